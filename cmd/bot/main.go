@@ -17,6 +17,7 @@ import (
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 	tghandlers "github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/callbackquery"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/message"
 	_ "github.com/mattn/go-sqlite3"
 )
 
@@ -38,36 +39,67 @@ func main() {
 
 	userStatsRepo := repository.NewUserStatsRepo(db)
 	settingsRepo := repository.NewSettingsRepo(db)
+	jackpotRepo := repository.NewJackpotRepo(db)
+	seasonRepo := repository.NewSeasonRepo(db)
+	achievementRepo := repository.NewAchievementRepo(db)
+	levelRepo := repository.NewLevelRepo(db)
+	dailyRepo := repository.NewDailyRepo(db)
+	bailoutRepo := repository.NewBailoutRepo(db)
+	transferRepo := repository.NewTransferRepo(db)
+	betRepo := repository.NewBetRepo(db)
+	duelRepo := repository.NewDuelRepo(db)
+	tournamentRepo := repository.NewTournamentRepo(db)
+	lotteryRepo := repository.NewLotteryRepo(db)
+	loanRepo := repository.NewLoanRepo(db)
 
 	slotMessageCache := cache.NewSlotMessageCache()
 	if err := slotMessageCache.LoadFromFile("slot_cache.json"); err != nil {
 		log.Println("failed to load slot cache:", err)
 	}
 
+	spinThrottle := cache.NewSpinThrottle()
+	pendingInputs := cache.NewPendingInputs(2 * time.Minute)
+
 	cleaner := service.NewMessageCleaner(slotMessageCache)
 	authService := service.NewAuthService(cfg.DevIDs, settingsRepo)
+	levelService := service.NewLevelService(levelRepo, settingsRepo)
+	bailoutService := service.NewBailoutService(bailoutRepo, settingsRepo)
+	betService := service.NewBetService(betRepo, settingsRepo)
+	duelService := service.NewDuelService(duelRepo, settingsRepo)
+	tournamentService := service.NewTournamentService(tournamentRepo, settingsRepo, authService)
+	lotteryService := service.NewLotteryService(lotteryRepo, settingsRepo)
+	loanService := service.NewLoanService(loanRepo, settingsRepo)
+	achievementService := service.NewAchievementService(achievementRepo, userStatsRepo, settingsRepo)
 	slotService := service.NewSlotService(
 		userStatsRepo,
 		settingsRepo,
 		slotMessageCache,
+		spinThrottle,
 		cleaner,
+		levelService,
+		achievementService,
+		bailoutService,
+		duelService,
+		loanService,
 	)
-	settingsService := service.NewSettingsService(settingsRepo, authService)
-	statsService := service.NewStatsService(userStatsRepo)
-	resetService := service.NewResetService(userStatsRepo, authService)
+	settingsService := service.NewSettingsService(settingsRepo, jackpotRepo, authService, pendingInputs)
+	statsService := service.NewStatsService(userStatsRepo, settingsRepo, jackpotRepo, seasonRepo, levelService, bailoutService, duelService, loanService)
+	dailyService := service.NewDailyService(dailyRepo, settingsRepo)
+	transferService := service.NewTransferService(transferRepo, userStatsRepo, settingsRepo)
+	resetService := service.NewResetService(seasonRepo, userStatsRepo, settingsRepo, authService)
 
 	bot, err := gotgbot.NewBot(cfg.BotToken, nil)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	loc, err := time.LoadLocation("Europe/Uzhgorod")
+	loc, err := time.LoadLocation(repository.DefaultTimezone)
 	if err != nil {
-		log.Printf("timezone Europe/Uzhgorod not found, using local: %v", err)
+		log.Printf("timezone %s not found, using local: %v", repository.DefaultTimezone, err)
 		loc = time.Local
 	}
 
-	sched := scheduler.NewScheduler(slotMessageCache, cleaner, bot, loc)
+	sched := scheduler.NewScheduler(slotMessageCache, spinThrottle, cleaner, resetService, betService, duelService, tournamentService, lotteryService, loanService, bot, loc)
 	sched.Start()
 	defer sched.Stop()
 
@@ -81,17 +113,35 @@ func main() {
 
 	updater := ext.NewUpdater(dispatcher, &ext.UpdaterOpts{})
 
+	// Answers to the bot's questions are picked up before anything else sees the message.
+	dispatcher.AddHandlerToGroup(tghandlers.NewMessage(message.Text, settingsService.HandlePendingInput), -1)
+
 	dispatcher.AddHandler(handlers.GetSlotHandler(slotService))
 	dispatcher.AddHandler(handlers.GetCleanCommand(slotService))
 
 	dispatcher.AddHandler(tghandlers.NewCommand("me", slotService.HandleMeCommand))
 	dispatcher.AddHandler(tghandlers.NewCommand("stats", statsService.HandleStatsCommand))
+	dispatcher.AddHandler(tghandlers.NewCommand("achievements", achievementService.HandleAchievementsCommand))
+	dispatcher.AddHandler(tghandlers.NewCommand("daily", dailyService.HandleDailyCommand))
+	dispatcher.AddHandler(tghandlers.NewCommand("bailout", bailoutService.HandleBailoutCommand))
+	dispatcher.AddHandler(tghandlers.NewCommand("give", transferService.HandleGiveCommand))
+	dispatcher.AddHandler(tghandlers.NewCommand("bet", betService.HandleBetCommand))
+	dispatcher.AddHandler(tghandlers.NewCommand("duel", duelService.HandleDuelCommand))
+	dispatcher.AddHandler(tghandlers.NewCommand("tournament", tournamentService.HandleTournamentCommand))
+	dispatcher.AddHandler(tghandlers.NewCommand("ticket", lotteryService.HandleTicketCommand))
+	dispatcher.AddHandler(tghandlers.NewCommand("loan", loanService.HandleLoanCommand))
 	dispatcher.AddHandler(tghandlers.NewCommand("settings", settingsService.HandleSettingsCommand))
+	dispatcher.AddHandler(tghandlers.NewCommand("timezone", settingsService.HandleTimezoneCommand))
 	dispatcher.AddHandler(tghandlers.NewCommand("reset", resetService.HandleResetCommand))
 	dispatcher.AddHandler(tghandlers.NewCommand("help", slotService.HandleHelpCommand))
+	dispatcher.AddHandler(tghandlers.NewCallback(callbackquery.Prefix("me:"), slotService.HandleMeCallback))
 	dispatcher.AddHandler(tghandlers.NewCallback(callbackquery.Prefix("stats:"), statsService.HandleStatsCallback))
 	dispatcher.AddHandler(tghandlers.NewCallback(callbackquery.Prefix("settings:"), settingsService.HandleSettingsCallback))
+	dispatcher.AddHandler(tghandlers.NewCallback(callbackquery.Prefix("prizepick:"), settingsService.HandlePrizePickerCallback))
 	dispatcher.AddHandler(tghandlers.NewCallback(callbackquery.Prefix("reset:"), resetService.HandleResetCallback))
+	dispatcher.AddHandler(tghandlers.NewCallback(callbackquery.Prefix("give:"), transferService.HandleGiveCallback))
+	dispatcher.AddHandler(tghandlers.NewCallback(callbackquery.Prefix("duel:"), duelService.HandleDuelCallback))
+	dispatcher.AddHandler(tghandlers.NewCallback(callbackquery.Prefix("tournament:"), tournamentService.HandleTournamentCallback))
 
 	err = updater.StartPolling(bot, &ext.PollingOpts{
 		DropPendingUpdates:    false,
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// PendingInput is a question the bot asked a chat member and now waits the
+// answer for in their next message.
+type PendingInput struct {
+	Kind    string
+	Payload string
+	expires time.Time
+}
+
+type pendingKey struct {
+	chatId int64
+	userId int64
+}
+
+// PendingInputs holds at most one open question per member of a chat. Answers
+// from anybody else are not matched, and a question expires after the ttl.
+type PendingInputs struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	inputs map[pendingKey]PendingInput
+}
+
+// NewPendingInputs returns an empty store whose questions live for ttl.
+func NewPendingInputs(ttl time.Duration) *PendingInputs {
+	return &PendingInputs{ttl: ttl, inputs: make(map[pendingKey]PendingInput)}
+}
+
+// Set opens a question for the member, replacing the one they had before.
+func (p *PendingInputs) Set(chatId, userId int64, kind, payload string, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key, input := range p.inputs {
+		if !now.Before(input.expires) {
+			delete(p.inputs, key)
+		}
+	}
+	p.inputs[pendingKey{chatId: chatId, userId: userId}] = PendingInput{
+		Kind:    kind,
+		Payload: payload,
+		expires: now.Add(p.ttl),
+	}
+}
+
+// Has reports whether the member has an open question, without closing it.
+func (p *PendingInputs) Has(chatId, userId int64, now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	input, ok := p.inputs[pendingKey{chatId: chatId, userId: userId}]
+	return ok && now.Before(input.expires)
+}
+
+// Take returns the member's open question and closes it.
+func (p *PendingInputs) Take(chatId, userId int64, now time.Time) (PendingInput, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := pendingKey{chatId: chatId, userId: userId}
+	input, ok := p.inputs[key]
+	if !ok {
+		return PendingInput{}, false
+	}
+	delete(p.inputs, key)
+	if !now.Before(input.expires) {
+		return PendingInput{}, false
+	}
+	return input, true
+}
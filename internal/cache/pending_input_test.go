@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPendingInputs_Take(t *testing.T) {
+	p := NewPendingInputs(time.Minute)
+	start := time.Unix(1_700_000_000, 0)
+
+	p.Set(100, 1, "name", "ff", start)
+
+	if _, ok := p.Take(100, 2, start); ok {
+		t.Error("another member must not answer the question")
+	}
+	if _, ok := p.Take(200, 1, start); ok {
+		t.Error("the question must not leak into another chat")
+	}
+
+	input, ok := p.Take(100, 1, start.Add(30*time.Second))
+	if !ok || input.Kind != "name" || input.Payload != "ff" {
+		t.Fatalf("Take() = %+v, %v", input, ok)
+	}
+	if _, ok := p.Take(100, 1, start.Add(30*time.Second)); ok {
+		t.Error("a question is answered only once")
+	}
+}
+
+func TestPendingInputs_Expires(t *testing.T) {
+	p := NewPendingInputs(time.Minute)
+	start := time.Unix(1_700_000_000, 0)
+
+	p.Set(100, 1, "name", "", start)
+	if _, ok := p.Take(100, 1, start.Add(time.Minute)); ok {
+		t.Error("expired question should not be returned")
+	}
+}
+
+func TestPendingInputs_SetReplaces(t *testing.T) {
+	p := NewPendingInputs(time.Minute)
+	start := time.Unix(1_700_000_000, 0)
+
+	p.Set(100, 1, "name", "", start)
+	p.Set(100, 1, "amount", "", start)
+
+	input, ok := p.Take(100, 1, start)
+	if !ok || input.Kind != "amount" {
+		t.Errorf("Take() = %+v, %v, want the latest question", input, ok)
+	}
+}
+
+func TestPendingInputs_HasKeepsQuestion(t *testing.T) {
+	p := NewPendingInputs(time.Minute)
+	start := time.Unix(1_700_000_000, 0)
+
+	p.Set(100, 1, "name", "", start)
+	if !p.Has(100, 1, start) || p.Has(100, 2, start) {
+		t.Error("Has() should only see the member's own question")
+	}
+	if _, ok := p.Take(100, 1, start); !ok {
+		t.Error("Has() must not close the question")
+	}
+	p.Set(100, 1, "name", "", start)
+	if p.Has(100, 1, start.Add(time.Minute)) {
+		t.Error("Has() should not see an expired question")
+	}
+}
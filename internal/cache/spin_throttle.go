@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	burstWindow  = time.Minute
+	warnInterval = 30 * time.Second
+)
+
+type throttleKey struct {
+	chatId int64
+	userId int64
+}
+
+type throttleState struct {
+	spins    []time.Time // counted spins within burstWindow, oldest first
+	warnedAt time.Time
+}
+
+// SpinThrottle remembers recent counted spins per player to enforce cooldowns
+// and burst limits. It lives in memory only: after a restart everybody starts clean.
+type SpinThrottle struct {
+	mu    sync.Mutex
+	users map[throttleKey]*throttleState
+}
+
+// NewSpinThrottle returns ready throttle
+func NewSpinThrottle() *SpinThrottle {
+	return &SpinThrottle{users: make(map[throttleKey]*throttleState)}
+}
+
+// Allow reports whether a spin made at `at` fits both the cooldown and the burst
+// limit (spins per minute); zero disables a limit. An allowed spin takes its
+// slot right away, under the same lock, so concurrent spins of one player
+// can't all pass; call Release if the spin ends up not counted. When the spin
+// is rejected, the returned duration is how long the player has to wait.
+func (t *SpinThrottle) Allow(chatId, userId int64, at time.Time, cooldown time.Duration, burst int) (bool, time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := throttleKey{chatId: chatId, userId: userId}
+	state, ok := t.users[key]
+	if !ok {
+		t.users[key] = &throttleState{spins: []time.Time{at}}
+		return true, 0
+	}
+
+	fresh := state.spins[:0]
+	for _, s := range state.spins {
+		if at.Sub(s) < burstWindow {
+			fresh = append(fresh, s)
+		}
+	}
+	state.spins = fresh
+
+	if cooldown > 0 && len(state.spins) > 0 {
+		if wait := cooldown - at.Sub(state.spins[len(state.spins)-1]); wait > 0 {
+			return false, wait
+		}
+	}
+	if burst > 0 && len(state.spins) >= burst {
+		return false, burstWindow - at.Sub(state.spins[len(state.spins)-burst])
+	}
+
+	i := len(state.spins)
+	for i > 0 && state.spins[i-1].After(at) {
+		i--
+	}
+	state.spins = slices.Insert(state.spins, i, at)
+	return true, 0
+}
+
+// Release gives back the slot Allow took for a spin made at `at` that was not
+// counted after all, e.g. a duplicate or a spin over the daily quota.
+func (t *SpinThrottle) Release(chatId, userId int64, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.users[throttleKey{chatId: chatId, userId: userId}]
+	if !ok {
+		return
+	}
+	for i := len(state.spins) - 1; i >= 0; i-- {
+		if state.spins[i].Equal(at) {
+			state.spins = slices.Delete(state.spins, i, i+1)
+			return
+		}
+	}
+}
+
+// ShouldWarn reports whether the player has not been warned recently, and if so
+// marks them as warned, so a spammer gets one warning instead of one per spin.
+func (t *SpinThrottle) ShouldWarn(chatId, userId int64, at time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.users[throttleKey{chatId: chatId, userId: userId}]
+	if !ok {
+		return false
+	}
+	if at.Sub(state.warnedAt) < warnInterval {
+		return false
+	}
+	state.warnedAt = at
+	return true
+}
+
+// Prune forgets players whose last counted spin is older than the burst window.
+func (t *SpinThrottle) Prune(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for key, state := range t.users {
+		if len(state.spins) == 0 || now.Sub(state.spins[len(state.spins)-1]) >= burstWindow {
+			if now.Sub(state.warnedAt) >= warnInterval {
+				delete(t.users, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSpinThrottle_Cooldown(t *testing.T) {
+	th := NewSpinThrottle()
+	start := time.Unix(1_700_000_000, 0)
+
+	if ok, _ := th.Allow(100, 1, start, 5*time.Second, 0); !ok {
+		t.Fatal("first spin should be allowed")
+	}
+	ok, wait := th.Allow(100, 1, start.Add(2*time.Second), 5*time.Second, 0)
+	if ok {
+		t.Fatal("spin inside cooldown should be rejected")
+	}
+	if wait != 3*time.Second {
+		t.Errorf("wait = %v, want 3s", wait)
+	}
+	// The rejected spin must not restart the cooldown.
+	if ok, _ := th.Allow(100, 1, start.Add(5*time.Second), 5*time.Second, 0); !ok {
+		t.Error("spin after cooldown should be allowed")
+	}
+}
+
+func TestSpinThrottle_Burst(t *testing.T) {
+	th := NewSpinThrottle()
+	start := time.Unix(1_700_000_000, 0)
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := th.Allow(100, 1, start.Add(time.Duration(i)*time.Second), 0, 3); !ok {
+			t.Fatalf("spin %d should be allowed", i+1)
+		}
+	}
+	ok, wait := th.Allow(100, 1, start.Add(10*time.Second), 0, 3)
+	if ok {
+		t.Fatal("fourth spin within a minute should be rejected")
+	}
+	if wait != 50*time.Second {
+		t.Errorf("wait = %v, want 50s", wait)
+	}
+	if ok, _ := th.Allow(100, 1, start.Add(time.Minute), 0, 3); !ok {
+		t.Error("spin after the oldest one left the window should be allowed")
+	}
+}
+
+func TestSpinThrottle_UncountedSpinsAreFree(t *testing.T) {
+	th := NewSpinThrottle()
+	now := time.Unix(1_700_000_000, 0)
+
+	// allowed but never counted, e.g. a duplicate or over the daily quota
+	th.Allow(100, 1, now, time.Minute, 1)
+	th.Release(100, 1, now)
+	if ok, _ := th.Allow(100, 1, now.Add(time.Second), time.Minute, 1); !ok {
+		t.Error("a released spin should not use up the limits")
+	}
+}
+
+func TestSpinThrottle_ConcurrentBurst(t *testing.T) {
+	th := NewSpinThrottle()
+	now := time.Unix(1_700_000_000, 0)
+
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ok, _ := th.Allow(100, 1, now, 0, 3); ok {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if allowed.Load() != 3 {
+		t.Errorf("%d concurrent spins allowed, want the burst of 3", allowed.Load())
+	}
+}
+
+func TestSpinThrottle_PlayersAndChatsAreIndependent(t *testing.T) {
+	th := NewSpinThrottle()
+	now := time.Unix(1_700_000_000, 0)
+
+	th.Allow(100, 1, now, time.Minute, 0)
+	if ok, _ := th.Allow(100, 2, now, time.Minute, 0); !ok {
+		t.Error("another player should not be throttled")
+	}
+	if ok, _ := th.Allow(200, 1, now, time.Minute, 0); !ok {
+		t.Error("the same player in another chat should not be throttled")
+	}
+}
+
+func TestSpinThrottle_ShouldWarnOncePerInterval(t *testing.T) {
+	th := NewSpinThrottle()
+	now := time.Unix(1_700_000_000, 0)
+	th.Allow(100, 1, now, time.Minute, 0)
+
+	if !th.ShouldWarn(100, 1, now) {
+		t.Error("first warning should be sent")
+	}
+	if th.ShouldWarn(100, 1, now.Add(5*time.Second)) {
+		t.Error("second warning within the interval should be suppressed")
+	}
+	if !th.ShouldWarn(100, 1, now.Add(warnInterval)) {
+		t.Error("warning after the interval should be sent")
+	}
+}
+
+func TestSpinThrottle_Prune(t *testing.T) {
+	th := NewSpinThrottle()
+	now := time.Unix(1_700_000_000, 0)
+	th.Allow(100, 1, now, time.Minute, 0)
+
+	th.Prune(now.Add(2 * time.Minute))
+	if len(th.users) != 0 {
+		t.Errorf("users after prune = %d, want 0", len(th.users))
+	}
+}
@@ -0,0 +1,93 @@
+package domain
+
+import "time"
+
+// Achievement is the key of a badge a player unlocks once per chat.
+type Achievement string
+
+const (
+	AchievementFirstWin      Achievement = "first_win"
+	AchievementWinStreak     Achievement = "win_streak_10"
+	AchievementLossStreak    Achievement = "loss_streak_50"
+	AchievementThousandSpins Achievement = "spins_1000"
+	AchievementFirstSevens   Achievement = "first_777"
+	AchievementRich          Achievement = "balance_10k"
+)
+
+// AchievementInfo describes an achievement and when it is earned. Earned
+// looks at the spin just made and the player's stats in its game right after it.
+type AchievementInfo struct {
+	Key    Achievement
+	Title  string
+	Hint   string
+	Earned func(spin Spin, stats PersonalStats) bool
+}
+
+// Achievements lists every achievement in the order they are shown.
+var Achievements = []AchievementInfo{
+	{
+		Key:    AchievementFirstWin,
+		Title:  "🍾 Перший виграш",
+		Hint:   "виграти хоч раз",
+		Earned: func(_ Spin, stats PersonalStats) bool { return stats.Wins >= 1 },
+	},
+	{
+		Key:   AchievementFirstSevens,
+		Title: "7️⃣ Три сімки",
+		Hint:  "вибити 777 на крутілці",
+		Earned: func(spin Spin, _ PersonalStats) bool {
+			return spin.Game == GameSlot && ComboSevens.Matches(DecodeSlot(spin.Value))
+		},
+	},
+	{
+		Key:    AchievementWinStreak,
+		Title:  "🔥 В ударі",
+		Hint:   "10 виграшів поспіль",
+		Earned: func(_ Spin, stats PersonalStats) bool { return stats.CurrentStreak >= 10 },
+	},
+	{
+		Key:    AchievementLossStreak,
+		Title:  "💀 Невдаха",
+		Hint:   "50 програшів поспіль",
+		Earned: func(_ Spin, stats PersonalStats) bool { return stats.CurrentLossStreak >= 50 },
+	},
+	{
+		Key:    AchievementThousandSpins,
+		Title:  "🎡 Завсідник",
+		Hint:   "1000 спроб в одній грі",
+		Earned: func(_ Spin, stats PersonalStats) bool { return stats.Spins >= 1000 },
+	},
+	{
+		Key:    AchievementRich,
+		Title:  "💰 Багатій",
+		Hint:   "баланс понад 10 000",
+		Earned: func(_ Spin, stats PersonalStats) bool { return stats.Balance > 10_000 },
+	},
+}
+
+// EarnedAchievements returns every achievement whose condition the spin meets,
+// whether or not the player already has it.
+func EarnedAchievements(spin Spin, stats PersonalStats) []Achievement {
+	var earned []Achievement
+	for _, a := range Achievements {
+		if a.Earned(spin, stats) {
+			earned = append(earned, a.Key)
+		}
+	}
+	return earned
+}
+
+func AchievementByKey(key Achievement) (AchievementInfo, bool) {
+	for _, a := range Achievements {
+		if a.Key == key {
+			return a, true
+		}
+	}
+	return AchievementInfo{}, false
+}
+
+// UnlockedAchievement is an achievement a player has, with its unlock time.
+type UnlockedAchievement struct {
+	Key        Achievement
+	UnlockedAt time.Time
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEarnedAchievements(t *testing.T) {
+	tests := []struct {
+		name  string
+		spin  Spin
+		stats PersonalStats
+		want  []Achievement
+	}{
+		{"nothing", Spin{Game: GameSlot, Value: 2}, PersonalStats{Spins: 1}, nil},
+		{"first win", Spin{Game: GameDice, Value: 6}, PersonalStats{Spins: 1, Wins: 1, CurrentStreak: 1}, []Achievement{AchievementFirstWin}},
+		{"777", Spin{Game: GameSlot, Value: 64}, PersonalStats{Spins: 1, Wins: 1}, []Achievement{AchievementFirstWin, AchievementFirstSevens}},
+		{"dice six is not 777", Spin{Game: GameDice, Value: 64}, PersonalStats{}, nil},
+		{"win streak", Spin{Game: GameSlot, Value: 1}, PersonalStats{Wins: 10, CurrentStreak: 10}, []Achievement{AchievementFirstWin, AchievementWinStreak}},
+		{"loss streak", Spin{Game: GameSlot, Value: 2}, PersonalStats{CurrentLossStreak: 50}, []Achievement{AchievementLossStreak}},
+		{"spins", Spin{Game: GameSlot, Value: 2}, PersonalStats{Spins: 1000}, []Achievement{AchievementThousandSpins}},
+		{"rich", Spin{Game: GameSlot, Value: 2}, PersonalStats{Balance: 10_001, Wins: 1}, []Achievement{AchievementFirstWin, AchievementRich}},
+		{"not quite rich", Spin{Game: GameSlot, Value: 2}, PersonalStats{Balance: 10_000}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EarnedAchievements(tt.spin, tt.stats); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EarnedAchievements() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
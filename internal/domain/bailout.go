@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// Bailout is the chat's policy for writing off the debts of broke players.
+type Bailout struct {
+	// Threshold is the balance at or below which a player may ask for a
+	// bailout; zero disables bailouts.
+	Threshold int64
+	// Cooldown is the minimum time between two bailouts of one player.
+	Cooldown time.Duration
+}
+
+func (b Bailout) Enabled() bool {
+	return b.Threshold < 0
+}
+
+type BailoutResult struct {
+	// Granted is set when the debt was written off.
+	Granted bool
+	// Debt is the written off debt, or the player's current balance when the
+	// bailout was refused.
+	Debt int64
+	// Count is how many bailouts the player has had, this one included.
+	Count int64
+	// RetryAt is set when the player had a bailout too recently.
+	RetryAt time.Time
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// Bet is a stake a player placed on their next 🎰 in a chat.
+type Bet struct {
+	ChatId    int64
+	UserId    int64
+	Stake     int64
+	ExpiresAt time.Time
+}
+
+// BetLimits bound the stakes of a chat; a zero Max disables bets.
+type BetLimits struct {
+	Min int64
+	Max int64
+}
+
+func (l BetLimits) Enabled() bool {
+	return l.Max > 0
+}
+
+// Valid reports whether enabled limits leave room for a stake.
+func (l BetLimits) Valid() bool {
+	return !l.Enabled() || l.Min <= l.Max
+}
+
+func (l BetLimits) Allows(stake int64) bool {
+	return stake >= l.Min && stake <= l.Max
+}
+
+// ApplyStake turns a resolved 🎰 spin into a bet: the stake is lost instead of
+// the spin cost, and the payout grows by the same factor, so a stake of ten
+// regular spins pays ten times as much. A free spin counts as a cost of one.
+// When the stake is not a multiple of the cost the payout is rounded down,
+// so a bet never pays more than its share of the win.
+func (s *Spin) ApplyStake(stake int64) {
+	unit := max(s.Cost, 1)
+	s.Payout = s.Payout * stake / unit
+	s.Cost = stake
+	s.Stake = stake
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "testing"
+
+func TestSpin_ApplyStake(t *testing.T) {
+	tests := []struct {
+		name       string
+		spin       Spin
+		stake      int64
+		wantPayout int64
+		wantCost   int64
+	}{
+		{"loss", Spin{Cost: 1}, 50, 0, 50},
+		{"win", Spin{Payout: 64, Cost: 1}, 50, 3200, 50},
+		{"costly spins", Spin{Payout: 64, Cost: 2}, 10, 320, 10},
+		{"free spins", Spin{Payout: 64}, 5, 320, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spin := tt.spin
+			spin.ApplyStake(tt.stake)
+			if spin.Payout != tt.wantPayout || spin.Cost != tt.wantCost || spin.Stake != tt.stake {
+				t.Errorf("ApplyStake(%d) = payout %d, cost %d, stake %d, want %d, %d, %d",
+					tt.stake, spin.Payout, spin.Cost, spin.Stake, tt.wantPayout, tt.wantCost, tt.stake)
+			}
+		})
+	}
+}
+
+func TestBetLimits(t *testing.T) {
+	limits := BetLimits{Min: 10, Max: 100}
+	if !limits.Enabled() || !limits.Allows(10) || !limits.Allows(100) || limits.Allows(9) || limits.Allows(101) {
+		t.Errorf("limits %+v misjudge the bounds", limits)
+	}
+	if (BetLimits{Min: 1}).Enabled() {
+		t.Error("zero Max should disable bets")
+	}
+	if (BetLimits{Min: 500, Max: 100}).Valid() || !(BetLimits{Min: 500}).Valid() {
+		t.Error("limits should be invalid only when enabled with Min above Max")
+	}
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// CoinGame is the game whose balance works as the chat's coins: bonuses and
+// other payments outside of spins are credited to it.
+const CoinGame = GameSlot
+
+// DayLayout formats a calendar day in the chat's timezone.
+const DayLayout = "2006-01-02"
+
+// Every consecutive day of claims adds a tenth of the base bonus, up to
+// DailyStreakCap extra tenths, which doubles the bonus.
+const DailyStreakCap = 10
+
+// DailyClaim is a player's daily bonus for one local day.
+type DailyClaim struct {
+	Day       string
+	Streak    int64
+	Amount    int64
+	ClaimedAt time.Time
+}
+
+// DailyBonus returns the bonus for the given day of a claim streak.
+func DailyBonus(base int64, streak int64) int64 {
+	extra := min(max(streak-1, 0), DailyStreakCap)
+	return base + base*extra/10
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "testing"
+
+func TestDailyBonus(t *testing.T) {
+	tests := []struct {
+		base   int64
+		streak int64
+		want   int64
+	}{
+		{10, 1, 10},
+		{10, 2, 11},
+		{10, 6, 15},
+		{10, 11, 20},
+		{10, 30, 20},
+		{25, 3, 30},
+		{0, 5, 0},
+	}
+
+	for _, tt := range tests {
+		if got := DailyBonus(tt.base, tt.streak); got != tt.want {
+			t.Errorf("DailyBonus(%d, %d) = %d, want %d", tt.base, tt.streak, got, tt.want)
+		}
+	}
+}
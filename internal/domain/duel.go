@@ -0,0 +1,72 @@
+package domain
+
+import "time"
+
+type DuelStatus string
+
+const (
+	DuelOffered   DuelStatus = "offered"
+	DuelAccepted  DuelStatus = "accepted"
+	DuelDone      DuelStatus = "done"
+	DuelCancelled DuelStatus = "cancelled"
+)
+
+// DuelNotRolled is the payout of a duelist who has not spun yet.
+const DuelNotRolled = -1
+
+// Duel is a challenge between two players: once accepted, both spin one 🎰
+// and the higher payout takes the other's stake.
+type Duel struct {
+	Id               int64
+	ChatId           int64
+	ChallengerId     int64
+	ChallengerName   string
+	OpponentId       int64
+	OpponentName     string
+	Stake            int64
+	Status           DuelStatus
+	ChallengerPayout int64
+	OpponentPayout   int64
+	WinnerId         int64
+	CreatedAt        time.Time
+	AcceptedAt       time.Time
+}
+
+// Involves reports whether the player is one of the duelists.
+func (d Duel) Involves(userId int64) bool {
+	return d.ChallengerId == userId || d.OpponentId == userId
+}
+
+// Winner decides the duel from the rolls made so far: the higher payout wins,
+// a duelist who did not roll loses, and equal payouts are a draw (zero).
+func (d Duel) Winner() int64 {
+	switch {
+	case d.ChallengerPayout > d.OpponentPayout:
+		return d.ChallengerId
+	case d.OpponentPayout > d.ChallengerPayout:
+		return d.OpponentId
+	default:
+		return 0
+	}
+}
+
+// Loser is the other duelist of a decided duel, zero for a draw.
+func (d Duel) Loser() int64 {
+	switch d.Winner() {
+	case d.ChallengerId:
+		return d.OpponentId
+	case d.OpponentId:
+		return d.ChallengerId
+	default:
+		return 0
+	}
+}
+
+// DuelStats is a line of the duelists leaderboard.
+type DuelStats struct {
+	UserId   int64
+	Username string
+	Wins     int64
+	Losses   int64
+	Rank     int64
+}
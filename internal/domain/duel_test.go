@@ -0,0 +1,32 @@
+package domain
+
+import "testing"
+
+func TestDuel_Winner(t *testing.T) {
+	tests := []struct {
+		name       string
+		challenger int64
+		opponent   int64
+		wantWinner int64
+		wantLoser  int64
+	}{
+		{"challenger wins", 64, 0, 1, 2},
+		{"opponent wins", 0, 5, 2, 1},
+		{"draw", 64, 64, 0, 0},
+		{"both lost", 0, 0, 0, 0},
+		{"opponent did not roll", 0, DuelNotRolled, 1, 2},
+		{"nobody rolled", DuelNotRolled, DuelNotRolled, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Duel{ChallengerId: 1, OpponentId: 2, ChallengerPayout: tt.challenger, OpponentPayout: tt.opponent}
+			if got := d.Winner(); got != tt.wantWinner {
+				t.Errorf("Winner() = %d, want %d", got, tt.wantWinner)
+			}
+			if got := d.Loser(); got != tt.wantLoser {
+				t.Errorf("Loser() = %d, want %d", got, tt.wantLoser)
+			}
+		})
+	}
+}
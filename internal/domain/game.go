@@ -0,0 +1,58 @@
+package domain
+
+// Game is one of the Telegram dice emojis tracked as a separate game.
+type Game string
+
+const (
+	GameSlot       Game = "slot"
+	GameDice       Game = "dice"
+	GameDarts      Game = "darts"
+	GameBasketball Game = "basketball"
+	GameFootball   Game = "football"
+	GameBowling    Game = "bowling"
+)
+
+var Games = []Game{GameSlot, GameDice, GameDarts, GameBasketball, GameFootball, GameBowling}
+
+var gameEmojis = map[Game]string{
+	GameSlot:       "🎰",
+	GameDice:       "🎲",
+	GameDarts:      "🎯",
+	GameBasketball: "🏀",
+	GameFootball:   "⚽",
+	GameBowling:    "🎳",
+}
+
+// gamePayouts are the fixed scoring rules of every game except the slot,
+// whose payouts are configured per chat.
+var gamePayouts = map[Game]map[int]int64{
+	GameDice:       {6: 5},
+	GameDarts:      {6: 5},
+	GameBasketball: {4: 2, 5: 2},
+	GameFootball:   {3: 2, 4: 2, 5: 2},
+	GameBowling:    {6: 5},
+}
+
+func GameByEmoji(emoji string) (Game, bool) {
+	for g, e := range gameEmojis {
+		if e == emoji {
+			return g, true
+		}
+	}
+	return "", false
+}
+
+func ParseGame(key string) (Game, bool) {
+	g := Game(key)
+	_, ok := gameEmojis[g]
+	return g, ok
+}
+
+func (g Game) Emoji() string {
+	return gameEmojis[g]
+}
+
+// Payout returns the fixed payout of a dice value for games with static rules.
+func (g Game) Payout(value int) int64 {
+	return gamePayouts[g][value]
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "testing"
+
+func TestGameByEmoji(t *testing.T) {
+	for _, g := range Games {
+		got, ok := GameByEmoji(g.Emoji())
+		if !ok || got != g {
+			t.Errorf("GameByEmoji(%q) = (%q, %v), want (%q, true)", g.Emoji(), got, ok, g)
+		}
+	}
+	if _, ok := GameByEmoji("🃏"); ok {
+		t.Error("GameByEmoji(🃏) should not match any game")
+	}
+}
+
+func TestGamePayout(t *testing.T) {
+	tests := []struct {
+		game  Game
+		value int
+		want  int64
+	}{
+		{GameDice, 6, 5},
+		{GameDice, 5, 0},
+		{GameDarts, 6, 5},
+		{GameBasketball, 4, 2},
+		{GameBasketball, 3, 0},
+		{GameFootball, 3, 2},
+		{GameFootball, 2, 0},
+		{GameBowling, 6, 5},
+		{GameSlot, 64, 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.game.Payout(tt.value); got != tt.want {
+			t.Errorf("%s.Payout(%d) = %d, want %d", tt.game, tt.value, got, tt.want)
+		}
+	}
+}
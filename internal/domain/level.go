@@ -0,0 +1,96 @@
+package domain
+
+// Experience a player earns for every counted spin, and on top of it for a win.
+const (
+	XPPerSpin int64 = 1
+	XPPerWin  int64 = 5
+)
+
+// SpinXP returns the experience a counted spin is worth; taking the jackpot
+// counts as a win even when the combination itself pays nothing.
+func SpinXP(spin Spin, result SpinResult) int64 {
+	if spin.Payout > 0 || result.JackpotWon > 0 {
+		return XPPerSpin + XPPerWin
+	}
+	return XPPerSpin
+}
+
+// LevelCurve says how much experience each level takes: going from level n to
+// n+1 costs Base + Step*(n-1), so every next level is a bit longer than the last.
+type LevelCurve struct {
+	Key  string
+	Name string
+	Base int64
+	Step int64
+}
+
+const DefaultLevelCurve = "normal"
+
+// LevelCurves lists the curves a chat can pick from, fastest first.
+var LevelCurves = []LevelCurve{
+	{Key: "fast", Name: "Швидко", Base: 50, Step: 25},
+	{Key: DefaultLevelCurve, Name: "Звичайно", Base: 100, Step: 50},
+	{Key: "slow", Name: "Повільно", Base: 200, Step: 100},
+}
+
+// LevelCurveByKey finds a curve, falling back to the default one for unknown keys.
+func LevelCurveByKey(key string) LevelCurve {
+	for _, c := range LevelCurves {
+		if c.Key == key {
+			return c
+		}
+	}
+	for _, c := range LevelCurves {
+		if c.Key == DefaultLevelCurve {
+			return c
+		}
+	}
+	return LevelCurve{}
+}
+
+// XPFor returns the total experience needed to reach the level; everybody starts at level 1.
+func (c LevelCurve) XPFor(level int) int64 {
+	if level <= 1 {
+		return 0
+	}
+	n := int64(level - 1)
+	return n*c.Base + c.Step*n*(n-1)/2
+}
+
+// Level returns the level reached with the given experience.
+func (c LevelCurve) Level(xp int64) int {
+	level := 1
+	for c.XPFor(level+1) <= xp {
+		level++
+	}
+	return level
+}
+
+// Progress returns the level reached with the given experience, the experience
+// gathered within it and how much the whole level takes.
+func (c LevelCurve) Progress(xp int64) (level int, gained int64, needed int64) {
+	level = c.Level(xp)
+	return level, xp - c.XPFor(level), c.XPFor(level+1) - c.XPFor(level)
+}
+
+var levelIcons = []struct {
+	from int
+	icon string
+}{
+	{50, "👑"},
+	{30, "💎"},
+	{20, "🌟"},
+	{10, "⭐"},
+	{5, "🍀"},
+	{1, "🌱"},
+}
+
+// LevelIcon is the badge shown next to a player's name for their level.
+func LevelIcon(level int) string {
+	for _, l := range levelIcons {
+		if level >= l.from {
+			return l.icon
+		}
+	}
+	return levelIcons[len(levelIcons)-1].icon
+}
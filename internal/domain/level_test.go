@@ -0,0 +1,63 @@
+package domain
+
+import "testing"
+
+func TestLevelCurve_Level(t *testing.T) {
+	curve := LevelCurve{Base: 100, Step: 50}
+	tests := []struct {
+		xp   int64
+		want int
+	}{
+		{0, 1},
+		{99, 1},
+		{100, 2},
+		{249, 2},
+		{250, 3},
+		{450, 4},
+	}
+
+	for _, tt := range tests {
+		if got := curve.Level(tt.xp); got != tt.want {
+			t.Errorf("Level(%d) = %d, want %d", tt.xp, got, tt.want)
+		}
+	}
+}
+
+func TestLevelCurve_Progress(t *testing.T) {
+	curve := LevelCurve{Base: 100, Step: 50}
+
+	level, gained, needed := curve.Progress(300)
+	if level != 3 || gained != 50 || needed != 200 {
+		t.Errorf("Progress(300) = %d, %d, %d, want 3, 50, 200", level, gained, needed)
+	}
+}
+
+func TestLevelCurveByKey_FallsBackToDefault(t *testing.T) {
+	if got := LevelCurveByKey("nope"); got.Key != DefaultLevelCurve {
+		t.Errorf("LevelCurveByKey(nope) = %q, want %q", got.Key, DefaultLevelCurve)
+	}
+	if got := LevelCurveByKey("slow"); got.Key != "slow" {
+		t.Errorf("LevelCurveByKey(slow) = %q, want slow", got.Key)
+	}
+}
+
+func TestSpinXP(t *testing.T) {
+	if got := SpinXP(Spin{}, SpinResult{}); got != XPPerSpin {
+		t.Errorf("SpinXP(loss) = %d, want %d", got, XPPerSpin)
+	}
+	if got := SpinXP(Spin{Payout: 64}, SpinResult{}); got != XPPerSpin+XPPerWin {
+		t.Errorf("SpinXP(win) = %d, want %d", got, XPPerSpin+XPPerWin)
+	}
+	if got := SpinXP(Spin{Jackpot: true}, SpinResult{JackpotWon: 20}); got != XPPerSpin+XPPerWin {
+		t.Errorf("SpinXP(jackpot only) = %d, want %d", got, XPPerSpin+XPPerWin)
+	}
+}
+
+func TestLevelIcon(t *testing.T) {
+	tests := map[int]string{0: "🌱", 1: "🌱", 5: "🍀", 12: "⭐", 50: "👑", 99: "👑"}
+	for level, want := range tests {
+		if got := LevelIcon(level); got != want {
+			t.Errorf("LevelIcon(%d) = %s, want %s", level, got, want)
+		}
+	}
+}
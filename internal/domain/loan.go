@@ -0,0 +1,74 @@
+package domain
+
+import "time"
+
+// LoanInterval is how often interest is added to a loan.
+const LoanInterval = 24 * time.Hour
+
+// LoanTerms are the chat's lending rules; a zero Max disables loans.
+type LoanTerms struct {
+	// Max is the largest amount a player may borrow at once.
+	Max int64
+	// Rate is the interest in percent of the debt added every LoanInterval.
+	Rate int64
+	// RepayShare is the percent of every 🎰 win that goes to the debt.
+	RepayShare int64
+}
+
+func (t LoanTerms) Enabled() bool {
+	return t.Max > 0
+}
+
+// Loan is a player's open debt. Debt grows with interest and shrinks with
+// repayments; the loan is closed once it reaches zero.
+type Loan struct {
+	Id        int64
+	ChatId    int64
+	UserId    int64
+	Username  string
+	Principal int64
+	Debt      int64
+	// Rate is the interest rate the loan was taken at.
+	Rate      int64
+	TakenAt   time.Time
+	AccruedAt time.Time
+}
+
+// MaxDebtFactor caps interest: a debt never grows past this many times the
+// amount borrowed, so a forgotten loan can't run away or overflow.
+const MaxDebtFactor = 10
+
+// AccrueInterest compounds the debt over the given number of intervals, up to
+// MaxDebtFactor times the principal. Every step rounds up, so a positive rate
+// always costs at least one coin.
+func AccrueInterest(debt int64, principal int64, rate int64, intervals int64) int64 {
+	limit := principal * MaxDebtFactor
+	if rate <= 0 || debt >= limit {
+		return debt
+	}
+	for range intervals {
+		debt += (debt*rate + 99) / 100
+		if debt >= limit {
+			return limit
+		}
+	}
+	return debt
+}
+
+// LoanRepayment is the part of a win that goes to the debt: the repay share
+// of the win, rounded up and capped by the debt.
+func LoanRepayment(win int64, share int64, debt int64) int64 {
+	if win <= 0 || share <= 0 || debt <= 0 {
+		return 0
+	}
+	return min((win*share+99)/100, debt)
+}
+
+// LoanStats is a line of the creditors leaderboard.
+type LoanStats struct {
+	UserId    int64
+	Username  string
+	Principal int64
+	Debt      int64
+	Rank      int64
+}
@@ -0,0 +1,59 @@
+package domain
+
+import "testing"
+
+func TestAccrueInterest(t *testing.T) {
+	tests := []struct {
+		name      string
+		debt      int64
+		principal int64
+		rate      int64
+		intervals int64
+		want      int64
+	}{
+		{"one day", 100, 100, 5, 1, 105},
+		{"compounds", 100, 100, 10, 2, 121},
+		{"rounds up", 10, 10, 5, 1, 11},
+		{"no intervals", 100, 100, 5, 0, 100},
+		{"zero rate", 100, 100, 0, 3, 100},
+		{"partly repaid", 50, 100, 10, 1, 55},
+		{"capped", 1000, 1000, 10, 360, 1000 * MaxDebtFactor},
+		{"years", 1000, 1000, 10, 1_000_000, 1000 * MaxDebtFactor},
+		{"already capped", 1000 * MaxDebtFactor, 1000, 10, 5, 1000 * MaxDebtFactor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AccrueInterest(tt.debt, tt.principal, tt.rate, tt.intervals); got != tt.want {
+				t.Errorf("AccrueInterest(%d, %d, %d, %d) = %d, want %d",
+					tt.debt, tt.principal, tt.rate, tt.intervals, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoanRepayment(t *testing.T) {
+	tests := []struct {
+		name  string
+		win   int64
+		share int64
+		debt  int64
+		want  int64
+	}{
+		{"half of the win", 64, 50, 1000, 32},
+		{"rounds up", 5, 50, 1000, 3},
+		{"capped by debt", 64, 50, 10, 10},
+		{"whole win", 64, 100, 1000, 64},
+		{"no win", 0, 50, 1000, 0},
+		{"no share", 64, 0, 1000, 0},
+		{"no debt", 64, 50, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LoanRepayment(tt.win, tt.share, tt.debt); got != tt.want {
+				t.Errorf("LoanRepayment(%d, %d, %d) = %d, want %d", tt.win, tt.share, tt.debt, got, tt.want)
+			}
+		})
+	}
+}
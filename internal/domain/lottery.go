@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Lottery schedule modes: no draws, a draw every day, or every Sunday, each
+// at the configured local hour.
+const (
+	LotteryOff    = "off"
+	LotteryDaily  = "daily"
+	LotteryWeekly = "weekly"
+)
+
+// LotteryMaxTickets caps how many tickets one /ticket command buys.
+const LotteryMaxTickets = 100
+
+// LotterySchedule tells when a chat's lottery is drawn.
+type LotterySchedule struct {
+	Mode string
+	// Hour is the local hour of the draw, 0..23.
+	Hour int
+	// CheckedAt is the last draw moment already handled; earlier ones are ignored.
+	CheckedAt time.Time
+}
+
+func (s LotterySchedule) Enabled() bool {
+	return s.Mode == LotteryDaily || s.Mode == LotteryWeekly
+}
+
+// LastDraw returns the latest scheduled draw at or before now.
+func (s LotterySchedule) LastDraw(now time.Time, loc *time.Location) (time.Time, bool) {
+	day := StartOfDay(now, loc)
+	step := 1
+	switch s.Mode {
+	case LotteryDaily:
+	case LotteryWeekly:
+		// Sunday closes the week that startOfWeek opens on Monday.
+		day = startOfWeek(now, loc).AddDate(0, 0, 6)
+		step = 7
+	default:
+		return time.Time{}, false
+	}
+	draw := s.at(day, loc)
+	for draw.After(now) {
+		day = day.AddDate(0, 0, -step)
+		draw = s.at(day, loc)
+	}
+	return draw, true
+}
+
+// NextDraw returns the first scheduled draw after now.
+func (s LotterySchedule) NextDraw(now time.Time, loc *time.Location) (time.Time, bool) {
+	last, ok := s.LastDraw(now, loc)
+	if !ok {
+		return time.Time{}, false
+	}
+	step := 1
+	if s.Mode == LotteryWeekly {
+		step = 7
+	}
+	return s.at(StartOfDay(last, loc).AddDate(0, 0, step), loc), true
+}
+
+// Due returns the draw moment that has come and was not handled yet.
+func (s LotterySchedule) Due(now time.Time, loc *time.Location) (time.Time, bool) {
+	draw, ok := s.LastDraw(now, loc)
+	if !ok || !draw.After(s.CheckedAt) {
+		return time.Time{}, false
+	}
+	return draw, true
+}
+
+func (s LotterySchedule) at(day time.Time, loc *time.Location) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), s.Hour, 0, 0, 0, loc)
+}
+
+type LotteryDrawStatus string
+
+const (
+	// LotteryWon is a drawn prize the winner has not claimed yet.
+	LotteryWon LotteryDrawStatus = "won"
+	// LotteryClaimed is a prize paid out to the winner.
+	LotteryClaimed LotteryDrawStatus = "claimed"
+	// LotteryRolled is a prize left unclaimed until the next draw and added to its pot.
+	LotteryRolled LotteryDrawStatus = "rolled"
+)
+
+// LotteryDraw is a drawn lottery round. The seed and the ordered tickets of
+// the round are stored, so LotteryWinner can replay the draw.
+type LotteryDraw struct {
+	Id          int64
+	ChatId      int64
+	DrawnAt     time.Time
+	Seed        uint64
+	Tickets     int64
+	Pot         int64
+	TicketId    int64
+	WinnerId    int64
+	WinnerName  string
+	WinnerCount int64
+	Status      LotteryDrawStatus
+}
+
+// LotteryWinner picks the index of the winning ticket among n tickets ordered
+// by id. The pick is the first PCG output of the seed modulo n, so anybody can
+// recompute it from the stored seed and ticket list.
+func LotteryWinner(seed uint64, n int) int {
+	return int(rand.NewPCG(seed, 0).Uint64() % uint64(n))
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLotterySchedule_LastAndNextDraw(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*3600)
+	// Wednesday 2025-03-05
+	at := func(day, hour, minute int) time.Time { return time.Date(2025, 3, day, hour, minute, 0, 0, loc) }
+
+	tests := []struct {
+		name     string
+		schedule LotterySchedule
+		now      time.Time
+		wantLast time.Time
+		wantNext time.Time
+	}{
+		{"daily after the hour", LotterySchedule{Mode: LotteryDaily, Hour: 20}, at(5, 21, 0), at(5, 20, 0), at(6, 20, 0)},
+		{"daily before the hour", LotterySchedule{Mode: LotteryDaily, Hour: 20}, at(5, 19, 59), at(4, 20, 0), at(5, 20, 0)},
+		{"daily at the hour", LotterySchedule{Mode: LotteryDaily, Hour: 20}, at(5, 20, 0), at(5, 20, 0), at(6, 20, 0)},
+		{"weekly midweek", LotterySchedule{Mode: LotteryWeekly, Hour: 18}, at(5, 12, 0), at(2, 18, 0), at(9, 18, 0)},
+		{"weekly sunday after", LotterySchedule{Mode: LotteryWeekly, Hour: 18}, at(9, 18, 30), at(9, 18, 0), at(16, 18, 0)},
+		{"weekly sunday before", LotterySchedule{Mode: LotteryWeekly, Hour: 18}, at(9, 17, 0), at(2, 18, 0), at(9, 18, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			last, ok := tt.schedule.LastDraw(tt.now, loc)
+			if !ok || !last.Equal(tt.wantLast) {
+				t.Errorf("LastDraw() = %v, %v, want %v", last, ok, tt.wantLast)
+			}
+			next, ok := tt.schedule.NextDraw(tt.now, loc)
+			if !ok || !next.Equal(tt.wantNext) {
+				t.Errorf("NextDraw() = %v, %v, want %v", next, ok, tt.wantNext)
+			}
+		})
+	}
+
+	if _, ok := (LotterySchedule{Mode: LotteryOff}).LastDraw(at(5, 12, 0), loc); ok {
+		t.Error("LastDraw() of a disabled lottery is ok")
+	}
+}
+
+func TestLotterySchedule_Due(t *testing.T) {
+	now := time.Date(2025, 3, 5, 20, 0, 30, 0, time.UTC)
+	draw := time.Date(2025, 3, 5, 20, 0, 0, 0, time.UTC)
+	schedule := LotterySchedule{Mode: LotteryDaily, Hour: 20, CheckedAt: draw.AddDate(0, 0, -1)}
+
+	got, ok := schedule.Due(now, time.UTC)
+	if !ok || !got.Equal(draw) {
+		t.Errorf("Due() = %v, %v, want %v", got, ok, draw)
+	}
+	schedule.CheckedAt = draw
+	if _, ok := schedule.Due(now, time.UTC); ok {
+		t.Error("Due() after the draw was handled is ok")
+	}
+}
+
+func TestLotteryWinner_Reproducible(t *testing.T) {
+	for _, n := range []int{1, 2, 7, 100} {
+		for seed := uint64(0); seed < 50; seed++ {
+			got := LotteryWinner(seed, n)
+			if got < 0 || got >= n {
+				t.Fatalf("LotteryWinner(%d, %d) = %d, out of range", seed, n, got)
+			}
+			if again := LotteryWinner(seed, n); again != got {
+				t.Fatalf("LotteryWinner(%d, %d) = %d, then %d", seed, n, got, again)
+			}
+		}
+	}
+
+	seen := map[int]bool{}
+	for seed := uint64(0); seed < 200; seed++ {
+		seen[LotteryWinner(seed, 5)] = true
+	}
+	if len(seen) != 5 {
+		t.Errorf("LotteryWinner over 200 seeds hit %d of 5 tickets", len(seen))
+	}
+}
@@ -0,0 +1,75 @@
+package domain
+
+import "time"
+
+// Period is a time window of the leaderboards.
+type Period string
+
+const (
+	PeriodAll   Period = "all"
+	PeriodDay   Period = "day"
+	PeriodWeek  Period = "week"
+	PeriodMonth Period = "month"
+)
+
+var Periods = []Period{PeriodDay, PeriodWeek, PeriodMonth, PeriodAll}
+
+func ParsePeriod(key string) (Period, bool) {
+	for _, p := range Periods {
+		if string(p) == key {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// Start returns when the period containing now began in loc: local midnight of
+// the day, of Monday, or of the first of the month. PeriodAll has no start.
+func (p Period) Start(now time.Time, loc *time.Location) (time.Time, bool) {
+	switch p {
+	case PeriodDay:
+		return StartOfDay(now, loc), true
+	case PeriodWeek:
+		return startOfWeek(now, loc), true
+	case PeriodMonth:
+		return startOfMonth(now, loc), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// Rating is the order a leaderboard is sorted in.
+type Rating string
+
+const (
+	RatingRich    Rating = "rich"
+	RatingDebtors Rating = "debtors"
+	RatingLucky   Rating = "lucky"
+	RatingStreaks Rating = "streaks"
+)
+
+// RatingQuery selects a leaderboard: a rating of one game in a chat over the
+// lifetime counters or, when Since is set, over the spins made since then.
+type RatingQuery struct {
+	ChatId int64
+	Game   Game
+	Rating Rating
+	Since  time.Time
+}
+
+// StartOfDay returns local midnight of the day t falls on in loc.
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+}
+
+func startOfWeek(t time.Time, loc *time.Location) time.Time {
+	midnight := StartOfDay(t, loc)
+	sinceMonday := (int(midnight.Weekday()) + 6) % 7
+	return midnight.AddDate(0, 0, -sinceMonday)
+}
+
+func startOfMonth(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriod_Start(t *testing.T) {
+	// Sunday, 2024-06-02 23:30 UTC is already Monday in Kyiv.
+	kyiv, err := time.LoadLocation("Europe/Kyiv")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	now := time.Date(2024, 6, 2, 23, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		period Period
+		want   time.Time
+		wantOK bool
+	}{
+		{PeriodDay, time.Date(2024, 6, 3, 0, 0, 0, 0, kyiv), true},
+		{PeriodWeek, time.Date(2024, 6, 3, 0, 0, 0, 0, kyiv), true},
+		{PeriodMonth, time.Date(2024, 6, 1, 0, 0, 0, 0, kyiv), true},
+		{PeriodAll, time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.period), func(t *testing.T) {
+			got, ok := tt.period.Start(now, kyiv)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("Start() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
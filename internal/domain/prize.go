@@ -0,0 +1,51 @@
+package domain
+
+import "math/bits"
+
+// PrizeSet is a set of 🎰 dice values (1..64) packed into a bitmask,
+// bit v-1 standing for value v.
+type PrizeSet uint64
+
+func NewPrizeSet(values []int) PrizeSet {
+	var s PrizeSet
+	for _, v := range values {
+		if v >= 1 && v <= 64 {
+			s |= 1 << (v - 1)
+		}
+	}
+	return s
+}
+
+func (s PrizeSet) Has(value int) bool {
+	return value >= 1 && value <= 64 && s&(1<<(value-1)) != 0
+}
+
+func (s PrizeSet) Toggle(value int) PrizeSet {
+	if value < 1 || value > 64 {
+		return s
+	}
+	return s ^ 1<<(value-1)
+}
+
+func (s PrizeSet) Len() int {
+	return bits.OnesCount64(uint64(s))
+}
+
+// Values returns the dice values in ascending order.
+func (s PrizeSet) Values() []int {
+	values := make([]int, 0, s.Len())
+	for v := 1; v <= 64; v++ {
+		if s.Has(v) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// PrizeMode is a named set of winning dice values. Built-in modes have fixed
+// keys, custom ones saved by chat admins are keyed by their id.
+type PrizeMode struct {
+	Key    string
+	Name   string
+	Values []int
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPrizeSet_RoundTrip(t *testing.T) {
+	s := NewPrizeSet([]int{64, 1, 43, 22, 0, 65})
+
+	if got, want := s.Values(), []int{1, 22, 43, 64}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Values() = %v, want %v", got, want)
+	}
+	if s.Len() != 4 {
+		t.Errorf("Len() = %d, want 4", s.Len())
+	}
+}
+
+func TestPrizeSet_Toggle(t *testing.T) {
+	s := NewPrizeSet([]int{64})
+
+	s = s.Toggle(1)
+	if !s.Has(1) || !s.Has(64) {
+		t.Errorf("after toggling 1 on: %v", s.Values())
+	}
+	s = s.Toggle(64)
+	if s.Has(64) {
+		t.Error("64 should be off after second toggle")
+	}
+	if s.Toggle(0) != s || s.Toggle(65) != s {
+		t.Error("out of range values should be ignored")
+	}
+}
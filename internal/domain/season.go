@@ -0,0 +1,68 @@
+package domain
+
+import "time"
+
+// Season is a closed stretch of play whose final standings are archived.
+// Number counts from 1 within a chat.
+type Season struct {
+	Number    int64
+	StartedAt time.Time
+	EndedAt   time.Time
+	// Champion is the top player of the requested game, empty if nobody played it.
+	Champion        string
+	ChampionBalance int64
+}
+
+// Season schedule modes: seasons end by hand only, every Monday, on the first
+// of every month, or once at the end of a given date.
+const (
+	SeasonsManual  = "off"
+	SeasonsWeekly  = "weekly"
+	SeasonsMonthly = "monthly"
+	SeasonsOnDate  = "date"
+)
+
+// SeasonEndDateLayout is how the end date of a SeasonsOnDate schedule is stored.
+const SeasonEndDateLayout = "2006-01-02"
+
+// SeasonSchedule tells when a chat's seasons roll over automatically.
+type SeasonSchedule struct {
+	Mode string
+	// EndDate is the last day of the season for SeasonsOnDate.
+	EndDate string
+	// CheckedAt is the last rollover moment already handled; earlier ones are ignored.
+	CheckedAt time.Time
+}
+
+// LastBoundary returns the latest scheduled rollover at or before now: local
+// midnight in loc starting a new week, month, or the day after EndDate.
+func (s SeasonSchedule) LastBoundary(now time.Time, loc *time.Location) (time.Time, bool) {
+	var boundary time.Time
+	switch s.Mode {
+	case SeasonsWeekly:
+		boundary = startOfWeek(now, loc)
+	case SeasonsMonthly:
+		boundary = startOfMonth(now, loc)
+	case SeasonsOnDate:
+		end, err := time.ParseInLocation(SeasonEndDateLayout, s.EndDate, loc)
+		if err != nil {
+			return time.Time{}, false
+		}
+		boundary = end.AddDate(0, 0, 1)
+		if boundary.After(now) {
+			return time.Time{}, false
+		}
+	default:
+		return time.Time{}, false
+	}
+	return boundary, true
+}
+
+// Due returns the rollover moment that has come and was not handled yet.
+func (s SeasonSchedule) Due(now time.Time, loc *time.Location) (time.Time, bool) {
+	boundary, ok := s.LastBoundary(now, loc)
+	if !ok || !boundary.After(s.CheckedAt) {
+		return time.Time{}, false
+	}
+	return boundary, true
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeasonSchedule_LastBoundary(t *testing.T) {
+	kyiv, err := time.LoadLocation("Europe/Kyiv")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	// Wednesday, 2024-05-15 10:00 in Kyiv.
+	now := time.Date(2024, 5, 15, 10, 0, 0, 0, kyiv)
+
+	tests := []struct {
+		name     string
+		schedule SeasonSchedule
+		want     time.Time
+		wantOK   bool
+	}{
+		{"manual", SeasonSchedule{Mode: SeasonsManual}, time.Time{}, false},
+		{"weekly", SeasonSchedule{Mode: SeasonsWeekly}, time.Date(2024, 5, 13, 0, 0, 0, 0, kyiv), true},
+		{"monthly", SeasonSchedule{Mode: SeasonsMonthly}, time.Date(2024, 5, 1, 0, 0, 0, 0, kyiv), true},
+		{"date passed", SeasonSchedule{Mode: SeasonsOnDate, EndDate: "2024-05-14"}, time.Date(2024, 5, 15, 0, 0, 0, 0, kyiv), true},
+		{"date today", SeasonSchedule{Mode: SeasonsOnDate, EndDate: "2024-05-15"}, time.Time{}, false},
+		{"bad date", SeasonSchedule{Mode: SeasonsOnDate, EndDate: "15.05"}, time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.schedule.LastBoundary(now, kyiv)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("LastBoundary() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSeasonSchedule_LastBoundaryOnMonday(t *testing.T) {
+	now := time.Date(2024, 5, 13, 0, 0, 5, 0, time.UTC)
+	got, _ := SeasonSchedule{Mode: SeasonsWeekly}.LastBoundary(now, time.UTC)
+	if want := time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("LastBoundary() = %v, want %v", got, want)
+	}
+}
+
+func TestSeasonSchedule_Due(t *testing.T) {
+	now := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+	monday := time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)
+
+	if _, ok := (SeasonSchedule{Mode: SeasonsWeekly, CheckedAt: monday}).Due(now, time.UTC); ok {
+		t.Error("boundary already handled should not be due")
+	}
+	// Turned on mid-week: the past Monday does not count.
+	if _, ok := (SeasonSchedule{Mode: SeasonsWeekly, CheckedAt: monday.Add(50 * time.Hour)}).Due(now, time.UTC); ok {
+		t.Error("boundary before the schedule was set should not be due")
+	}
+	got, ok := SeasonSchedule{Mode: SeasonsWeekly, CheckedAt: monday.AddDate(0, 0, -7)}.Due(now, time.UTC)
+	if !ok || !got.Equal(monday) {
+		t.Errorf("Due() = %v, %v, want %v, true", got, ok, monday)
+	}
+}
@@ -0,0 +1,104 @@
+package domain
+
+// Symbol is a single reel face of the 🎰 dice, in the order Telegram encodes them.
+type Symbol int
+
+const (
+	SymbolBar Symbol = iota
+	SymbolGrapes
+	SymbolLemon
+	SymbolSeven
+)
+
+func (s Symbol) String() string {
+	switch s {
+	case SymbolBar:
+		return "BAR"
+	case SymbolGrapes:
+		return "🍇"
+	case SymbolLemon:
+		return "🍋"
+	case SymbolSeven:
+		return "7️⃣"
+	default:
+		return "?"
+	}
+}
+
+// Reels holds the left, middle and right reel of a slot spin.
+type Reels [3]Symbol
+
+// DecodeSlot turns a 🎰 dice value (1..64) into its three reels.
+// Telegram packs the reels as base-4 digits of value-1, left reel first.
+func DecodeSlot(value int) Reels {
+	v := value - 1
+	return Reels{Symbol(v & 3), Symbol(v >> 2 & 3), Symbol(v >> 4 & 3)}
+}
+
+func (r Reels) String() string {
+	return r[0].String() + " " + r[1].String() + " " + r[2].String()
+}
+
+func (r Reels) count(s Symbol) int {
+	n := 0
+	for _, v := range r {
+		if v == s {
+			n++
+		}
+	}
+	return n
+}
+
+func (r Reels) isTriple() bool {
+	return r[0] == r[1] && r[1] == r[2]
+}
+
+// Combo is a key of the per-chat payout table.
+type Combo string
+
+const (
+	ComboSevens    Combo = "777"
+	ComboBars      Combo = "bar3"
+	ComboGrapes    Combo = "grapes3"
+	ComboLemons    Combo = "lemon3"
+	ComboTriple    Combo = "triple"
+	ComboTwoSevens Combo = "two_sevens"
+)
+
+// Combos lists every combination from the most specific to the most generic,
+// which is also the order they are checked in.
+var Combos = []Combo{ComboSevens, ComboBars, ComboGrapes, ComboLemons, ComboTriple, ComboTwoSevens}
+
+func (c Combo) Matches(r Reels) bool {
+	switch c {
+	case ComboSevens:
+		return r.count(SymbolSeven) == 3
+	case ComboBars:
+		return r.count(SymbolBar) == 3
+	case ComboGrapes:
+		return r.count(SymbolGrapes) == 3
+	case ComboLemons:
+		return r.count(SymbolLemon) == 3
+	case ComboTriple:
+		return r.isTriple()
+	case ComboTwoSevens:
+		return r.count(SymbolSeven) == 2
+	default:
+		return false
+	}
+}
+
+// PayoutTable maps combinations to the amount they pay.
+type PayoutTable map[Combo]int64
+
+// Payout returns the first combination in Combos that matches the reels and
+// has a positive amount in the table.
+func (t PayoutTable) Payout(r Reels) (Combo, int64, bool) {
+	for _, c := range Combos {
+		amount := t[c]
+		if amount > 0 && c.Matches(r) {
+			return c, amount, true
+		}
+	}
+	return "", 0, false
+}
@@ -0,0 +1,67 @@
+package domain
+
+import "testing"
+
+func TestDecodeSlot(t *testing.T) {
+	tests := []struct {
+		name  string
+		value int
+		want  Reels
+	}{
+		{"bars", 1, Reels{SymbolBar, SymbolBar, SymbolBar}},
+		{"grapes", 22, Reels{SymbolGrapes, SymbolGrapes, SymbolGrapes}},
+		{"lemons", 43, Reels{SymbolLemon, SymbolLemon, SymbolLemon}},
+		{"sevens", 64, Reels{SymbolSeven, SymbolSeven, SymbolSeven}},
+		{"left reel first", 2, Reels{SymbolGrapes, SymbolBar, SymbolBar}},
+		{"right reel last", 17, Reels{SymbolBar, SymbolBar, SymbolGrapes}},
+		{"mixed", 48, Reels{SymbolSeven, SymbolSeven, SymbolLemon}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DecodeSlot(tt.value); got != tt.want {
+				t.Errorf("DecodeSlot(%d) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPayoutTable_Payout(t *testing.T) {
+	table := PayoutTable{
+		ComboSevens:    500,
+		ComboTriple:    50,
+		ComboTwoSevens: 5,
+	}
+
+	tests := []struct {
+		name       string
+		value      int
+		wantCombo  Combo
+		wantAmount int64
+		wantOk     bool
+	}{
+		{"specific combo wins over triple", 64, ComboSevens, 500, true},
+		{"any triple", 22, ComboTriple, 50, true},
+		{"two sevens", 48, ComboTwoSevens, 5, true},
+		{"no match", 2, "", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			combo, amount, ok := table.Payout(DecodeSlot(tt.value))
+			if combo != tt.wantCombo || amount != tt.wantAmount || ok != tt.wantOk {
+				t.Errorf("Payout(%d) = (%q, %d, %v), want (%q, %d, %v)",
+					tt.value, combo, amount, ok, tt.wantCombo, tt.wantAmount, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestPayoutTable_ZeroAmountSkipped(t *testing.T) {
+	table := PayoutTable{ComboSevens: 0, ComboTriple: 50}
+
+	combo, amount, ok := table.Payout(DecodeSlot(64))
+	if !ok || combo != ComboTriple || amount != 50 {
+		t.Errorf("Payout(64) = (%q, %d, %v), want (triple, 50, true)", combo, amount, ok)
+	}
+}
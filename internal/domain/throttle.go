@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// Throttle is the anti-spam policy of a chat. Zero values disable the limits.
+type Throttle struct {
+	// Cooldown is the minimum pause between two counted spins of one player.
+	Cooldown time.Duration
+	// Burst is the maximum number of counted spins of one player per minute.
+	Burst int
+	// Cleanup queues throttled spins for deletion and warns the player.
+	Cleanup bool
+}
+
+func (t Throttle) Enabled() bool {
+	return t.Cooldown > 0 || t.Burst > 0
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+type TournamentStatus string
+
+const (
+	TournamentRunning  TournamentStatus = "running"
+	TournamentFinished TournamentStatus = "finished"
+)
+
+// Bounds of a tournament's length.
+const (
+	TournamentMinDuration = 10 * time.Minute
+	TournamentMaxDuration = 7 * 24 * time.Hour
+)
+
+// TournamentRule decides what the tournament standings are ordered by.
+type TournamentRule string
+
+const (
+	// TournamentWins ranks by winning spins, then by net balance.
+	TournamentWins TournamentRule = "wins"
+	// TournamentNet ranks by net balance of the tournament spins, then by wins.
+	TournamentNet TournamentRule = "net"
+)
+
+var TournamentRules = []TournamentRule{TournamentWins, TournamentNet}
+
+var tournamentRuleNames = map[TournamentRule]string{
+	TournamentWins: "🍾 Найбільше виграшів",
+	TournamentNet:  "💸 Найкращий баланс",
+}
+
+func ParseTournamentRule(key string) (TournamentRule, bool) {
+	r := TournamentRule(key)
+	_, ok := tournamentRuleNames[r]
+	return r, ok
+}
+
+func (r TournamentRule) Name() string {
+	return tournamentRuleNames[r]
+}
+
+// Tournament is a time-boxed contest of one game with its own scores; the
+// lifetime stats of the players are not affected by it.
+type Tournament struct {
+	Id        int64
+	ChatId    int64
+	Game      Game
+	Rule      TournamentRule
+	Status    TournamentStatus
+	StartedAt time.Time
+	EndsAt    time.Time
+	// MessageId is the standings message kept up to date while the tournament runs.
+	MessageId int64
+}
+
+// Counts reports whether a spin of the game made at `at` belongs to the tournament.
+func (t Tournament) Counts(game Game, at time.Time) bool {
+	return t.Status == TournamentRunning && game == t.Game && !at.Before(t.StartedAt) && at.Before(t.EndsAt)
+}
+
+// TournamentScore is a line of the tournament standings.
+type TournamentScore struct {
+	UserId   int64
+	Username string
+	Spins    int64
+	Wins     int64
+	Net      int64
+	Rank     int64
+}
+
+// ParseTournamentDuration reads a tournament length such as "2h", "90m",
+// "1h30m" or "3d" and checks it against the allowed bounds.
+func ParseTournamentDuration(s string) (time.Duration, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	var d time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, false
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, false
+		}
+	}
+	return d, d >= TournamentMinDuration && d <= TournamentMaxDuration
+}
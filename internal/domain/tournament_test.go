@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTournamentDuration(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   time.Duration
+		wantOk bool
+	}{
+		{"2h", 2 * time.Hour, true},
+		{"90m", 90 * time.Minute, true},
+		{"1h30m", 90 * time.Minute, true},
+		{"3d", 72 * time.Hour, true},
+		{"2H", 2 * time.Hour, true},
+		{"5m", 5 * time.Minute, false},
+		{"8d", 8 * 24 * time.Hour, false},
+		{"-2h", -2 * time.Hour, false},
+		{"soon", 0, false},
+		{"d", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, ok := ParseTournamentDuration(tt.in)
+			if ok != tt.wantOk || ok && got != tt.want {
+				t.Errorf("ParseTournamentDuration(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestTournament_Counts(t *testing.T) {
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	tr := Tournament{Game: GameSlot, Status: TournamentRunning, StartedAt: start, EndsAt: start.Add(2 * time.Hour)}
+
+	tests := []struct {
+		name string
+		game Game
+		at   time.Time
+		want bool
+	}{
+		{"at start", GameSlot, start, true},
+		{"inside", GameSlot, start.Add(time.Hour), true},
+		{"before start", GameSlot, start.Add(-time.Second), false},
+		{"at end", GameSlot, start.Add(2 * time.Hour), false},
+		{"other game", GameDice, start.Add(time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tr.Counts(tt.game, tt.at); got != tt.want {
+				t.Errorf("Counts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	tr.Status = TournamentFinished
+	if tr.Counts(GameSlot, start.Add(time.Hour)) {
+		t.Error("finished tournament counts spins")
+	}
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+type TransferStatus string
+
+const (
+	TransferPending   TransferStatus = "pending"
+	TransferDone      TransferStatus = "done"
+	TransferCancelled TransferStatus = "cancelled"
+)
+
+// Transfer moves coins from one player to another once the sender confirms it.
+type Transfer struct {
+	Id        int64
+	ChatId    int64
+	FromId    int64
+	FromName  string
+	ToId      int64
+	ToName    string
+	Amount    int64
+	Status    TransferStatus
+	CreatedAt time.Time
+}
+
+// TransferLimits are the chat's rules for transfers.
+type TransferLimits struct {
+	// Floor is the lowest balance a sender may be left with.
+	Floor int64
+	// DailyCap is how much one player may send per day; zero means no cap.
+	DailyCap int64
+	// DayStart is the start of the sender's current day in the chat's timezone.
+	DayStart time.Time
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 type PersonalStats struct {
 	Spins             int64
 	Wins              int64
@@ -13,6 +15,7 @@ type PersonalStats struct {
 }
 
 type RatingStats struct {
+	UserId            int64
 	Username          string
 	Spins             int64
 	Wins              int64
@@ -23,4 +26,67 @@ type RatingStats struct {
 	CurrentLossStreak int64
 	MaxLossStreak     int64
 	Luck              float64
+	// Bailouts is filled in only for the debtors leaderboard.
+	Bailouts int64
+}
+
+type Spin struct {
+	ChatId   int64
+	UserId   int64
+	Game     Game
+	Username string
+	// Handle is the player's Telegram username in lower case, without the @.
+	Handle    string
+	MessageId int64
+	Value     int
+	At        time.Time
+	Payout    int64
+	Cost      int64
+	// FreeWins keeps the rules of a chat that never set a spin cost: a win is
+	// paid in full and only a losing spin pays Cost.
+	FreeWins bool
+	// JackpotShare is the percent of Cost that a losing spin feeds into the pool.
+	JackpotShare int64
+	// Jackpot marks a spin that takes the whole pool on top of Payout.
+	Jackpot bool
+	// Stake is set when the spin settles a pending /bet of the player.
+	Stake int64
+	// LoanRepayShare is the percent of a 🎰 win that goes to the player's open loan.
+	LoanRepayShare int64
+	// DuelWindow is how long after acceptance a 🎰 still rolls in the player's
+	// duel; zero keeps the spin out of duels.
+	DuelWindow time.Duration
+	// DailyLimit caps counted spins of the player since DayStart; zero means unlimited.
+	DailyLimit int64
+	DayStart   time.Time
+}
+
+// Charged is the cost the spin pays once its total payout, jackpot included, is known.
+func (s Spin) Charged(payout int64) int64 {
+	if s.FreeWins && payout > 0 {
+		return 0
+	}
+	return s.Cost
+}
+
+type SpinResult struct {
+	// Duplicate is set when the message was already counted; nothing was changed.
+	Duplicate bool
+	// OverQuota is set when the daily limit was already used up; nothing was changed.
+	OverQuota  bool
+	JackpotWon int64
+	// Stake is the pending bet the spin settled, zero without one.
+	Stake int64
+	// LoanRepaid is what the win paid off the player's loan, and LoanDebt is
+	// what is still owed after it.
+	LoanRepaid int64
+	LoanDebt   int64
+	// Duel is the player's duel the spin rolled in, set when DuelRolled.
+	Duel       Duel
+	DuelRolled bool
+	// InTournament is set when the spin counted in the chat's running tournament.
+	InTournament bool
+	// XP is the player's experience after the spin, XPGained of it for the spin.
+	XP       int64
+	XPGained int64
 }
@@ -16,7 +16,7 @@ func GetSlotHandler(slotService *service.SlotService) ext.Handler {
 }
 
 func handleSlot(b *gotgbot.Bot, ctx *ext.Context, slotService *service.SlotService) error {
-	if ctx.EffectiveMessage.Dice.Emoji == "🎰" && ctx.Message.ForwardOrigin == nil {
+	if ctx.Message.ForwardOrigin == nil {
 		return slotService.HandleSlot(b, ctx)
 	}
 	return nil
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"database/sql"
+	"time"
+)
+
+// Achievements are kept per chat and survive season resets.
+type AchievementRepo struct {
+	db *sql.DB
+}
+
+func NewAchievementRepo(db *sql.DB) *AchievementRepo {
+	return &AchievementRepo{db: db}
+}
+
+// Unlock grants the achievements and returns those the player did not have yet.
+func (r *AchievementRepo) Unlock(chatId int64, userId int64, keys []domain.Achievement, at time.Time) ([]domain.Achievement, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var unlocked []domain.Achievement
+	for _, key := range keys {
+		res, err := tx.Exec(`
+			INSERT INTO achievements (chat_id, user_id, achievement, unlocked_at) VALUES (?, ?, ?, ?)
+			ON CONFLICT(chat_id, user_id, achievement) DO NOTHING`,
+			chatId, userId, key, at.Unix())
+		if err != nil {
+			return nil, err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return nil, err
+		} else if n > 0 {
+			unlocked = append(unlocked, key)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return unlocked, nil
+}
+
+func (r *AchievementRepo) GetAchievements(chatId int64, userId int64) ([]domain.UnlockedAchievement, error) {
+	rows, err := r.db.Query(`
+		SELECT achievement, unlocked_at FROM achievements
+		WHERE chat_id = ? AND user_id = ?
+		ORDER BY unlocked_at, achievement`, chatId, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.UnlockedAchievement
+	for rows.Next() {
+		var a domain.UnlockedAchievement
+		var unlockedAt int64
+		if err := rows.Scan(&a.Key, &unlockedAt); err != nil {
+			return nil, err
+		}
+		a.UnlockedAt = time.Unix(unlockedAt, 0)
+		res = append(res, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (r *AchievementRepo) CountAchievements(chatId int64, userId int64) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM achievements WHERE chat_id = ? AND user_id = ?`,
+		chatId, userId).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUnlock_OnlyNew(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewAchievementRepo(db)
+	at := time.Unix(1_700_000_000, 0)
+
+	got, err := repo.Unlock(100, 1, []domain.Achievement{domain.AchievementFirstWin}, at)
+	if err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, []domain.Achievement{domain.AchievementFirstWin}) {
+		t.Errorf("first unlock = %v", got)
+	}
+
+	got, _ = repo.Unlock(100, 1, []domain.Achievement{domain.AchievementFirstWin, domain.AchievementFirstSevens}, at.Add(time.Hour))
+	if !reflect.DeepEqual(got, []domain.Achievement{domain.AchievementFirstSevens}) {
+		t.Errorf("second unlock = %v, want only first_777", got)
+	}
+
+	list, err := repo.GetAchievements(100, 1)
+	if err != nil {
+		t.Fatalf("GetAchievements() error = %v", err)
+	}
+	if len(list) != 2 || list[0].Key != domain.AchievementFirstWin || !list[0].UnlockedAt.Equal(at) {
+		t.Errorf("achievements = %+v", list)
+	}
+}
+
+func TestCountAchievements_Isolation(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewAchievementRepo(db)
+	at := time.Unix(1_700_000_000, 0)
+
+	repo.Unlock(100, 1, []domain.Achievement{domain.AchievementFirstWin, domain.AchievementRich}, at)
+	repo.Unlock(100, 2, []domain.Achievement{domain.AchievementFirstWin}, at)
+	repo.Unlock(200, 1, []domain.Achievement{domain.AchievementFirstWin}, at)
+
+	count, err := repo.CountAchievements(100, 1)
+	if err != nil {
+		t.Fatalf("CountAchievements() error = %v", err)
+	}
+	if count != 2 {
+		t.Errorf("count = %d, want 2", count)
+	}
+}
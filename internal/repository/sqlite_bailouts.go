@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Bailouts are kept as a log: the count of a player's bailouts is their shame.
+type BailoutRepo struct {
+	db *sql.DB
+}
+
+func NewBailoutRepo(db *sql.DB) *BailoutRepo {
+	return &BailoutRepo{db: db}
+}
+
+// Bailout writes off the player's coin debt when it has reached the policy's
+// threshold and the previous bailout is at least a cooldown old.
+func (r *BailoutRepo) Bailout(chatId int64, userId int64, policy domain.Bailout, at time.Time) (domain.BailoutResult, error) {
+	var result domain.BailoutResult
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		SELECT COUNT(*) FROM bailouts WHERE chat_id = ? AND user_id = ?`,
+		chatId, userId).Scan(&result.Count)
+	if err != nil {
+		return result, err
+	}
+
+	var balance int64
+	err = tx.QueryRow(`
+		SELECT balance FROM user_stats WHERE chat_id = ? AND user_id = ? AND game = ?`,
+		chatId, userId, domain.CoinGame).Scan(&balance)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return result, err
+	}
+	result.Debt = balance
+	if balance > policy.Threshold {
+		return result, nil
+	}
+
+	var lastAt sql.NullInt64
+	err = tx.QueryRow(`
+		SELECT MAX(created_at) FROM bailouts WHERE chat_id = ? AND user_id = ?`,
+		chatId, userId).Scan(&lastAt)
+	if err != nil {
+		return result, err
+	}
+	if lastAt.Valid {
+		if retryAt := time.Unix(lastAt.Int64, 0).Add(policy.Cooldown); at.Before(retryAt) {
+			result.RetryAt = retryAt
+			return result, nil
+		}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE user_stats SET balance = 0 WHERE chat_id = ? AND user_id = ? AND game = ?`,
+		chatId, userId, domain.CoinGame)
+	if err != nil {
+		return result, err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO bailouts (chat_id, user_id, debt, created_at) VALUES (?, ?, ?, ?)`,
+		chatId, userId, -balance, at.Unix())
+	if err != nil {
+		return result, err
+	}
+	if err := tx.Commit(); err != nil {
+		return result, err
+	}
+	result.Granted = true
+	result.Debt = -balance
+	result.Count++
+	return result, nil
+}
+
+func (r *BailoutRepo) CountBailouts(chatId int64, userId int64) (int64, error) {
+	var count int64
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM bailouts WHERE chat_id = ? AND user_id = ?`,
+		chatId, userId).Scan(&count)
+	return count, err
+}
+
+// CountBailoutsOf returns the bailout counts of several players at once;
+// players who never had one are left out of the map.
+func (r *BailoutRepo) CountBailoutsOf(chatId int64, userIds []int64) (map[int64]int64, error) {
+	res := make(map[int64]int64, len(userIds))
+	if len(userIds) == 0 {
+		return res, nil
+	}
+	placeholders, args := userIdsIn(chatId, userIds)
+	rows, err := r.db.Query(`
+		SELECT user_id, COUNT(*) FROM bailouts
+		WHERE chat_id = ? AND user_id IN (`+placeholders+`)
+		GROUP BY user_id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userId, count int64
+		if err := rows.Scan(&userId, &count); err != nil {
+			return nil, err
+		}
+		res[userId] = count
+	}
+	return res, rows.Err()
+}
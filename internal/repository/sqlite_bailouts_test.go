@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"testing"
+	"time"
+)
+
+var testBailout = domain.Bailout{Threshold: -100, Cooldown: 24 * time.Hour}
+
+func TestBailout_WritesOffDebt(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	bailouts := NewBailoutRepo(db)
+
+	stats.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 1, Cost: 150})
+
+	at := time.Unix(1_000_000, 0)
+	result, err := bailouts.Bailout(100, 1, testBailout, at)
+	if err != nil {
+		t.Fatalf("Bailout() error = %v", err)
+	}
+	if !result.Granted || result.Debt != 150 || result.Count != 1 {
+		t.Errorf("Bailout() = %+v, want granted, debt 150, count 1", result)
+	}
+
+	alice, _ := stats.GetPersonalStats(100, 1, domain.GameSlot)
+	if alice.Balance != 0 || alice.Spins != 1 {
+		t.Errorf("balance, spins = %d, %d, want 0, 1", alice.Balance, alice.Spins)
+	}
+}
+
+func TestBailout_NotDeepEnough(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	bailouts := NewBailoutRepo(db)
+
+	stats.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 1, Cost: 99})
+
+	result, err := bailouts.Bailout(100, 1, testBailout, time.Unix(1_000_000, 0))
+	if err != nil {
+		t.Fatalf("Bailout() error = %v", err)
+	}
+	if result.Granted || result.Debt != -99 || !result.RetryAt.IsZero() {
+		t.Errorf("Bailout() = %+v, want refused with balance -99", result)
+	}
+}
+
+func TestBailout_Cooldown(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	bailouts := NewBailoutRepo(db)
+
+	at := time.Unix(1_000_000, 0)
+	stats.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 1, Cost: 200})
+	bailouts.Bailout(100, 1, testBailout, at)
+	stats.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 2, Cost: 200})
+
+	result, _ := bailouts.Bailout(100, 1, testBailout, at.Add(time.Hour))
+	if result.Granted || !result.RetryAt.Equal(at.Add(24*time.Hour)) {
+		t.Errorf("Bailout() within cooldown = %+v, want refused until %v", result, at.Add(24*time.Hour))
+	}
+
+	result, _ = bailouts.Bailout(100, 1, testBailout, at.Add(24*time.Hour))
+	if !result.Granted || result.Count != 2 {
+		t.Errorf("Bailout() after cooldown = %+v, want granted, count 2", result)
+	}
+}
+
+func TestCountBailoutsOf(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	bailouts := NewBailoutRepo(db)
+
+	stats.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 1, Cost: 200})
+	bailouts.Bailout(100, 1, testBailout, time.Unix(1_000_000, 0))
+
+	counts, err := bailouts.CountBailoutsOf(100, []int64{1, 2})
+	if err != nil {
+		t.Fatalf("CountBailoutsOf() error = %v", err)
+	}
+	if len(counts) != 1 || counts[1] != 1 {
+		t.Errorf("counts = %v, want map[1:1]", counts)
+	}
+}
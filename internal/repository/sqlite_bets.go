@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Pending bets live in the database, so a restart does not lose them; a bet
+// is settled by the player's next counted 🎰 in UserStatsRepo.Spin.
+type BetRepo struct {
+	db *sql.DB
+}
+
+func NewBetRepo(db *sql.DB) *BetRepo {
+	return &BetRepo{db: db}
+}
+
+// PlaceBet stores the bet, replacing a pending one of the same player.
+func (r *BetRepo) PlaceBet(bet domain.Bet) error {
+	_, err := r.db.Exec(`
+		INSERT INTO pending_bets (chat_id, user_id, stake, expires_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(chat_id, user_id) DO UPDATE SET
+			stake = excluded.stake,
+			expires_at = excluded.expires_at`,
+		bet.ChatId, bet.UserId, bet.Stake, bet.ExpiresAt.Unix())
+	return err
+}
+
+// GetBet returns the player's bet unless it has expired by now.
+func (r *BetRepo) GetBet(chatId int64, userId int64, now time.Time) (domain.Bet, bool, error) {
+	return getBet(r.db, chatId, userId, now)
+}
+
+func getBet(q rowQuerier, chatId int64, userId int64, now time.Time) (domain.Bet, bool, error) {
+	bet := domain.Bet{ChatId: chatId, UserId: userId}
+	var expiresAt int64
+	err := q.QueryRow(`
+		SELECT stake, expires_at FROM pending_bets
+		WHERE chat_id = ? AND user_id = ? AND expires_at > ?`,
+		chatId, userId, now.Unix()).Scan(&bet.Stake, &expiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return bet, false, nil
+		}
+		return bet, false, err
+	}
+	bet.ExpiresAt = time.Unix(expiresAt, 0)
+	return bet, true, nil
+}
+
+// CancelBet drops the player's bet and reports whether there was one.
+func (r *BetRepo) CancelBet(chatId int64, userId int64) (bool, error) {
+	res, err := r.db.Exec(`DELETE FROM pending_bets WHERE chat_id = ? AND user_id = ?`, chatId, userId)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// DeleteExpired drops every bet that ran out before now and returns how many.
+func (r *BetRepo) DeleteExpired(now time.Time) (int64, error) {
+	res, err := r.db.Exec(`DELETE FROM pending_bets WHERE expires_at <= ?`, now.Unix())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
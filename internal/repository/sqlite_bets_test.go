@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestPlaceBet_ReplacesPending(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	bets := NewBetRepo(db)
+
+	now := time.Unix(1_000_000, 0)
+	bets.PlaceBet(domain.Bet{ChatId: 100, UserId: 1, Stake: 10, ExpiresAt: now.Add(time.Minute)})
+	bets.PlaceBet(domain.Bet{ChatId: 100, UserId: 1, Stake: 50, ExpiresAt: now.Add(time.Minute)})
+
+	bet, ok, err := bets.GetBet(100, 1, now)
+	if err != nil {
+		t.Fatalf("GetBet() error = %v", err)
+	}
+	if !ok || bet.Stake != 50 {
+		t.Errorf("GetBet() = %+v, %v, want stake 50", bet, ok)
+	}
+}
+
+func TestGetBet_Expired(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	bets := NewBetRepo(db)
+
+	now := time.Unix(1_000_000, 0)
+	bets.PlaceBet(domain.Bet{ChatId: 100, UserId: 1, Stake: 10, ExpiresAt: now.Add(time.Minute)})
+
+	if _, ok, _ := bets.GetBet(100, 1, now.Add(time.Minute)); ok {
+		t.Error("GetBet() returned an expired bet")
+	}
+	deleted, err := bets.DeleteExpired(now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("DeleteExpired() error = %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("DeleteExpired() = %d, want 1", deleted)
+	}
+}
+
+func TestSpin_SettlesBet(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	bets := NewBetRepo(db)
+
+	now := time.Unix(1_000_000, 0)
+	bets.PlaceBet(domain.Bet{ChatId: 100, UserId: 1, Stake: 50, ExpiresAt: now.Add(time.Minute)})
+
+	result, err := stats.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 1, Cost: 1, At: now})
+	if err != nil {
+		t.Fatalf("Spin() error = %v", err)
+	}
+	if result.Stake != 50 {
+		t.Errorf("settled stake = %d, want 50", result.Stake)
+	}
+
+	if _, ok, _ := bets.GetBet(100, 1, now); ok {
+		t.Error("bet is still pending after the spin")
+	}
+	alice, _ := stats.GetPersonalStats(100, 1, domain.GameSlot)
+	if alice.Balance != -50 {
+		t.Errorf("balance = %d, want -50", alice.Balance)
+	}
+}
+
+func TestSpin_DuplicateKeepsBet(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	bets := NewBetRepo(db)
+
+	now := time.Unix(1_000_000, 0)
+	stats.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 1, Cost: 1, At: now})
+	bets.PlaceBet(domain.Bet{ChatId: 100, UserId: 1, Stake: 50, ExpiresAt: now.Add(time.Minute)})
+
+	result, _ := stats.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 1, Cost: 1, At: now})
+	if !result.Duplicate {
+		t.Fatal("Spin() of a counted message is not a duplicate")
+	}
+	if _, ok, _ := bets.GetBet(100, 1, now); !ok {
+		t.Error("a duplicate spin settled the bet")
+	}
+}
+
+func TestSpin_OtherGamesKeepBet(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	bets := NewBetRepo(db)
+
+	now := time.Unix(1_000_000, 0)
+	bets.PlaceBet(domain.Bet{ChatId: 100, UserId: 1, Stake: 50, ExpiresAt: now.Add(time.Minute)})
+
+	result, _ := stats.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameDice, Username: "alice", MessageId: 1, Cost: 1, At: now})
+	if result.Stake != 0 {
+		t.Errorf("a 🎲 settled stake %d", result.Stake)
+	}
+	if _, ok, _ := bets.GetBet(100, 1, now); !ok {
+		t.Error("a 🎲 settled the bet")
+	}
+}
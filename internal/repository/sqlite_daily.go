@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type DailyRepo struct {
+	db *sql.DB
+}
+
+func NewDailyRepo(db *sql.DB) *DailyRepo {
+	return &DailyRepo{db: db}
+}
+
+// Claim gives the player the daily bonus for the local day and credits it to
+// their coins. A claim made yesterday continues the streak, an older one
+// starts it over. When today's bonus is already taken, that claim is returned
+// with ok set to false and nothing changes.
+func (r *DailyRepo) Claim(chatId int64, userId int64, username string, day time.Time, base int64, at time.Time) (domain.DailyClaim, bool, error) {
+	var claim domain.DailyClaim
+	today := day.Format(domain.DayLayout)
+	yesterday := day.AddDate(0, 0, -1).Format(domain.DayLayout)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return claim, false, err
+	}
+	defer tx.Rollback()
+
+	var last domain.DailyClaim
+	var claimedAt int64
+	err = tx.QueryRow(`
+		SELECT day, streak, amount, claimed_at FROM daily_claims
+		WHERE chat_id = ? AND user_id = ?
+		ORDER BY day DESC LIMIT 1`,
+		chatId, userId).Scan(&last.Day, &last.Streak, &last.Amount, &claimedAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return claim, false, err
+	}
+	last.ClaimedAt = time.Unix(claimedAt, 0)
+	if last.Day == today {
+		return last, false, nil
+	}
+
+	claim = domain.DailyClaim{Day: today, Streak: 1, ClaimedAt: at}
+	if last.Day == yesterday {
+		claim.Streak = last.Streak + 1
+	}
+	claim.Amount = domain.DailyBonus(base, claim.Streak)
+
+	_, err = tx.Exec(`
+		INSERT INTO daily_claims (chat_id, user_id, day, streak, amount, claimed_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		chatId, userId, claim.Day, claim.Streak, claim.Amount, at.Unix())
+	if err != nil {
+		return claim, false, err
+	}
+	if err := addBalanceTx(tx, chatId, userId, username, claim.Amount); err != nil {
+		return claim, false, err
+	}
+	return claim, true, tx.Commit()
+}
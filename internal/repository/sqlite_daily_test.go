@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestClaim_OncePerDay(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	daily := NewDailyRepo(db)
+	stats := NewUserStatsRepo(db)
+
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	claim, ok, err := daily.Claim(100, 1, "alice", day, 10, day.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	if !ok || claim.Amount != 10 || claim.Streak != 1 {
+		t.Fatalf("Claim() = %+v, %v, want amount 10, streak 1, true", claim, ok)
+	}
+
+	again, ok, err := daily.Claim(100, 1, "alice", day, 10, day.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("second Claim() error = %v", err)
+	}
+	if ok {
+		t.Error("second Claim() on the same day succeeded")
+	}
+	if again.Day != "2025-03-10" || again.Amount != 10 {
+		t.Errorf("second Claim() = %+v, want today's claim", again)
+	}
+
+	alice, err := stats.GetPersonalStats(100, 1, domain.CoinGame)
+	if err != nil {
+		t.Fatalf("GetPersonalStats() error = %v", err)
+	}
+	if alice.Balance != 10 || alice.Spins != 0 {
+		t.Errorf("balance, spins = %d, %d, want 10, 0", alice.Balance, alice.Spins)
+	}
+}
+
+func TestClaim_Streak(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	daily := NewDailyRepo(db)
+
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	daily.Claim(100, 1, "alice", day, 10, day)
+	claim, _, _ := daily.Claim(100, 1, "alice", day.AddDate(0, 0, 1), 10, day.AddDate(0, 0, 1))
+	if claim.Streak != 2 || claim.Amount != 11 {
+		t.Errorf("next day claim = %+v, want streak 2, amount 11", claim)
+	}
+
+	claim, _, _ = daily.Claim(100, 1, "alice", day.AddDate(0, 0, 3), 10, day.AddDate(0, 0, 3))
+	if claim.Streak != 1 || claim.Amount != 10 {
+		t.Errorf("claim after a gap = %+v, want streak 1, amount 10", claim)
+	}
+}
+
+func TestClaim_AddsToSpinBalance(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	daily := NewDailyRepo(db)
+	stats := NewUserStatsRepo(db)
+
+	stats.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 1, Cost: 5})
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	daily.Claim(100, 1, "alice", day, 25, day)
+
+	alice, _ := stats.GetPersonalStats(100, 1, domain.GameSlot)
+	if alice.Balance != 20 || alice.Spins != 1 {
+		t.Errorf("balance, spins = %d, %d, want 20, 1", alice.Balance, alice.Spins)
+	}
+}
@@ -0,0 +1,313 @@
+package repository
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrDuelClosed is returned when a duel is no longer waiting for the action.
+	ErrDuelClosed = errors.New("duel is closed")
+	// ErrDuelBusy is returned when one of the players is already in an open duel.
+	ErrDuelBusy = errors.New("player is already in a duel")
+)
+
+type DuelRepo struct {
+	db *sql.DB
+}
+
+func NewDuelRepo(db *sql.DB) *DuelRepo {
+	return &DuelRepo{db: db}
+}
+
+const duelColumns = `id, chat_id, challenger_id, challenger_name, opponent_id, opponent_name, stake,
+	status, challenger_payout, opponent_payout, winner_id, created_at, accepted_at`
+
+func scanDuel(row interface{ Scan(...any) error }) (domain.Duel, error) {
+	var d domain.Duel
+	var createdAt, acceptedAt int64
+	err := row.Scan(&d.Id, &d.ChatId, &d.ChallengerId, &d.ChallengerName, &d.OpponentId, &d.OpponentName,
+		&d.Stake, &d.Status, &d.ChallengerPayout, &d.OpponentPayout, &d.WinnerId, &createdAt, &acceptedAt)
+	d.CreatedAt = time.Unix(createdAt, 0)
+	d.AcceptedAt = time.Unix(acceptedAt, 0)
+	return d, err
+}
+
+// CreateDuel stores a challenge unless either player already has an open duel
+// in the chat, and returns its id.
+func (r *DuelRepo) CreateDuel(d domain.Duel) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var open int64
+	err = tx.QueryRow(`
+		SELECT COUNT(*) FROM duels
+		WHERE chat_id = ? AND status IN (?, ?)
+		  AND (challenger_id IN (?, ?) OR opponent_id IN (?, ?))`,
+		d.ChatId, domain.DuelOffered, domain.DuelAccepted,
+		d.ChallengerId, d.OpponentId, d.ChallengerId, d.OpponentId).Scan(&open)
+	if err != nil {
+		return 0, err
+	}
+	if open > 0 {
+		return 0, ErrDuelBusy
+	}
+
+	res, err := tx.Exec(`
+		INSERT INTO duels (chat_id, challenger_id, challenger_name, opponent_id, opponent_name, stake, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		d.ChatId, d.ChallengerId, d.ChallengerName, d.OpponentId, d.OpponentName, d.Stake,
+		domain.DuelOffered, d.CreatedAt.Unix())
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+func (r *DuelRepo) GetDuel(id int64) (domain.Duel, error) {
+	return scanDuel(r.db.QueryRow(`SELECT `+duelColumns+` FROM duels WHERE id = ?`, id))
+}
+
+// Accept starts an offered duel.
+func (r *DuelRepo) Accept(id int64, at time.Time) error {
+	res, err := r.db.Exec(`UPDATE duels SET status = ?, accepted_at = ? WHERE id = ? AND status = ?`,
+		domain.DuelAccepted, at.Unix(), id, domain.DuelOffered)
+	return duelMoved(res, err)
+}
+
+// Cancel withdraws or declines an offered duel.
+func (r *DuelRepo) Cancel(id int64) error {
+	res, err := r.db.Exec(`UPDATE duels SET status = ? WHERE id = ? AND status = ?`,
+		domain.DuelCancelled, id, domain.DuelOffered)
+	return duelMoved(res, err)
+}
+
+// duelMoved turns an update of an offered duel that matched nothing into ErrDuelClosed.
+func duelMoved(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrDuelClosed
+	}
+	return nil
+}
+
+// recordRollTx counts the player's spin in their running duel, if they have
+// one accepted within the window and have not rolled in it yet. The duel is
+// settled as soon as both duelists have rolled; ok is false when the spin was
+// not a duel roll.
+func recordRollTx(tx *sql.Tx, chatId int64, userId int64, payout int64, at time.Time, window time.Duration) (domain.Duel, bool, error) {
+	d, err := scanDuel(tx.QueryRow(`
+		SELECT `+duelColumns+` FROM duels
+		WHERE chat_id = ? AND status = ? AND accepted_at > ?
+		  AND ((challenger_id = ? AND challenger_payout < 0) OR (opponent_id = ? AND opponent_payout < 0))
+		ORDER BY id LIMIT 1`,
+		chatId, domain.DuelAccepted, at.Add(-window).Unix(), userId, userId))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return d, false, nil
+		}
+		return d, false, err
+	}
+
+	column := "opponent_payout"
+	if d.ChallengerId == userId && d.ChallengerPayout < 0 {
+		column = "challenger_payout"
+		d.ChallengerPayout = payout
+	} else {
+		d.OpponentPayout = payout
+	}
+	if _, err := tx.Exec(`UPDATE duels SET `+column+` = ? WHERE id = ?`, payout, d.Id); err != nil {
+		return d, false, err
+	}
+	if d.ChallengerPayout >= 0 && d.OpponentPayout >= 0 {
+		if err := settleDuelTx(tx, &d, at); err != nil {
+			return d, false, err
+		}
+	}
+	return d, true, nil
+}
+
+// ExpireDuels closes the duels that ran out of time: offers nobody accepted
+// are cancelled, and accepted duels are settled with the rolls made so far,
+// or cancelled when nobody rolled. The settled and cancelled accepted duels
+// are returned to be announced.
+func (r *DuelRepo) ExpireDuels(now time.Time, offerTTL, window time.Duration) ([]domain.Duel, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`UPDATE duels SET status = ? WHERE status = ? AND created_at <= ?`,
+		domain.DuelCancelled, domain.DuelOffered, now.Add(-offerTTL).Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(`SELECT `+duelColumns+` FROM duels WHERE status = ? AND accepted_at <= ?`,
+		domain.DuelAccepted, now.Add(-window).Unix())
+	if err != nil {
+		return nil, err
+	}
+	var expired []domain.Duel
+	for rows.Next() {
+		d, err := scanDuel(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		expired = append(expired, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range expired {
+		d := &expired[i]
+		if d.ChallengerPayout < 0 && d.OpponentPayout < 0 {
+			if _, err := tx.Exec(`UPDATE duels SET status = ? WHERE id = ?`, domain.DuelCancelled, d.Id); err != nil {
+				return nil, err
+			}
+			d.Status = domain.DuelCancelled
+			continue
+		}
+		if err := settleDuelTx(tx, d, now); err != nil {
+			return nil, err
+		}
+	}
+	return expired, tx.Commit()
+}
+
+// settleDuelTx records the outcome and hands the loser's stake to the winner.
+func settleDuelTx(tx *sql.Tx, d *domain.Duel, at time.Time) error {
+	d.Status = domain.DuelDone
+	d.WinnerId = d.Winner()
+	_, err := tx.Exec(`UPDATE duels SET status = ?, winner_id = ?, finished_at = ? WHERE id = ?`,
+		d.Status, d.WinnerId, at.Unix(), d.Id)
+	if err != nil || d.WinnerId == 0 || d.Stake == 0 {
+		return err
+	}
+
+	winnerName, loserName := d.ChallengerName, d.OpponentName
+	if d.WinnerId == d.OpponentId {
+		winnerName, loserName = d.OpponentName, d.ChallengerName
+	}
+	if err := addBalanceTx(tx, d.ChatId, d.Loser(), loserName, -d.Stake); err != nil {
+		return err
+	}
+	return addBalanceTx(tx, d.ChatId, d.WinnerId, winnerName, d.Stake)
+}
+
+// GetDuelRecord returns the player's duel wins and losses in the chat.
+func (r *DuelRepo) GetDuelRecord(chatId int64, userId int64) (int64, int64, error) {
+	var wins, losses int64
+	err := r.db.QueryRow(`
+		SELECT COALESCE(SUM(winner_id = ?), 0), COALESCE(SUM(winner_id != 0 AND winner_id != ?), 0)
+		FROM duels
+		WHERE chat_id = ? AND status = ? AND (challenger_id = ? OR opponent_id = ?)`,
+		userId, userId, chatId, domain.DuelDone, userId, userId).Scan(&wins, &losses)
+	return wins, losses, err
+}
+
+// duelistsCTE defines `ranked` for the duelists leaderboard: wins first and
+// fewer losses on a tie, with the zero-based position breaking ties by user id
+// so pages are stable.
+func duelistsCTE(chatId int64) (string, []any) {
+	return `
+		WITH results AS (
+			SELECT id, challenger_id AS user_id, challenger_name AS username, winner_id
+			FROM duels WHERE chat_id = ? AND status = ?
+			UNION ALL
+			SELECT id, opponent_id, opponent_name, winner_id
+			FROM duels WHERE chat_id = ? AND status = ?
+		),
+		duelists AS (
+			SELECT user_id,
+			       (SELECT username FROM results last WHERE last.user_id = r.user_id ORDER BY id DESC LIMIT 1) AS username,
+			       SUM(winner_id = user_id) AS wins,
+			       SUM(winner_id != 0 AND winner_id != user_id) AS losses
+			FROM results r
+			GROUP BY user_id
+		),
+		ranked AS (
+			SELECT user_id, username, wins, losses,
+			       DENSE_RANK() OVER (ORDER BY wins DESC, losses ASC) AS rank,
+			       ROW_NUMBER() OVER (ORDER BY wins DESC, losses ASC, user_id) - 1 AS position
+			FROM duelists
+		)`, []any{chatId, domain.DuelDone, chatId, domain.DuelDone}
+}
+
+const duelistColumns = `user_id, username, wins, losses, rank, position`
+
+func scanDuelist(row interface{ Scan(...any) error }) (domain.DuelStats, int, error) {
+	var s domain.DuelStats
+	var position int
+	err := row.Scan(&s.UserId, &s.Username, &s.Wins, &s.Losses, &s.Rank, &position)
+	return s, position, err
+}
+
+// GetDuelRatingPage returns `limit` duelists starting at `offset`; a negative
+// limit returns everybody.
+func (r *DuelRepo) GetDuelRatingPage(chatId int64, limit, offset int) ([]domain.DuelStats, error) {
+	with, args := duelistsCTE(chatId)
+	rows, err := r.db.Query(with+`
+		SELECT `+duelistColumns+` FROM ranked
+		ORDER BY position
+		LIMIT ? OFFSET ?`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.DuelStats
+	for rows.Next() {
+		s, _, err := scanDuelist(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CountDuelRating returns how many players have finished a duel in the chat.
+func (r *DuelRepo) CountDuelRating(chatId int64) (int, error) {
+	with, args := duelistsCTE(chatId)
+	var count int
+	err := r.db.QueryRow(with+` SELECT COUNT(*) FROM duelists`, args...).Scan(&count)
+	return count, err
+}
+
+// GetDuelRatingEntry returns the player's line of the duelists leaderboard and
+// its zero-based position; ok is false when the player is not on it.
+func (r *DuelRepo) GetDuelRatingEntry(chatId int64, userId int64) (domain.DuelStats, int, bool, error) {
+	with, args := duelistsCTE(chatId)
+	s, position, err := scanDuelist(r.db.QueryRow(with+`
+		SELECT `+duelistColumns+` FROM ranked WHERE user_id = ?`, append(args, userId)...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.DuelStats{}, 0, false, nil
+		}
+		return domain.DuelStats{}, 0, false, err
+	}
+	return s, position, true, nil
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"errors"
+	"testing"
+	"time"
+)
+
+const testDuelWindow = 3 * time.Minute
+
+func newTestDuel(t *testing.T, repo *DuelRepo, stake int64, at time.Time) int64 {
+	t.Helper()
+	id, err := repo.CreateDuel(domain.Duel{
+		ChatId: 100, ChallengerId: 1, ChallengerName: "alice", OpponentId: 2, OpponentName: "bob",
+		Stake: stake, CreatedAt: at,
+	})
+	if err != nil {
+		t.Fatalf("CreateDuel() error = %v", err)
+	}
+	return id
+}
+
+// roll spins a 🎰 that may count in the player's duel; a zero payout is a
+// lost spin.
+func roll(t *testing.T, stats *UserStatsRepo, userId int64, username string, payout int64, at time.Time) domain.SpinResult {
+	t.Helper()
+	lastMessageId++
+	result, err := stats.Spin(domain.Spin{ChatId: 100, UserId: userId, Game: domain.GameSlot, Username: username,
+		MessageId: lastMessageId, At: at, Payout: payout, Cost: 1, FreeWins: true, DuelWindow: testDuelWindow})
+	if err != nil {
+		t.Fatalf("Spin() error = %v", err)
+	}
+	return result
+}
+
+func TestDuel_HigherPayoutTakesStake(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	duels := NewDuelRepo(db)
+	stats := NewUserStatsRepo(db)
+
+	at := time.Unix(1_000_000, 0)
+	id := newTestDuel(t, duels, 30, at)
+	if err := duels.Accept(id, at); err != nil {
+		t.Fatalf("Accept() error = %v", err)
+	}
+
+	result := roll(t, stats, 1, "alice", 0, at.Add(time.Minute))
+	if !result.DuelRolled {
+		t.Fatal("the spin did not roll in the duel")
+	}
+	if result.Duel.Status != domain.DuelAccepted {
+		t.Errorf("status after one roll = %q, want accepted", result.Duel.Status)
+	}
+	result = roll(t, stats, 2, "bob", 64, at.Add(2*time.Minute))
+	if d := result.Duel; d.Status != domain.DuelDone || d.WinnerId != 2 {
+		t.Errorf("duel = %+v, want done with bob winning", d)
+	}
+
+	alice, _ := stats.GetPersonalStats(100, 1, domain.CoinGame)
+	bob, _ := stats.GetPersonalStats(100, 2, domain.CoinGame)
+	if alice.Balance != -31 || bob.Balance != 94 {
+		t.Errorf("balances = %d, %d, want -31 (lost spin and stake), 94 (win and stake)", alice.Balance, bob.Balance)
+	}
+
+	if result := roll(t, stats, 1, "alice", 64, at.Add(2*time.Minute)); result.DuelRolled {
+		t.Error("a roll after the duel ended counted")
+	}
+}
+
+func TestDuel_OneOpenDuelPerPlayer(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	duels := NewDuelRepo(db)
+
+	at := time.Unix(1_000_000, 0)
+	newTestDuel(t, duels, 0, at)
+	_, err := duels.CreateDuel(domain.Duel{ChatId: 100, ChallengerId: 3, OpponentId: 2, CreatedAt: at})
+	if !errors.Is(err, ErrDuelBusy) {
+		t.Errorf("CreateDuel() error = %v, want ErrDuelBusy", err)
+	}
+}
+
+func TestDuel_CancelledOfferCannotBeAccepted(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	duels := NewDuelRepo(db)
+
+	at := time.Unix(1_000_000, 0)
+	id := newTestDuel(t, duels, 0, at)
+	if err := duels.Cancel(id); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	if err := duels.Accept(id, at); !errors.Is(err, ErrDuelClosed) {
+		t.Errorf("Accept() error = %v, want ErrDuelClosed", err)
+	}
+}
+
+func TestExpireDuels(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	duels := NewDuelRepo(db)
+	stats := NewUserStatsRepo(db)
+
+	at := time.Unix(1_000_000, 0)
+	offered := newTestDuel(t, duels, 0, at)
+
+	forfeit, _ := duels.CreateDuel(domain.Duel{ChatId: 100, ChallengerId: 3, ChallengerName: "carol",
+		OpponentId: 4, OpponentName: "dave", Stake: 10, CreatedAt: at})
+	duels.Accept(forfeit, at)
+	roll(t, stats, 3, "carol", 0, at.Add(time.Minute))
+
+	expired, err := duels.ExpireDuels(at.Add(10*time.Minute), 5*time.Minute, testDuelWindow)
+	if err != nil {
+		t.Fatalf("ExpireDuels() error = %v", err)
+	}
+	if len(expired) != 1 || expired[0].Id != forfeit || expired[0].WinnerId != 3 {
+		t.Fatalf("expired = %+v, want the forfeit won by carol", expired)
+	}
+	if d, _ := duels.GetDuel(offered); d.Status != domain.DuelCancelled {
+		t.Errorf("stale offer status = %q, want cancelled", d.Status)
+	}
+}
+
+func TestGetDuelRating(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	duels := NewDuelRepo(db)
+	stats := NewUserStatsRepo(db)
+
+	at := time.Unix(1_000_000, 0)
+	for i, payouts := range [][2]int64{{64, 0}, {64, 0}, {0, 0}} {
+		id := newTestDuel(t, duels, 0, at.Add(time.Duration(i)*time.Hour))
+		duels.Accept(id, at.Add(time.Duration(i)*time.Hour))
+		roll(t, stats, 1, "alice", payouts[0], at.Add(time.Duration(i)*time.Hour))
+		roll(t, stats, 2, "bob", payouts[1], at.Add(time.Duration(i)*time.Hour))
+	}
+
+	rating, err := duels.GetDuelRatingPage(100, 10, 0)
+	if err != nil {
+		t.Fatalf("GetDuelRatingPage() error = %v", err)
+	}
+	if len(rating) != 2 || rating[0].UserId != 1 || rating[0].Wins != 2 || rating[1].Losses != 2 || rating[1].Rank != 2 {
+		t.Errorf("rating = %+v, want alice 2-0 first and bob 0-2 second", rating)
+	}
+	if total, _ := duels.CountDuelRating(100); total != 2 {
+		t.Errorf("CountDuelRating() = %d, want 2", total)
+	}
+	second, _ := duels.GetDuelRatingPage(100, 1, 1)
+	if len(second) != 1 || second[0].UserId != 2 {
+		t.Errorf("second page = %+v, want bob", second)
+	}
+	bob, position, ok, err := duels.GetDuelRatingEntry(100, 2)
+	if err != nil || !ok || position != 1 || bob.Losses != 2 {
+		t.Errorf("GetDuelRatingEntry() = %+v, %d, %v, %v, want bob at 1", bob, position, ok, err)
+	}
+	if _, _, ok, _ := duels.GetDuelRatingEntry(100, 3); ok {
+		t.Error("a player without duels is on the board")
+	}
+
+	wins, losses, err := duels.GetDuelRecord(100, 2)
+	if err != nil {
+		t.Fatalf("GetDuelRecord() error = %v", err)
+	}
+	if wins != 0 || losses != 2 {
+		t.Errorf("bob record = %d-%d, want 0-2", wins, losses)
+	}
+}
+
+func TestSpin_DuplicateDoesNotRoll(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	duels := NewDuelRepo(db)
+	stats := NewUserStatsRepo(db)
+
+	at := time.Unix(1_000_000, 0)
+	id := newTestDuel(t, duels, 0, at)
+	duels.Accept(id, at)
+
+	spin := domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice",
+		MessageId: 1, At: at, Payout: 64, Cost: 1, DuelWindow: testDuelWindow}
+	stats.Spin(spin)
+	spin.Payout = 0
+	if result, _ := stats.Spin(spin); !result.Duplicate || result.DuelRolled {
+		t.Errorf("redelivered spin = %+v, want a duplicate without a roll", result)
+	}
+	if d, _ := duels.GetDuel(id); d.ChallengerPayout != 64 {
+		t.Errorf("challenger roll = %d, want the first delivery's 64", d.ChallengerPayout)
+	}
+}
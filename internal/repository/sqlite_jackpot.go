@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// The pool is stored in hundredths of a coin so that small shares of cheap
+// spins still add up instead of being rounded away.
+type JackpotRepo struct {
+	db *sql.DB
+}
+
+func NewJackpotRepo(db *sql.DB) *JackpotRepo {
+	return &JackpotRepo{db: db}
+}
+
+func (r *JackpotRepo) GetPool(chatId int64) (int64, error) {
+	var cents int64
+	err := r.db.QueryRow(`SELECT pool_cents FROM jackpots WHERE chat_id = ?`, chatId).Scan(&cents)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return cents / 100, nil
+}
+
+func addToPoolTx(tx *sql.Tx, chatId int64, cents int64) error {
+	_, err := tx.Exec(`
+		INSERT INTO jackpots (chat_id, pool_cents) VALUES (?, ?)
+		ON CONFLICT(chat_id) DO UPDATE SET pool_cents = pool_cents + excluded.pool_cents`,
+		chatId, cents)
+	return err
+}
+
+// takePoolTx empties the pool and returns its whole coins; the fractional
+// remainder stays as the seed of the next pool.
+func takePoolTx(tx *sql.Tx, chatId int64) (int64, error) {
+	var cents int64
+	err := tx.QueryRow(`SELECT pool_cents FROM jackpots WHERE chat_id = ?`, chatId).Scan(&cents)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if _, err := tx.Exec(`UPDATE jackpots SET pool_cents = pool_cents % 100 WHERE chat_id = ?`, chatId); err != nil {
+		return 0, err
+	}
+	return cents / 100, nil
+}
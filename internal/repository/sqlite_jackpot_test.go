@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"testing"
+)
+
+func TestGetPool_Default(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewJackpotRepo(db)
+
+	pool, err := repo.GetPool(100)
+	if err != nil {
+		t.Fatalf("GetPool() error = %v", err)
+	}
+	if pool != 0 {
+		t.Errorf("pool = %d, want 0", pool)
+	}
+}
+
+func TestSpin_LossFeedsJackpot(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	jackpots := NewJackpotRepo(db)
+
+	for i := int64(1); i <= 3; i++ {
+		stats.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: i, Cost: 1, JackpotShare: 50})
+	}
+
+	pool, _ := jackpots.GetPool(100)
+	if pool != 1 {
+		t.Errorf("pool = %d, want 1 (3 x 0.5, rounded down)", pool)
+	}
+}
+
+func TestSpin_WinDoesNotFeedJackpot(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	jackpots := NewJackpotRepo(db)
+
+	stats.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 1, Payout: 64, Cost: 10, JackpotShare: 100})
+
+	pool, _ := jackpots.GetPool(100)
+	if pool != 0 {
+		t.Errorf("pool = %d, want 0", pool)
+	}
+}
+
+func TestSpin_JackpotPaysWholePool(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	jackpots := NewJackpotRepo(db)
+
+	for i := int64(1); i <= 5; i++ {
+		stats.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: i, Cost: 10, JackpotShare: 25})
+	}
+	stats.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 6, Cost: 1, JackpotShare: 50})
+
+	result, err := stats.Spin(domain.Spin{ChatId: 100, UserId: 2, Game: domain.GameSlot, Username: "bob", MessageId: 7, Payout: 64, Cost: 1, Jackpot: true})
+	if err != nil {
+		t.Fatalf("Spin() error = %v", err)
+	}
+	if result.JackpotWon != 13 {
+		t.Errorf("JackpotWon = %d, want 13 (5 x 2.5 + 0.5)", result.JackpotWon)
+	}
+
+	bob, _ := stats.GetPersonalStats(100, 2, domain.GameSlot)
+	if bob.Balance != 76 {
+		t.Errorf("bob balance = %d, want 76 (64 + 13 - 1)", bob.Balance)
+	}
+
+	pool, _ := jackpots.GetPool(100)
+	if pool != 0 {
+		t.Errorf("pool after jackpot = %d, want 0", pool)
+	}
+
+	var ledgerPayout, ledgerJackpot int64
+	db.QueryRow(`SELECT payout, jackpot FROM spins WHERE user_id = 2`).Scan(&ledgerPayout, &ledgerJackpot)
+	if ledgerPayout != 77 || ledgerJackpot != 13 {
+		t.Errorf("ledger payout, jackpot = %d, %d, want 77, 13", ledgerPayout, ledgerJackpot)
+	}
+}
+
+func TestSpin_JackpotChatIsolation(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	jackpots := NewJackpotRepo(db)
+
+	stats.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 1, Cost: 10, JackpotShare: 100})
+	result, _ := stats.Spin(domain.Spin{ChatId: 200, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 2, Payout: 64, Cost: 1, Jackpot: true})
+
+	if result.JackpotWon != 0 {
+		t.Errorf("JackpotWon in chat 200 = %d, want 0", result.JackpotWon)
+	}
+	pool, _ := jackpots.GetPool(100)
+	if pool != 10 {
+		t.Errorf("chat 100 pool = %d, want 10", pool)
+	}
+}
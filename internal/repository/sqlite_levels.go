@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+// Experience is kept per chat and, unlike balances, survives season resets.
+type LevelRepo struct {
+	db *sql.DB
+}
+
+func NewLevelRepo(db *sql.DB) *LevelRepo {
+	return &LevelRepo{db: db}
+}
+
+// AddXP adds experience to the player and returns their new total.
+func (r *LevelRepo) AddXP(chatId int64, userId int64, xp int64) (int64, error) {
+	return addXP(r.db, chatId, userId, xp)
+}
+
+func addXP(q rowQuerier, chatId int64, userId int64, xp int64) (int64, error) {
+	var total int64
+	err := q.QueryRow(`
+		INSERT INTO player_xp (chat_id, user_id, xp) VALUES (?, ?, ?)
+		ON CONFLICT(chat_id, user_id) DO UPDATE SET xp = xp + excluded.xp
+		RETURNING xp`,
+		chatId, userId, xp).Scan(&total)
+	return total, err
+}
+
+func (r *LevelRepo) GetXP(chatId int64, userId int64) (int64, error) {
+	var xp int64
+	err := r.db.QueryRow(`SELECT xp FROM player_xp WHERE chat_id = ? AND user_id = ?`,
+		chatId, userId).Scan(&xp)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return xp, err
+}
+
+// GetXPs returns the experience of several players at once; players without
+// any are left out of the map.
+func (r *LevelRepo) GetXPs(chatId int64, userIds []int64) (map[int64]int64, error) {
+	res := make(map[int64]int64, len(userIds))
+	if len(userIds) == 0 {
+		return res, nil
+	}
+	placeholders, args := userIdsIn(chatId, userIds)
+	rows, err := r.db.Query(`
+		SELECT user_id, xp FROM player_xp
+		WHERE chat_id = ? AND user_id IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userId, xp int64
+		if err := rows.Scan(&userId, &xp); err != nil {
+			return nil, err
+		}
+		res[userId] = xp
+	}
+	return res, rows.Err()
+}
+
+// userIdsIn returns the placeholders of an IN list of the user ids and the
+// query arguments, chat id first.
+func userIdsIn(chatId int64, userIds []int64) (string, []any) {
+	args := []any{chatId}
+	for _, id := range userIds {
+		args = append(args, id)
+	}
+	return strings.TrimSuffix(strings.Repeat("?,", len(userIds)), ","), args
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestAddXP_Accumulates(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewLevelRepo(db)
+
+	if total, err := repo.AddXP(100, 1, 6); err != nil || total != 6 {
+		t.Fatalf("AddXP() = %d, %v, want 6, nil", total, err)
+	}
+	if total, _ := repo.AddXP(100, 1, 1); total != 7 {
+		t.Errorf("AddXP() total = %d, want 7", total)
+	}
+
+	xp, err := repo.GetXP(100, 1)
+	if err != nil {
+		t.Fatalf("GetXP() error = %v", err)
+	}
+	if xp != 7 {
+		t.Errorf("xp = %d, want 7", xp)
+	}
+}
+
+func TestGetXP_Default(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewLevelRepo(db)
+
+	xp, err := repo.GetXP(100, 1)
+	if err != nil {
+		t.Fatalf("GetXP() error = %v", err)
+	}
+	if xp != 0 {
+		t.Errorf("xp = %d, want 0", xp)
+	}
+}
+
+func TestGetXPs_ChatIsolation(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewLevelRepo(db)
+
+	repo.AddXP(100, 1, 10)
+	repo.AddXP(100, 2, 20)
+	repo.AddXP(200, 1, 99)
+
+	xps, err := repo.GetXPs(100, []int64{1, 2, 3})
+	if err != nil {
+		t.Fatalf("GetXPs() error = %v", err)
+	}
+	if len(xps) != 2 || xps[1] != 10 || xps[2] != 20 {
+		t.Errorf("xps = %v, want map[1:10 2:20]", xps)
+	}
+}
+
+func TestSpin_AwardsXP(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	levels := NewLevelRepo(db)
+
+	spin(t, stats, 100, 1, "alice", 0)
+	result := spin(t, stats, 100, 1, "alice", 64)
+	want := 2*domain.XPPerSpin + domain.XPPerWin
+	if result.XP != want || result.XPGained != domain.XPPerSpin+domain.XPPerWin {
+		t.Errorf("result XP = %d (+%d), want %d (+%d)", result.XP, result.XPGained, want, domain.XPPerSpin+domain.XPPerWin)
+	}
+
+	dup := domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice",
+		MessageId: lastMessageId, At: time.Unix(1_000_000, 0), Payout: 64, Cost: 1, FreeWins: true}
+	if result, _ := stats.Spin(dup); !result.Duplicate || result.XPGained != 0 {
+		t.Errorf("redelivered spin = %+v, want a duplicate without XP", result)
+	}
+	if xp, _ := levels.GetXP(100, 1); xp != want {
+		t.Errorf("xp = %d, want %d", xp, want)
+	}
+}
@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrLoanOpen is returned when the player already has a loan to repay.
+	ErrLoanOpen = errors.New("player already has an open loan")
+	// ErrNotInDebt is returned when the player's balance is not below zero.
+	ErrNotInDebt = errors.New("player is not in debt")
+)
+
+type LoanRepo struct {
+	db *sql.DB
+}
+
+func NewLoanRepo(db *sql.DB) *LoanRepo {
+	return &LoanRepo{db: db}
+}
+
+const loanColumns = `id, chat_id, user_id, username, principal, debt, rate, taken_at, accrued_at`
+
+func scanLoan(row interface{ Scan(...any) error }) (domain.Loan, error) {
+	var l domain.Loan
+	var takenAt, accruedAt int64
+	err := row.Scan(&l.Id, &l.ChatId, &l.UserId, &l.Username, &l.Principal, &l.Debt, &l.Rate, &takenAt, &accruedAt)
+	l.TakenAt = time.Unix(takenAt, 0)
+	l.AccruedAt = time.Unix(accruedAt, 0)
+	return l, err
+}
+
+func getOpenLoan(q rowQuerier, chatId int64, userId int64) (domain.Loan, bool, error) {
+	l, err := scanLoan(q.QueryRow(`SELECT `+loanColumns+` FROM loans WHERE chat_id = ? AND user_id = ? AND repaid_at = 0`,
+		chatId, userId))
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Loan{}, false, nil
+	}
+	return l, err == nil, err
+}
+
+// TakeLoan lends the amount to the player at the given rate and credits it to
+// their balance. Loans are for players in the red only, and a player has at
+// most one open loan.
+func (r *LoanRepo) TakeLoan(chatId int64, userId int64, username string, amount int64, rate int64, at time.Time) (domain.Loan, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return domain.Loan{}, err
+	}
+	defer tx.Rollback()
+
+	if _, open, err := getOpenLoan(tx, chatId, userId); err != nil {
+		return domain.Loan{}, err
+	} else if open {
+		return domain.Loan{}, ErrLoanOpen
+	}
+
+	var balance int64
+	err = tx.QueryRow(`
+		SELECT balance FROM user_stats WHERE chat_id = ? AND user_id = ? AND game = ?`,
+		chatId, userId, domain.CoinGame).Scan(&balance)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return domain.Loan{}, err
+	}
+	if balance >= 0 {
+		return domain.Loan{}, ErrNotInDebt
+	}
+
+	l := domain.Loan{ChatId: chatId, UserId: userId, Username: username, Principal: amount, Debt: amount,
+		Rate: rate, TakenAt: at, AccruedAt: at}
+	res, err := tx.Exec(`
+		INSERT INTO loans (chat_id, user_id, username, principal, debt, rate, taken_at, accrued_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		chatId, userId, username, amount, amount, rate, at.Unix(), at.Unix())
+	if err != nil {
+		return domain.Loan{}, err
+	}
+	if l.Id, err = res.LastInsertId(); err != nil {
+		return domain.Loan{}, err
+	}
+	if err := addBalanceTx(tx, chatId, userId, username, amount); err != nil {
+		return domain.Loan{}, err
+	}
+	return l, tx.Commit()
+}
+
+// GetLoan returns the player's open loan; ok is false when they owe nothing.
+func (r *LoanRepo) GetLoan(chatId int64, userId int64) (domain.Loan, bool, error) {
+	return getOpenLoan(r.db, chatId, userId)
+}
+
+// Repay pays off as much of the player's open loan as their positive balance covers.
+func (r *LoanRepo) Repay(chatId int64, userId int64, at time.Time) (domain.Loan, int64, error) {
+	return r.repay(chatId, userId, at, func(tx *sql.Tx, l domain.Loan) (int64, error) {
+		var balance int64
+		err := tx.QueryRow(`
+			SELECT balance FROM user_stats WHERE chat_id = ? AND user_id = ? AND game = ?`,
+			chatId, userId, domain.CoinGame).Scan(&balance)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return 0, err
+		}
+		return max(min(balance, l.Debt), 0), nil
+	})
+}
+
+func (r *LoanRepo) repay(chatId int64, userId int64, at time.Time, amountOf func(*sql.Tx, domain.Loan) (int64, error)) (domain.Loan, int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return domain.Loan{}, 0, err
+	}
+	defer tx.Rollback()
+
+	l, amount, err := repayTx(tx, chatId, userId, at, amountOf)
+	if err != nil {
+		return domain.Loan{}, 0, err
+	}
+	return l, amount, tx.Commit()
+}
+
+// repayTx takes amountOf the player's open loan from their balance. It returns
+// the loan after the repayment and the amount repaid, zero when there was
+// nothing to repay.
+func repayTx(tx *sql.Tx, chatId int64, userId int64, at time.Time, amountOf func(*sql.Tx, domain.Loan) (int64, error)) (domain.Loan, int64, error) {
+	l, open, err := getOpenLoan(tx, chatId, userId)
+	if err != nil || !open {
+		return domain.Loan{}, 0, err
+	}
+	amount, err := amountOf(tx, l)
+	if err != nil || amount == 0 {
+		return l, 0, err
+	}
+
+	l.Debt -= amount
+	var repaidAt int64
+	if l.Debt == 0 {
+		repaidAt = at.Unix()
+	}
+	if _, err := tx.Exec(`UPDATE loans SET debt = ?, repaid_at = ? WHERE id = ?`, l.Debt, repaidAt, l.Id); err != nil {
+		return domain.Loan{}, 0, err
+	}
+	if err := addBalanceTx(tx, chatId, userId, l.Username, -amount); err != nil {
+		return domain.Loan{}, 0, err
+	}
+	return l, amount, nil
+}
+
+// AccrueInterest adds the interest of every full interval that passed since
+// each open loan was last accrued, and returns how many loans were due.
+func (r *LoanRepo) AccrueInterest(now time.Time) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	interval := int64(domain.LoanInterval / time.Second)
+	rows, err := tx.Query(`SELECT `+loanColumns+` FROM loans WHERE repaid_at = 0 AND accrued_at <= ?`,
+		now.Unix()-interval)
+	if err != nil {
+		return 0, err
+	}
+	var due []domain.Loan
+	for rows.Next() {
+		l, err := scanLoan(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		due = append(due, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, l := range due {
+		intervals := (now.Unix() - l.AccruedAt.Unix()) / interval
+		_, err := tx.Exec(`UPDATE loans SET debt = ?, accrued_at = ? WHERE id = ?`,
+			domain.AccrueInterest(l.Debt, l.Principal, l.Rate, intervals), l.AccruedAt.Unix()+intervals*interval, l.Id)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(due)), tx.Commit()
+}
+
+// creditorsCTE defines `ranked` for the creditors leaderboard: the chat's open
+// loans, biggest debt first, with the zero-based position breaking ties by
+// user id so pages are stable.
+func creditorsCTE(chatId int64) (string, []any) {
+	return `
+		WITH ranked AS (
+			SELECT user_id, username, principal, debt,
+			       RANK() OVER (ORDER BY debt DESC) AS rank,
+			       ROW_NUMBER() OVER (ORDER BY debt DESC, user_id) - 1 AS position
+			FROM loans
+			WHERE chat_id = ? AND repaid_at = 0
+		)`, []any{chatId}
+}
+
+const creditorColumns = `user_id, username, principal, debt, rank, position`
+
+func scanCreditor(row interface{ Scan(...any) error }) (domain.LoanStats, int, error) {
+	var s domain.LoanStats
+	var position int
+	err := row.Scan(&s.UserId, &s.Username, &s.Principal, &s.Debt, &s.Rank, &position)
+	return s, position, err
+}
+
+// GetLoanRatingPage returns `limit` players with open loans starting at
+// `offset`; a negative limit returns everybody.
+func (r *LoanRepo) GetLoanRatingPage(chatId int64, limit, offset int) ([]domain.LoanStats, error) {
+	with, args := creditorsCTE(chatId)
+	rows, err := r.db.Query(with+`
+		SELECT `+creditorColumns+` FROM ranked
+		ORDER BY position
+		LIMIT ? OFFSET ?`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.LoanStats
+	for rows.Next() {
+		s, _, err := scanCreditor(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CountLoanRating returns how many players of the chat have an open loan.
+func (r *LoanRepo) CountLoanRating(chatId int64) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM loans WHERE chat_id = ? AND repaid_at = 0`, chatId).Scan(&count)
+	return count, err
+}
+
+// GetLoanRatingEntry returns the player's line of the creditors leaderboard
+// and its zero-based position; ok is false when the player owes nothing.
+func (r *LoanRepo) GetLoanRatingEntry(chatId int64, userId int64) (domain.LoanStats, int, bool, error) {
+	with, args := creditorsCTE(chatId)
+	s, position, err := scanCreditor(r.db.QueryRow(with+`
+		SELECT `+creditorColumns+` FROM ranked WHERE user_id = ?`, append(args, userId)...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.LoanStats{}, 0, false, nil
+		}
+		return domain.LoanStats{}, 0, false, err
+	}
+	return s, position, true, nil
+}
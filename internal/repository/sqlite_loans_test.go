@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTakeLoan_CreditsBalanceOnce(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	loans := NewLoanRepo(db)
+	at := time.Unix(1_000_000, 0)
+
+	if _, err := loans.TakeLoan(100, 1, "alice", 200, 5, at); !errors.Is(err, ErrNotInDebt) {
+		t.Errorf("TakeLoan() without debt error = %v, want ErrNotInDebt", err)
+	}
+	spin(t, stats, 100, 1, "alice", 0)
+	spin(t, stats, 200, 1, "alice", 0)
+
+	loan, err := loans.TakeLoan(100, 1, "alice", 200, 5, at)
+	if err != nil {
+		t.Fatalf("TakeLoan() error = %v", err)
+	}
+	if loan.Debt != 200 || loan.Rate != 5 {
+		t.Errorf("loan = %+v, want debt 200 at 5%%", loan)
+	}
+	if _, err := loans.TakeLoan(100, 1, "alice", 50, 5, at); !errors.Is(err, ErrLoanOpen) {
+		t.Errorf("second TakeLoan() error = %v, want ErrLoanOpen", err)
+	}
+	if _, err := loans.TakeLoan(200, 1, "alice", 50, 5, at); err != nil {
+		t.Errorf("TakeLoan() in another chat error = %v", err)
+	}
+
+	alice, _ := stats.GetPersonalStats(100, 1, domain.CoinGame)
+	if alice.Balance != 199 {
+		t.Errorf("alice balance = %d, want 199", alice.Balance)
+	}
+	got, ok, _ := loans.GetLoan(100, 1)
+	if !ok || got.Id != loan.Id {
+		t.Errorf("GetLoan() = %+v, %v", got, ok)
+	}
+}
+
+func TestSpin_RepaysLoanFromWin(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	loans := NewLoanRepo(db)
+	at := time.Unix(1_000_000, 0)
+	spin(t, stats, 100, 1, "alice", 0)
+	loans.TakeLoan(100, 1, "alice", 40, 5, at)
+
+	win := func(payout int64) domain.SpinResult {
+		t.Helper()
+		lastMessageId++
+		result, err := stats.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice",
+			MessageId: lastMessageId, At: at, Payout: payout, Cost: 1, FreeWins: true, LoanRepayShare: 50})
+		if err != nil {
+			t.Fatalf("Spin() error = %v", err)
+		}
+		return result
+	}
+
+	if result := win(0); result.LoanRepaid != 0 {
+		t.Errorf("a loss repaid %d", result.LoanRepaid)
+	}
+	if result := win(64); result.LoanRepaid != 32 || result.LoanDebt != 8 {
+		t.Errorf("repaid, debt = %d, %d, want 32, 8", result.LoanRepaid, result.LoanDebt)
+	}
+	if result := win(64); result.LoanRepaid != 8 || result.LoanDebt != 0 {
+		t.Errorf("second repayment repaid, debt = %d, %d, want 8, 0", result.LoanRepaid, result.LoanDebt)
+	}
+	if _, ok, _ := loans.GetLoan(100, 1); ok {
+		t.Error("cleared loan is still open")
+	}
+	if result := win(64); result.LoanRepaid != 0 {
+		t.Errorf("repayment without a loan = %d, want 0", result.LoanRepaid)
+	}
+
+	alice, _ := stats.GetPersonalStats(100, 1, domain.CoinGame)
+	if alice.Balance != 190 {
+		t.Errorf("alice balance = %d, want 190 (two losses, 40 borrowed and repaid, three wins)", alice.Balance)
+	}
+}
+
+func TestRepay_FromBalance(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	loans := NewLoanRepo(db)
+	at := time.Unix(1_000_000, 0)
+	spin(t, stats, 100, 1, "alice", 0)
+	loans.TakeLoan(100, 1, "alice", 100, 5, at)
+	// spend most of it
+	for range 69 {
+		spin(t, stats, 100, 1, "alice", 0)
+	}
+
+	loan, repaid, err := loans.Repay(100, 1, at)
+	if err != nil {
+		t.Fatalf("Repay() error = %v", err)
+	}
+	if repaid != 30 || loan.Debt != 70 {
+		t.Errorf("repaid, debt = %d, %d, want 30, 70", repaid, loan.Debt)
+	}
+	if _, repaid, _ := loans.Repay(100, 1, at); repaid != 0 {
+		t.Errorf("Repay() with empty balance = %d, want 0", repaid)
+	}
+}
+
+func TestAccrueInterest_FullDaysOnly(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	loans := NewLoanRepo(db)
+	at := time.Unix(1_000_000, 0)
+	spin(t, stats, 100, 1, "alice", 0)
+	spin(t, stats, 100, 2, "bob", 0)
+	loans.TakeLoan(100, 1, "alice", 100, 10, at)
+	loans.TakeLoan(100, 2, "bob", 100, 10, at.Add(12*time.Hour))
+
+	n, err := loans.AccrueInterest(at.Add(47 * time.Hour))
+	if err != nil {
+		t.Fatalf("AccrueInterest() error = %v", err)
+	}
+	if n != 2 {
+		t.Errorf("AccrueInterest() grew %d loans, want 2", n)
+	}
+	alice, _, _ := loans.GetLoan(100, 1)
+	bob, _, _ := loans.GetLoan(100, 2)
+	if alice.Debt != 110 || bob.Debt != 110 {
+		t.Errorf("debts = %d, %d, want 110, 110", alice.Debt, bob.Debt)
+	}
+
+	// alice's second day is due, bob's is not yet
+	loans.AccrueInterest(at.Add(48 * time.Hour))
+	alice, _, _ = loans.GetLoan(100, 1)
+	bob, _, _ = loans.GetLoan(100, 2)
+	if alice.Debt != 121 || bob.Debt != 110 {
+		t.Errorf("debts = %d, %d, want 121, 110", alice.Debt, bob.Debt)
+	}
+}
+
+func TestGetLoanRatingPage(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	loans := NewLoanRepo(db)
+	at := time.Unix(1_000_000, 0)
+	for _, p := range []struct {
+		chatId, userId int64
+		name           string
+	}{{100, 1, "alice"}, {100, 2, "bob"}, {100, 3, "carol"}, {200, 4, "dave"}} {
+		spin(t, stats, p.chatId, p.userId, p.name, 0)
+	}
+	loans.TakeLoan(100, 1, "alice", 100, 5, at)
+	loans.TakeLoan(100, 2, "bob", 300, 5, at)
+	loans.TakeLoan(100, 3, "carol", 10, 5, at)
+	lastMessageId++
+	stats.Spin(domain.Spin{ChatId: 100, UserId: 3, Game: domain.GameSlot, Username: "carol",
+		MessageId: lastMessageId, At: at, Payout: 64, Cost: 1, FreeWins: true, LoanRepayShare: 100})
+	loans.TakeLoan(200, 4, "dave", 1000, 5, at)
+
+	rating, err := loans.GetLoanRatingPage(100, 10, 0)
+	if err != nil {
+		t.Fatalf("GetLoanRatingPage() error = %v", err)
+	}
+	if len(rating) != 2 || rating[0].Username != "bob" || rating[1].Username != "alice" {
+		t.Fatalf("rating = %+v, want bob, alice", rating)
+	}
+	if rating[0].Rank != 1 || rating[1].Rank != 2 || rating[0].Debt != 300 {
+		t.Errorf("rating = %+v", rating)
+	}
+	if total, _ := loans.CountLoanRating(100); total != 2 {
+		t.Errorf("CountLoanRating() = %d, want 2", total)
+	}
+	second, _ := loans.GetLoanRatingPage(100, 1, 1)
+	if len(second) != 1 || second[0].Username != "alice" {
+		t.Errorf("second page = %+v, want alice", second)
+	}
+	alice, position, ok, err := loans.GetLoanRatingEntry(100, 1)
+	if err != nil || !ok || position != 1 || alice.Debt != 100 {
+		t.Errorf("GetLoanRatingEntry() = %+v, %d, %v, %v, want alice at 1", alice, position, ok, err)
+	}
+	if _, _, ok, _ := loans.GetLoanRatingEntry(100, 3); ok {
+		t.Error("a player who repaid is on the board")
+	}
+}
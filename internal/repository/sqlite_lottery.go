@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrLotteryFunds is returned when the player's balance does not cover the tickets.
+var ErrLotteryFunds = errors.New("not enough balance for the tickets")
+
+type LotteryRepo struct {
+	db *sql.DB
+}
+
+func NewLotteryRepo(db *sql.DB) *LotteryRepo {
+	return &LotteryRepo{db: db}
+}
+
+// LotteryTicket is a ticket of a drawn or open lottery round.
+type LotteryTicket struct {
+	Id       int64
+	UserId   int64
+	Username string
+}
+
+// BuyTickets charges the player for `count` tickets of the open round and
+// adds the money to the chat's pot. It returns how many open tickets the
+// player holds and the pot after the purchase.
+func (r *LotteryRepo) BuyTickets(chatId int64, userId int64, username string, count int64, price int64, at time.Time) (int64, int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	var balance int64
+	err = tx.QueryRow(`
+		SELECT balance FROM user_stats WHERE chat_id = ? AND user_id = ? AND game = ?`,
+		chatId, userId, domain.CoinGame).Scan(&balance)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, 0, err
+	}
+	cost := count * price
+	if balance < cost {
+		return 0, 0, ErrLotteryFunds
+	}
+
+	if err := addBalanceTx(tx, chatId, userId, username, -cost); err != nil {
+		return 0, 0, err
+	}
+	for range count {
+		_, err := tx.Exec(`
+			INSERT INTO lottery_tickets (chat_id, user_id, username, price, bought_at) VALUES (?, ?, ?, ?, ?)`,
+			chatId, userId, username, price, at.Unix())
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	var pot int64
+	err = tx.QueryRow(`
+		INSERT INTO lottery_pots (chat_id, amount) VALUES (?, ?)
+		ON CONFLICT(chat_id) DO UPDATE SET amount = amount + excluded.amount
+		RETURNING amount`,
+		chatId, cost).Scan(&pot)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var owned int64
+	err = tx.QueryRow(`
+		SELECT COUNT(*) FROM lottery_tickets WHERE chat_id = ? AND user_id = ? AND draw_id = 0`,
+		chatId, userId).Scan(&owned)
+	if err != nil {
+		return 0, 0, err
+	}
+	return owned, pot, tx.Commit()
+}
+
+// GetPot returns the chat's current lottery pot.
+func (r *LotteryRepo) GetPot(chatId int64) (int64, error) {
+	var pot int64
+	err := r.db.QueryRow(`SELECT amount FROM lottery_pots WHERE chat_id = ?`, chatId).Scan(&pot)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return pot, err
+}
+
+// CountTickets returns the player's and everybody's tickets in the open round.
+func (r *LotteryRepo) CountTickets(chatId int64, userId int64) (int64, int64, error) {
+	var mine, total int64
+	err := r.db.QueryRow(`
+		SELECT COALESCE(SUM(user_id = ?), 0), COUNT(*)
+		FROM lottery_tickets WHERE chat_id = ? AND draw_id = 0`,
+		userId, chatId).Scan(&mine, &total)
+	return mine, total, err
+}
+
+// Draw closes the open round. Prizes left unclaimed since earlier draws go
+// back to the pot first; then the winning ticket is picked from the round's
+// tickets with the seed, and the whole pot is set aside for its owner to
+// claim. ok is false when nobody bought a ticket, the pot then stays.
+// Either way the draw at `at` is marked as handled in the chat settings.
+func (r *LotteryRepo) Draw(chatId int64, seed uint64, at time.Time) (domain.LotteryDraw, bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return domain.LotteryDraw{}, false, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO chat_settings (chat_id, lottery_checked_at) VALUES (?, ?)
+		ON CONFLICT(chat_id) DO UPDATE SET lottery_checked_at = excluded.lottery_checked_at`,
+		chatId, at.Unix())
+	if err != nil {
+		return domain.LotteryDraw{}, false, err
+	}
+
+	var rolled int64
+	err = tx.QueryRow(`SELECT COALESCE(SUM(pot), 0) FROM lottery_draws WHERE chat_id = ? AND status = ?`,
+		chatId, domain.LotteryWon).Scan(&rolled)
+	if err != nil {
+		return domain.LotteryDraw{}, false, err
+	}
+	if rolled > 0 {
+		_, err = tx.Exec(`UPDATE lottery_draws SET status = ? WHERE chat_id = ? AND status = ?`,
+			domain.LotteryRolled, chatId, domain.LotteryWon)
+		if err != nil {
+			return domain.LotteryDraw{}, false, err
+		}
+		_, err = tx.Exec(`
+			INSERT INTO lottery_pots (chat_id, amount) VALUES (?, ?)
+			ON CONFLICT(chat_id) DO UPDATE SET amount = amount + excluded.amount`,
+			chatId, rolled)
+		if err != nil {
+			return domain.LotteryDraw{}, false, err
+		}
+	}
+
+	tickets, err := queryTickets(tx, `WHERE chat_id = ? AND draw_id = 0`, chatId)
+	if err != nil {
+		return domain.LotteryDraw{}, false, err
+	}
+	if len(tickets) == 0 {
+		return domain.LotteryDraw{}, false, tx.Commit()
+	}
+
+	d := domain.LotteryDraw{
+		ChatId:  chatId,
+		DrawnAt: at,
+		Seed:    seed,
+		Tickets: int64(len(tickets)),
+		Status:  domain.LotteryWon,
+	}
+	winner := tickets[domain.LotteryWinner(seed, len(tickets))]
+	d.TicketId, d.WinnerId, d.WinnerName = winner.Id, winner.UserId, winner.Username
+	for _, t := range tickets {
+		if t.UserId == d.WinnerId {
+			d.WinnerCount++
+		}
+	}
+
+	err = tx.QueryRow(`SELECT amount FROM lottery_pots WHERE chat_id = ?`, chatId).Scan(&d.Pot)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return domain.LotteryDraw{}, false, err
+	}
+	if _, err := tx.Exec(`UPDATE lottery_pots SET amount = 0 WHERE chat_id = ?`, chatId); err != nil {
+		return domain.LotteryDraw{}, false, err
+	}
+	res, err := tx.Exec(`
+		INSERT INTO lottery_draws (chat_id, drawn_at, seed, tickets, pot, ticket_id, winner_id, winner_name, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		chatId, at.Unix(), int64(seed), d.Tickets, d.Pot, d.TicketId, d.WinnerId, d.WinnerName, d.Status)
+	if err != nil {
+		return domain.LotteryDraw{}, false, err
+	}
+	if d.Id, err = res.LastInsertId(); err != nil {
+		return domain.LotteryDraw{}, false, err
+	}
+	_, err = tx.Exec(`UPDATE lottery_tickets SET draw_id = ? WHERE chat_id = ? AND draw_id = 0`, d.Id, chatId)
+	if err != nil {
+		return domain.LotteryDraw{}, false, err
+	}
+	return d, true, tx.Commit()
+}
+
+// Claim pays the player every prize they won and have not claimed yet, and
+// returns the amount.
+func (r *LotteryRepo) Claim(chatId int64, userId int64, at time.Time) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var prize int64
+	var username string
+	err = tx.QueryRow(`
+		SELECT COALESCE(SUM(pot), 0), COALESCE(MAX(winner_name), '')
+		FROM lottery_draws WHERE chat_id = ? AND winner_id = ? AND status = ?`,
+		chatId, userId, domain.LotteryWon).Scan(&prize, &username)
+	if err != nil || prize == 0 {
+		return 0, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE lottery_draws SET status = ?, claimed_at = ?
+		WHERE chat_id = ? AND winner_id = ? AND status = ?`,
+		domain.LotteryClaimed, at.Unix(), chatId, userId, domain.LotteryWon)
+	if err != nil {
+		return 0, err
+	}
+	if err := addBalanceTx(tx, chatId, userId, username, prize); err != nil {
+		return 0, err
+	}
+	return prize, tx.Commit()
+}
+
+// GetUnclaimed returns the prize the player won and has not claimed yet.
+func (r *LotteryRepo) GetUnclaimed(chatId int64, userId int64) (int64, error) {
+	var prize int64
+	err := r.db.QueryRow(`
+		SELECT COALESCE(SUM(pot), 0) FROM lottery_draws
+		WHERE chat_id = ? AND winner_id = ? AND status = ?`,
+		chatId, userId, domain.LotteryWon).Scan(&prize)
+	return prize, err
+}
+
+// GetLastDraw returns the chat's latest draw; ok is false before the first one.
+func (r *LotteryRepo) GetLastDraw(chatId int64) (domain.LotteryDraw, bool, error) {
+	var d domain.LotteryDraw
+	var drawnAt, seed int64
+	err := r.db.QueryRow(`
+		SELECT id, chat_id, drawn_at, seed, tickets, pot, ticket_id, winner_id, winner_name, status,
+		       (SELECT COUNT(*) FROM lottery_tickets t WHERE t.draw_id = d.id AND t.user_id = d.winner_id)
+		FROM lottery_draws d WHERE chat_id = ? ORDER BY id DESC LIMIT 1`,
+		chatId).Scan(&d.Id, &d.ChatId, &drawnAt, &seed, &d.Tickets, &d.Pot, &d.TicketId,
+		&d.WinnerId, &d.WinnerName, &d.Status, &d.WinnerCount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.LotteryDraw{}, false, nil
+	}
+	if err != nil {
+		return domain.LotteryDraw{}, false, err
+	}
+	d.DrawnAt = time.Unix(drawnAt, 0)
+	d.Seed = uint64(seed)
+	return d, true, nil
+}
+
+// GetDrawTickets returns the tickets of a drawn round in the order the draw
+// picked from.
+func (r *LotteryRepo) GetDrawTickets(drawId int64) ([]LotteryTicket, error) {
+	return queryTickets(r.db, `WHERE draw_id = ?`, drawId)
+}
+
+func queryTickets(q interface {
+	Query(string, ...any) (*sql.Rows, error)
+}, where string, args ...any) ([]LotteryTicket, error) {
+	rows, err := q.Query(`SELECT id, user_id, username FROM lottery_tickets `+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []LotteryTicket
+	for rows.Next() {
+		var t LotteryTicket
+		if err := rows.Scan(&t.Id, &t.UserId, &t.Username); err != nil {
+			return nil, err
+		}
+		res = append(res, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fundPlayer gives the player a 64 coin win to buy tickets with.
+func fundPlayer(stats *UserStatsRepo, userId int64, name string, messageId int64) {
+	stats.Spin(domain.Spin{ChatId: 100, UserId: userId, Game: domain.GameSlot, Username: name, MessageId: messageId, Payout: 64})
+}
+
+func TestBuyTickets_ChargesAndFeedsPot(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	lottery := NewLotteryRepo(db)
+	fundPlayer(stats, 1, "alice", 1)
+	at := time.Unix(1_000_000, 0)
+
+	owned, pot, err := lottery.BuyTickets(100, 1, "alice", 3, 10, at)
+	if err != nil {
+		t.Fatalf("BuyTickets() error = %v", err)
+	}
+	if owned != 3 || pot != 30 {
+		t.Errorf("owned, pot = %d, %d, want 3, 30", owned, pot)
+	}
+	owned, pot, _ = lottery.BuyTickets(100, 1, "alice", 2, 10, at)
+	if owned != 5 || pot != 50 {
+		t.Errorf("after second purchase owned, pot = %d, %d, want 5, 50", owned, pot)
+	}
+
+	alice, _ := stats.GetPersonalStats(100, 1, domain.CoinGame)
+	if alice.Balance != 14 {
+		t.Errorf("alice balance = %d, want 14", alice.Balance)
+	}
+
+	if _, _, err := lottery.BuyTickets(100, 1, "alice", 2, 10, at); !errors.Is(err, ErrLotteryFunds) {
+		t.Errorf("BuyTickets() over balance error = %v, want ErrLotteryFunds", err)
+	}
+	if _, _, err := lottery.BuyTickets(100, 2, "bob", 1, 10, at); !errors.Is(err, ErrLotteryFunds) {
+		t.Errorf("BuyTickets() without balance error = %v, want ErrLotteryFunds", err)
+	}
+	mine, total, _ := lottery.CountTickets(100, 1)
+	if mine != 5 || total != 5 {
+		t.Errorf("CountTickets() = %d, %d, want 5, 5", mine, total)
+	}
+}
+
+func TestDraw_ReplaysFromSeedAndTickets(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	lottery := NewLotteryRepo(db)
+	fundPlayer(stats, 1, "alice", 1)
+	fundPlayer(stats, 2, "bob", 2)
+	at := time.Unix(1_000_000, 0)
+	lottery.BuyTickets(100, 1, "alice", 2, 10, at)
+	lottery.BuyTickets(100, 2, "bob", 3, 10, at)
+
+	seed := uint64(1) << 63
+	draw, ok, err := lottery.Draw(100, seed, at)
+	if err != nil || !ok {
+		t.Fatalf("Draw() = %v, %v", ok, err)
+	}
+	if draw.Pot != 50 || draw.Tickets != 5 {
+		t.Errorf("pot, tickets = %d, %d, want 50, 5", draw.Pot, draw.Tickets)
+	}
+
+	tickets, err := lottery.GetDrawTickets(draw.Id)
+	if err != nil {
+		t.Fatalf("GetDrawTickets() error = %v", err)
+	}
+	if len(tickets) != 5 {
+		t.Fatalf("draw has %d tickets, want 5", len(tickets))
+	}
+	replayed := tickets[domain.LotteryWinner(draw.Seed, len(tickets))]
+	if replayed.Id != draw.TicketId || replayed.UserId != draw.WinnerId {
+		t.Errorf("replayed ticket %d of %d, draw picked %d of %d", replayed.Id, replayed.UserId, draw.TicketId, draw.WinnerId)
+	}
+
+	last, ok, _ := lottery.GetLastDraw(100)
+	if !ok || last.Seed != seed || last.TicketId != draw.TicketId || last.WinnerCount != draw.WinnerCount {
+		t.Errorf("GetLastDraw() = %+v, want %+v", last, draw)
+	}
+
+	if pot, _ := lottery.GetPot(100); pot != 0 {
+		t.Errorf("pot after draw = %d, want 0", pot)
+	}
+	if _, total, _ := lottery.CountTickets(100, 1); total != 0 {
+		t.Errorf("open tickets after draw = %d, want 0", total)
+	}
+}
+
+func TestDraw_NoTicketsKeepsPot(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	lottery := NewLotteryRepo(db)
+	settings := NewSettingsRepo(db)
+
+	at := time.Unix(1_000_000, 0)
+	_, ok, err := lottery.Draw(100, 42, at)
+	if err != nil || ok {
+		t.Errorf("Draw() without tickets = %v, %v, want false", ok, err)
+	}
+	if _, ok, _ := lottery.GetLastDraw(100); ok {
+		t.Error("empty draw was recorded")
+	}
+	if schedule, _ := settings.GetLotterySchedule(100); !schedule.CheckedAt.Equal(at) {
+		t.Errorf("CheckedAt = %v, want the draw time %v", schedule.CheckedAt, at)
+	}
+}
+
+func TestClaim_PaysOnce(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	lottery := NewLotteryRepo(db)
+	fundPlayer(stats, 1, "alice", 1)
+	at := time.Unix(1_000_000, 0)
+	lottery.BuyTickets(100, 1, "alice", 4, 10, at)
+	lottery.Draw(100, 7, at)
+
+	if prize, _ := lottery.GetUnclaimed(100, 1); prize != 40 {
+		t.Errorf("GetUnclaimed() = %d, want 40", prize)
+	}
+	prize, err := lottery.Claim(100, 1, at)
+	if err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	if prize != 40 {
+		t.Errorf("Claim() = %d, want 40", prize)
+	}
+	if again, _ := lottery.Claim(100, 1, at); again != 0 {
+		t.Errorf("second Claim() = %d, want 0", again)
+	}
+
+	alice, _ := stats.GetPersonalStats(100, 1, domain.CoinGame)
+	if alice.Balance != 64 {
+		t.Errorf("alice balance = %d, want 64", alice.Balance)
+	}
+}
+
+func TestDraw_UnclaimedPotRollsOver(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	lottery := NewLotteryRepo(db)
+	fundPlayer(stats, 1, "alice", 1)
+	at := time.Unix(1_000_000, 0)
+
+	lottery.BuyTickets(100, 1, "alice", 3, 10, at)
+	first, _, _ := lottery.Draw(100, 1, at)
+
+	// nobody bought tickets: the unclaimed prize goes back to the pot and waits
+	if _, ok, _ := lottery.Draw(100, 2, at.Add(24*time.Hour)); ok {
+		t.Fatal("Draw() without tickets is ok")
+	}
+	if pot, _ := lottery.GetPot(100); pot != first.Pot {
+		t.Errorf("pot after rollover = %d, want %d", pot, first.Pot)
+	}
+	if prize, _ := lottery.Claim(100, 1, at); prize != 0 {
+		t.Errorf("Claim() of a rolled prize = %d, want 0", prize)
+	}
+
+	lottery.BuyTickets(100, 1, "alice", 1, 10, at)
+	second, ok, _ := lottery.Draw(100, 3, at.Add(48*time.Hour))
+	if !ok || second.Pot != 40 {
+		t.Errorf("second draw pot = %d, want 40 (30 rolled over + 10)", second.Pot)
+	}
+}
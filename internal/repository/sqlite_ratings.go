@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"database/sql"
+	"errors"
+)
+
+// ratingOrders are the ORDER BY keys of the ratings over the players CTE.
+var ratingOrders = map[domain.Rating]string{
+	domain.RatingRich:    "balance DESC",
+	domain.RatingDebtors: "balance ASC",
+	domain.RatingLucky:   "CASE WHEN spins > 0 THEN CAST(wins AS REAL) / spins ELSE 0 END DESC",
+	domain.RatingStreaks: "max_streak DESC",
+}
+
+// playersCTE defines `players` for a leaderboard: the lifetime counters, or,
+// with Since set, the spin ledger folded per player. Ledger streaks are the
+// longest runs of wins and losses within the period.
+func playersCTE(q domain.RatingQuery) (string, []any) {
+	if q.Since.IsZero() {
+		return `
+		WITH players AS (
+			SELECT user_id, username, spins, wins, balance, max_streak, max_loss_streak
+			FROM user_stats
+			WHERE chat_id = ? AND game = ?
+		)`, []any{q.ChatId, q.Game}
+	}
+	return `
+		WITH period AS (
+			SELECT id, user_id, username, payout, cost,
+			       ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY id)
+			     - ROW_NUMBER() OVER (PARTITION BY user_id, payout > 0 ORDER BY id) AS run
+			FROM spins
+			WHERE chat_id = ? AND game = ? AND created_at >= ?
+		),
+		runs AS (
+			SELECT user_id, payout > 0 AS win, COUNT(*) AS length
+			FROM period
+			GROUP BY user_id, payout > 0, run
+		),
+		players AS (
+			SELECT user_id,
+			       (SELECT username FROM period last WHERE last.user_id = p.user_id ORDER BY id DESC LIMIT 1) AS username,
+			       COUNT(*) AS spins,
+			       SUM(payout > 0) AS wins,
+			       SUM(payout - cost) AS balance,
+			       COALESCE((SELECT MAX(length) FROM runs WHERE runs.user_id = p.user_id AND win), 0) AS max_streak,
+			       COALESCE((SELECT MAX(length) FROM runs WHERE runs.user_id = p.user_id AND NOT win), 0) AS max_loss_streak
+			FROM period p
+			GROUP BY user_id
+		)`, []any{q.ChatId, q.Game, q.Since.Unix()}
+}
+
+// rankedCTE adds `ranked` on top of players: the rank shared by ties and the
+// zero-based position, which breaks ties by user id so pages are stable.
+func rankedCTE(q domain.RatingQuery) (string, []any) {
+	order, ok := ratingOrders[q.Rating]
+	if !ok {
+		order = ratingOrders[domain.RatingRich]
+	}
+	with, args := playersCTE(q)
+	return with + `,
+		ranked AS (
+			SELECT user_id, username, spins, wins, balance, max_streak, max_loss_streak,
+			       CASE WHEN spins > 0 THEN CAST(wins AS REAL) / spins * 100 ELSE 0 END AS luck,
+			       DENSE_RANK() OVER (ORDER BY ` + order + `) AS rank,
+			       ROW_NUMBER() OVER (ORDER BY ` + order + `, user_id) - 1 AS position
+			FROM players
+		)`, args
+}
+
+const rankedColumns = `user_id, username, spins, wins, balance, max_streak, max_loss_streak, luck, rank, position`
+
+func scanRanked(row interface{ Scan(...any) error }) (domain.RatingStats, int, error) {
+	var s domain.RatingStats
+	var position int
+	err := row.Scan(&s.UserId, &s.Username, &s.Spins, &s.Wins, &s.Balance, &s.MaxStreak, &s.MaxLossStreak,
+		&s.Luck, &s.Rank, &position)
+	return s, position, err
+}
+
+// GetRatingPage returns `limit` players of the leaderboard starting at
+// `offset`; a negative limit returns everybody.
+func (r *UserStatsRepo) GetRatingPage(q domain.RatingQuery, limit, offset int) ([]domain.RatingStats, error) {
+	with, args := rankedCTE(q)
+	rows, err := r.db.Query(with+`
+		SELECT `+rankedColumns+` FROM ranked
+		ORDER BY position
+		LIMIT ? OFFSET ?`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.RatingStats
+	for rows.Next() {
+		s, _, err := scanRanked(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CountRating returns how many players the leaderboard has.
+func (r *UserStatsRepo) CountRating(q domain.RatingQuery) (int, error) {
+	with, args := playersCTE(q)
+	var count int
+	err := r.db.QueryRow(with+` SELECT COUNT(*) FROM players`, args...).Scan(&count)
+	return count, err
+}
+
+// GetRatingEntry returns the player's line of the leaderboard and its
+// zero-based position; ok is false when the player is not on it.
+func (r *UserStatsRepo) GetRatingEntry(q domain.RatingQuery, userId int64) (domain.RatingStats, int, bool, error) {
+	with, args := rankedCTE(q)
+	s, position, err := scanRanked(r.db.QueryRow(with+`
+		SELECT `+rankedColumns+` FROM ranked WHERE user_id = ?`, append(args, userId)...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.RatingStats{}, 0, false, nil
+		}
+		return domain.RatingStats{}, 0, false, err
+	}
+	return s, position, true, nil
+}
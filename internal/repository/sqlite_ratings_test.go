@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestGetRatingPage_Paginates(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserStatsRepo(db)
+
+	// Five players with balances 64, 64, -1, -1, -1: ties are broken by user id.
+	spin(t, repo, 100, 5, "eve", 64)
+	spin(t, repo, 100, 1, "alice", 64)
+	spin(t, repo, 100, 4, "dave", 0)
+	spin(t, repo, 100, 2, "bob", 0)
+	spin(t, repo, 100, 3, "carol", 0)
+	spin(t, repo, 200, 6, "mallory", 64)
+
+	q := domain.RatingQuery{ChatId: 100, Game: domain.GameSlot, Rating: domain.RatingRich}
+	count, err := repo.CountRating(q)
+	if err != nil {
+		t.Fatalf("CountRating() error = %v", err)
+	}
+	if count != 5 {
+		t.Errorf("count = %d, want 5", count)
+	}
+
+	first, err := repo.GetRatingPage(q, 2, 0)
+	if err != nil {
+		t.Fatalf("GetRatingPage() error = %v", err)
+	}
+	if len(first) != 2 || first[0].Username != "alice" || first[1].Username != "eve" {
+		t.Fatalf("first page = %+v, want alice, eve", first)
+	}
+	if first[0].Rank != 1 || first[1].Rank != 1 {
+		t.Errorf("tied players should share rank 1, got %d and %d", first[0].Rank, first[1].Rank)
+	}
+
+	last, _ := repo.GetRatingPage(q, 2, 4)
+	if len(last) != 1 || last[0].Username != "dave" || last[0].Rank != 2 {
+		t.Errorf("last page = %+v, want dave ranked 2", last)
+	}
+}
+
+func TestGetRatingEntry(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserStatsRepo(db)
+
+	spin(t, repo, 100, 1, "alice", 64)
+	spin(t, repo, 100, 2, "bob", 0)
+	spin(t, repo, 100, 3, "carol", 32)
+
+	q := domain.RatingQuery{ChatId: 100, Game: domain.GameSlot, Rating: domain.RatingRich}
+	entry, position, ok, err := repo.GetRatingEntry(q, 2)
+	if err != nil {
+		t.Fatalf("GetRatingEntry() error = %v", err)
+	}
+	if !ok || position != 2 || entry.Username != "bob" || entry.Rank != 3 || entry.UserId != 2 {
+		t.Errorf("entry = %+v at %d (ok %v), want bob ranked 3 at position 2", entry, position, ok)
+	}
+
+	if _, _, ok, _ := repo.GetRatingEntry(q, 42); ok {
+		t.Error("player without spins should not be on the leaderboard")
+	}
+}
+
+func TestGetRatingPage_Period(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserStatsRepo(db)
+
+	now := time.Unix(1_700_000_000, 0)
+	old := now.Add(-48 * time.Hour)
+	record := func(id int64, userId int64, username string, payout int64, at time.Time) {
+		t.Helper()
+		if _, err := repo.Spin(domain.Spin{ChatId: 100, UserId: userId, Game: domain.GameSlot, Username: username,
+			MessageId: id, Payout: payout, Cost: 1, At: at}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// alice got rich long ago, bob is on fire today.
+	record(1, 1, "alice", 500, old)
+	record(2, 1, "alice", 0, now)
+	record(3, 2, "bob", 10, now)
+	record(4, 2, "bob", 10, now)
+	record(5, 2, "bob", 0, now)
+	record(6, 2, "bobby", 10, now)
+
+	stats, err := repo.GetRatingPage(domain.RatingQuery{ChatId: 100, Game: domain.GameSlot, Rating: domain.RatingRich, Since: now.Add(-time.Hour)}, -1, 0)
+	if err != nil {
+		t.Fatalf("GetRatingPage() error = %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("got %d players, want 2", len(stats))
+	}
+	bob := stats[0]
+	if bob.Username != "bobby" || bob.Rank != 1 || bob.Spins != 4 || bob.Wins != 3 || bob.Balance != 26 {
+		t.Errorf("first = %+v, want bobby with 4 spins, 3 wins, balance 26", bob)
+	}
+	if bob.MaxStreak != 2 || bob.MaxLossStreak != 1 {
+		t.Errorf("bob streaks = %d/%d, want 2/1", bob.MaxStreak, bob.MaxLossStreak)
+	}
+	if stats[1].Username != "alice" || stats[1].Balance != -1 {
+		t.Errorf("second = %+v, want alice with -1 today", stats[1])
+	}
+
+	all, _ := repo.GetRatingPage(domain.RatingQuery{ChatId: 100, Game: domain.GameSlot, Rating: domain.RatingRich, Since: time.Unix(1, 0)}, -1, 0)
+	if all[0].Username != "alice" || all[0].Balance != 498 {
+		t.Errorf("all-time first = %+v, want alice with 498", all[0])
+	}
+
+	debtors, _ := repo.GetRatingPage(domain.RatingQuery{ChatId: 100, Game: domain.GameSlot, Rating: domain.RatingDebtors, Since: now.Add(-time.Hour)}, -1, 0)
+	if debtors[0].Username != "alice" {
+		t.Errorf("first debtor = %q, want alice", debtors[0].Username)
+	}
+}
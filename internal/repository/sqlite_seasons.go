@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrEmptySeason is returned when there is nothing to archive: nobody has
+// played since the last season was closed.
+var ErrEmptySeason = errors.New("season has no players")
+
+type SeasonRepo struct {
+	db *sql.DB
+}
+
+func NewSeasonRepo(db *sql.DB) *SeasonRepo {
+	return &SeasonRepo{db: db}
+}
+
+// GetCurrentSeason returns the season in progress. Its start is the end of the
+// previous season, or the first recorded spin of the chat.
+func (r *SeasonRepo) GetCurrentSeason(chatId int64, now time.Time) (domain.Season, error) {
+	var number, startedAt int64
+	err := r.db.QueryRow(`
+		SELECT COALESCE(MAX(number), 0) + 1,
+		       COALESCE(MAX(ended_at), (SELECT MIN(created_at) FROM spins WHERE chat_id = ?), ?)
+		FROM seasons WHERE chat_id = ?`,
+		chatId, now.Unix(), chatId).Scan(&number, &startedAt)
+	if err != nil {
+		return domain.Season{}, err
+	}
+	return domain.Season{Number: number, StartedAt: time.Unix(startedAt, 0)}, nil
+}
+
+// CloseSeason archives the current standings of every game under the next
+// season number and starts everybody from zero. The spin ledger and the
+// jackpot pool carry over.
+func (r *SeasonRepo) CloseSeason(chatId int64, at time.Time) (domain.Season, error) {
+	current, err := r.GetCurrentSeason(chatId, at)
+	if err != nil {
+		return domain.Season{}, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return domain.Season{}, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		INSERT INTO season_standings (chat_id, season, game, user_id, username, spins, wins, balance,
+		                              max_streak, max_loss_streak, rank)
+		SELECT chat_id, ?, game, user_id, username, spins, wins, balance, max_streak, max_loss_streak,
+		       DENSE_RANK() OVER (PARTITION BY game ORDER BY balance DESC)
+		FROM user_stats
+		WHERE chat_id = ?`,
+		current.Number, chatId)
+	if err != nil {
+		return domain.Season{}, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return domain.Season{}, err
+	} else if n == 0 {
+		return domain.Season{}, ErrEmptySeason
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO seasons (chat_id, number, started_at, ended_at) VALUES (?, ?, ?, ?)`,
+		chatId, current.Number, current.StartedAt.Unix(), at.Unix()); err != nil {
+		return domain.Season{}, err
+	}
+	if _, err := tx.Exec(`DELETE FROM user_stats WHERE chat_id = ?`, chatId); err != nil {
+		return domain.Season{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.Season{}, err
+	}
+
+	current.EndedAt = at
+	return current, nil
+}
+
+// GetSeasons lists `limit` closed seasons starting at `offset`, latest first,
+// with the champion of the game; a negative limit returns them all.
+func (r *SeasonRepo) GetSeasons(chatId int64, game domain.Game, limit, offset int) ([]domain.Season, error) {
+	rows, err := r.db.Query(`
+		SELECT s.number, s.started_at, s.ended_at,
+		       COALESCE(c.username, ''), COALESCE(c.balance, 0)
+		FROM seasons s
+		LEFT JOIN season_standings c ON c.rowid = (
+			SELECT rowid FROM season_standings
+			WHERE chat_id = s.chat_id AND season = s.number AND game = ? AND rank = 1
+			ORDER BY spins DESC
+			LIMIT 1
+		)
+		WHERE s.chat_id = ?
+		ORDER BY s.number DESC
+		LIMIT ? OFFSET ?`, game, chatId, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.Season
+	for rows.Next() {
+		var s domain.Season
+		var startedAt, endedAt int64
+		if err := rows.Scan(&s.Number, &startedAt, &endedAt, &s.Champion, &s.ChampionBalance); err != nil {
+			return nil, err
+		}
+		s.StartedAt = time.Unix(startedAt, 0)
+		s.EndedAt = time.Unix(endedAt, 0)
+		res = append(res, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CountSeasons returns how many seasons of the chat are closed.
+func (r *SeasonRepo) CountSeasons(chatId int64) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM seasons WHERE chat_id = ?`, chatId).Scan(&count)
+	return count, err
+}
+
+// GetSeasonStandings returns `limit` lines of the final standings of a closed
+// season by balance, starting at `offset`; a negative limit returns them all.
+func (r *SeasonRepo) GetSeasonStandings(chatId int64, season int64, game domain.Game, limit, offset int) ([]domain.RatingStats, error) {
+	rows, err := r.db.Query(`
+		SELECT username, spins, wins, balance, max_streak, max_loss_streak, rank
+		FROM season_standings
+		WHERE chat_id = ? AND season = ? AND game = ?
+		ORDER BY rank, spins DESC, user_id
+		LIMIT ? OFFSET ?`, chatId, season, game, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.RatingStats
+	for rows.Next() {
+		var s domain.RatingStats
+		if err := rows.Scan(&s.Username, &s.Spins, &s.Wins, &s.Balance, &s.MaxStreak, &s.MaxLossStreak, &s.Rank); err != nil {
+			return nil, err
+		}
+		res = append(res, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CountSeasonStandings returns how many players the season's standings of the game have.
+func (r *SeasonRepo) CountSeasonStandings(chatId int64, season int64, game domain.Game) (int, error) {
+	var count int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM season_standings WHERE chat_id = ? AND season = ? AND game = ?`,
+		chatId, season, game).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCloseSeason_ArchivesAndResets(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	seasons := NewSeasonRepo(db)
+
+	spin(t, stats, 100, 1, "alice", 64)
+	spin(t, stats, 100, 2, "bob", 0)
+	spin(t, stats, 200, 3, "carol", 64)
+
+	end := time.Now()
+	season, err := seasons.CloseSeason(100, end)
+	if err != nil {
+		t.Fatalf("CloseSeason() error = %v", err)
+	}
+	if season.Number != 1 {
+		t.Errorf("season number = %d, want 1", season.Number)
+	}
+
+	current, _ := firstPage(stats, 100, domain.GameSlot, domain.RatingRich)
+	if len(current) != 0 {
+		t.Errorf("expected 0 users after closing the season, got %d", len(current))
+	}
+	other, _ := firstPage(stats, 200, domain.GameSlot, domain.RatingRich)
+	if len(other) != 1 {
+		t.Errorf("other chat should keep its standings, got %d users", len(other))
+	}
+
+	standings, err := seasons.GetSeasonStandings(100, 1, domain.GameSlot, -1, 0)
+	if err != nil {
+		t.Fatalf("GetSeasonStandings() error = %v", err)
+	}
+	if len(standings) != 2 {
+		t.Fatalf("archived %d users, want 2", len(standings))
+	}
+	if standings[0].Username != "alice" || standings[0].Balance != 64 || standings[0].Rank != 1 {
+		t.Errorf("first place = %+v, want alice with 64", standings[0])
+	}
+	if standings[1].Username != "bob" || standings[1].Rank != 2 {
+		t.Errorf("second place = %+v, want bob", standings[1])
+	}
+}
+
+func TestCloseSeason_Numbering(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	seasons := NewSeasonRepo(db)
+
+	first := time.Unix(1_700_000_000, 0)
+	spin(t, stats, 100, 1, "alice", 64)
+	seasons.CloseSeason(100, first)
+
+	spin(t, stats, 100, 2, "bob", 64)
+	second, err := seasons.CloseSeason(100, first.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("CloseSeason() error = %v", err)
+	}
+	if second.Number != 2 {
+		t.Errorf("second season number = %d, want 2", second.Number)
+	}
+	if !second.StartedAt.Equal(first) {
+		t.Errorf("second season started at %v, want the end of the first %v", second.StartedAt, first)
+	}
+
+	list, err := seasons.GetSeasons(100, domain.GameSlot, -1, 0)
+	if err != nil {
+		t.Fatalf("GetSeasons() error = %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("got %d seasons, want 2", len(list))
+	}
+	if list[0].Number != 2 || list[0].Champion != "bob" {
+		t.Errorf("latest season = %+v, want 2 won by bob", list[0])
+	}
+	if list[1].Number != 1 || list[1].Champion != "alice" || list[1].ChampionBalance != 64 {
+		t.Errorf("first season = %+v, want 1 won by alice with 64", list[1])
+	}
+}
+
+func TestCloseSeason_Empty(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	seasons := NewSeasonRepo(db)
+
+	if _, err := seasons.CloseSeason(100, time.Now()); !errors.Is(err, ErrEmptySeason) {
+		t.Errorf("CloseSeason() error = %v, want ErrEmptySeason", err)
+	}
+	list, _ := seasons.GetSeasons(100, domain.GameSlot, -1, 0)
+	if len(list) != 0 {
+		t.Errorf("empty season should not be archived, got %d", len(list))
+	}
+}
+
+func TestGetSeasons_ChampionPerGame(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	seasons := NewSeasonRepo(db)
+
+	spin(t, stats, 100, 1, "alice", 64)
+	stats.Spin(domain.Spin{ChatId: 100, UserId: 2, Game: domain.GameDice, Username: "bob", MessageId: 9001, Payout: 5, Cost: 1})
+	seasons.CloseSeason(100, time.Now())
+
+	dice, _ := seasons.GetSeasons(100, domain.GameDice, -1, 0)
+	if len(dice) != 1 || dice[0].Champion != "bob" {
+		t.Errorf("dice seasons = %+v, want bob as champion", dice)
+	}
+	bowling, _ := seasons.GetSeasons(100, domain.GameBowling, -1, 0)
+	if len(bowling) != 1 || bowling[0].Champion != "" {
+		t.Errorf("bowling seasons = %+v, want a season without champion", bowling)
+	}
+}
+
+func TestSeasons_Pages(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	seasons := NewSeasonRepo(db)
+
+	start := time.Unix(1_700_000_000, 0)
+	for i := int64(1); i <= 3; i++ {
+		spin(t, stats, 100, i, "player", 64)
+		spin(t, stats, 100, 10+i, "other", 0)
+		seasons.CloseSeason(100, start.Add(time.Duration(i)*time.Hour))
+	}
+
+	if total, _ := seasons.CountSeasons(100); total != 3 {
+		t.Errorf("CountSeasons() = %d, want 3", total)
+	}
+	page, err := seasons.GetSeasons(100, domain.GameSlot, 2, 2)
+	if err != nil {
+		t.Fatalf("GetSeasons() error = %v", err)
+	}
+	if len(page) != 1 || page[0].Number != 1 {
+		t.Errorf("last page = %+v, want season 1", page)
+	}
+
+	if total, _ := seasons.CountSeasonStandings(100, 2, domain.GameSlot); total != 2 {
+		t.Errorf("CountSeasonStandings() = %d, want 2", total)
+	}
+	standings, _ := seasons.GetSeasonStandings(100, 2, domain.GameSlot, 1, 1)
+	if len(standings) != 1 || standings[0].Username != "other" || standings[0].Rank != 2 {
+		t.Errorf("second line = %+v, want other ranked 2", standings)
+	}
+}
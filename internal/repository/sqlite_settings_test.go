@@ -1,38 +1,66 @@
 package repository
 
 import (
+	"bandit-counter-bot/internal/domain"
 	"database/sql"
 	"testing"
-	"testing/fstest"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// settingsSchema holds the chat settings tables.
+const settingsSchema = `
+	CREATE TABLE IF NOT EXISTS chat_settings(
+		chat_id INTEGER PRIMARY KEY,
+		prize_values TEXT NOT NULL DEFAULT '[64]',
+		win_amount INTEGER NOT NULL DEFAULT 64,
+		allow_user_settings INTEGER NOT NULL DEFAULT 0,
+		allow_user_reset INTEGER NOT NULL DEFAULT 0,
+		payout_table TEXT NOT NULL DEFAULT '{}',
+		spin_cost INTEGER NOT NULL DEFAULT 1,
+		spin_cost_set INTEGER NOT NULL DEFAULT 0,
+		jackpot_share INTEGER NOT NULL DEFAULT 0,
+		enabled_games TEXT NOT NULL DEFAULT '["slot"]',
+		spin_cooldown INTEGER NOT NULL DEFAULT 0,
+		spin_burst INTEGER NOT NULL DEFAULT 0,
+		throttle_cleanup INTEGER NOT NULL DEFAULT 0,
+		daily_spin_limit INTEGER NOT NULL DEFAULT 0,
+		timezone TEXT NOT NULL DEFAULT 'Europe/Kyiv',
+		ignore_sender_chats INTEGER NOT NULL DEFAULT 0,
+		prize_mode TEXT NOT NULL DEFAULT 'classic',
+		season_schedule TEXT NOT NULL DEFAULT 'off',
+		season_end_date TEXT NOT NULL DEFAULT '',
+		season_checked_at INTEGER NOT NULL DEFAULT 0,
+		level_curve TEXT NOT NULL DEFAULT 'normal',
+		daily_bonus INTEGER NOT NULL DEFAULT 10,
+		bailout_threshold INTEGER NOT NULL DEFAULT -1000,
+		bailout_cooldown INTEGER NOT NULL DEFAULT 604800,
+		transfer_floor INTEGER NOT NULL DEFAULT 0,
+		transfer_daily_cap INTEGER NOT NULL DEFAULT 500,
+		bet_min INTEGER NOT NULL DEFAULT 1,
+		bet_max INTEGER NOT NULL DEFAULT 0,
+		lottery_schedule TEXT NOT NULL DEFAULT 'off',
+		lottery_hour INTEGER NOT NULL DEFAULT 20,
+		lottery_checked_at INTEGER NOT NULL DEFAULT 0,
+		ticket_price INTEGER NOT NULL DEFAULT 10,
+		loan_max INTEGER NOT NULL DEFAULT 0,
+		loan_rate INTEGER NOT NULL DEFAULT 5,
+		loan_repay_share INTEGER NOT NULL DEFAULT 50
+	);
+
+	CREATE TABLE IF NOT EXISTS prize_modes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chat_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		prize_values TEXT NOT NULL,
+		UNIQUE (chat_id, name)
+	);
+`
+
 func setupSettingsDB(t *testing.T) *sql.DB {
 	t.Helper()
-	db, err := sql.Open("sqlite3", ":memory:")
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	migrations := fstest.MapFS{
-		"001_init.sql": &fstest.MapFile{
-			Data: []byte(`
-				CREATE TABLE IF NOT EXISTS chat_settings(
-					chat_id INTEGER PRIMARY KEY,
-					prize_values TEXT NOT NULL DEFAULT '[64]',
-					win_amount INTEGER NOT NULL DEFAULT 64,
-					allow_user_settings INTEGER NOT NULL DEFAULT 0,
-					allow_user_reset INTEGER NOT NULL DEFAULT 0
-				);
-			`),
-		},
-	}
-
-	if err := Migrate(db, migrations); err != nil {
-		t.Fatal(err)
-	}
-	return db
+	return openTestDB(t, settingsSchema)
 }
 
 func TestGetPrizeValues_Default(t *testing.T) {
@@ -91,6 +119,62 @@ func TestUpdatePrizeValues_Overwrite(t *testing.T) {
 	}
 }
 
+func TestUpdatePrizeMode(t *testing.T) {
+	db := setupSettingsDB(t)
+	defer db.Close()
+	repo := NewSettingsRepo(db)
+
+	mode, err := repo.GetPrizeMode(100)
+	if err != nil {
+		t.Fatalf("GetPrizeMode() error = %v", err)
+	}
+	if mode != DefaultPrizeMode {
+		t.Errorf("default mode = %q, want %q", mode, DefaultPrizeMode)
+	}
+
+	if err := repo.UpdatePrizeMode("lemons", []int{43}, 100); err != nil {
+		t.Fatalf("UpdatePrizeMode() error = %v", err)
+	}
+	mode, _ = repo.GetPrizeMode(100)
+	values, _ := repo.GetPrizeValues(100)
+	if mode != "lemons" || len(values) != 1 || values[0] != 43 {
+		t.Errorf("mode, values = %q, %v, want lemons, [43]", mode, values)
+	}
+}
+
+func TestSaveCustomPrizeMode(t *testing.T) {
+	db := setupSettingsDB(t)
+	defer db.Close()
+	repo := NewSettingsRepo(db)
+
+	first, err := repo.SaveCustomPrizeMode(100, "сімки", []int{48, 64})
+	if err != nil {
+		t.Fatalf("SaveCustomPrizeMode() error = %v", err)
+	}
+	repo.SaveCustomPrizeMode(100, "бари", []int{1})
+	repo.SaveCustomPrizeMode(200, "чужий", []int{22})
+
+	// Saving under the same name replaces the values and keeps the key.
+	again, _ := repo.SaveCustomPrizeMode(100, "сімки", []int{64})
+	if again.Key != first.Key {
+		t.Errorf("key after overwrite = %q, want %q", again.Key, first.Key)
+	}
+
+	modes, err := repo.GetCustomPrizeModes(100)
+	if err != nil {
+		t.Fatalf("GetCustomPrizeModes() error = %v", err)
+	}
+	if len(modes) != 2 {
+		t.Fatalf("got %d modes, want 2", len(modes))
+	}
+	if modes[0].Key != first.Key || modes[0].Name != "сімки" || len(modes[0].Values) != 1 || modes[0].Values[0] != 64 {
+		t.Errorf("modes[0] = %+v", modes[0])
+	}
+	if modes[1].Name != "бари" {
+		t.Errorf("modes[1].Name = %q, want бари", modes[1].Name)
+	}
+}
+
 func TestGetPrizeValues_InvalidJSON(t *testing.T) {
 	db := setupSettingsDB(t)
 	defer db.Close()
@@ -145,6 +229,313 @@ func TestPrizeValues_ChatIsolation(t *testing.T) {
 	}
 }
 
+func TestGetSpinCost_Default(t *testing.T) {
+	db := setupSettingsDB(t)
+	defer db.Close()
+	repo := NewSettingsRepo(db)
+
+	cost, set, err := repo.GetSpinCost(100)
+	if err != nil {
+		t.Fatalf("GetSpinCost() error = %v", err)
+	}
+	if cost != 1 || set {
+		t.Errorf("default cost = %d, %v, want 1, not set", cost, set)
+	}
+
+	// another setting creates the row, the cost is still the default
+	repo.UpdateJackpotShare(25, 100)
+	if cost, set, _ := repo.GetSpinCost(100); cost != 1 || set {
+		t.Errorf("cost after other setting = %d, %v, want 1, not set", cost, set)
+	}
+}
+
+func TestUpdateAndGetSpinCost(t *testing.T) {
+	db := setupSettingsDB(t)
+	defer db.Close()
+	repo := NewSettingsRepo(db)
+
+	if err := repo.UpdateSpinCost(5, 100); err != nil {
+		t.Fatalf("UpdateSpinCost() error = %v", err)
+	}
+
+	cost, set, err := repo.GetSpinCost(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost != 5 || !set {
+		t.Errorf("cost = %d, %v, want 5, set", cost, set)
+	}
+
+	other, otherSet, _ := repo.GetSpinCost(200)
+	if other != 1 || otherSet {
+		t.Errorf("chat 200 cost = %d, %v, want 1, not set", other, otherSet)
+	}
+}
+
+func TestUpdateAndGetJackpotShare(t *testing.T) {
+	db := setupSettingsDB(t)
+	defer db.Close()
+	repo := NewSettingsRepo(db)
+
+	share, err := repo.GetJackpotShare(100)
+	if err != nil {
+		t.Fatalf("GetJackpotShare() error = %v", err)
+	}
+	if share != 0 {
+		t.Errorf("default share = %d, want 0", share)
+	}
+
+	if err := repo.UpdateJackpotShare(25, 100); err != nil {
+		t.Fatalf("UpdateJackpotShare() error = %v", err)
+	}
+	share, _ = repo.GetJackpotShare(100)
+	if share != 25 {
+		t.Errorf("share = %d, want 25", share)
+	}
+}
+
+func TestGetEnabledGames_Default(t *testing.T) {
+	db := setupSettingsDB(t)
+	defer db.Close()
+	repo := NewSettingsRepo(db)
+
+	games, err := repo.GetEnabledGames(100)
+	if err != nil {
+		t.Fatalf("GetEnabledGames() error = %v", err)
+	}
+	if len(games) != 1 || games[0] != domain.GameSlot {
+		t.Errorf("default games = %v, want [slot]", games)
+	}
+}
+
+func TestUpdateAndGetEnabledGames(t *testing.T) {
+	db := setupSettingsDB(t)
+	defer db.Close()
+	repo := NewSettingsRepo(db)
+
+	if err := repo.UpdateEnabledGames([]domain.Game{domain.GameSlot, domain.GameDarts}, 100); err != nil {
+		t.Fatalf("UpdateEnabledGames() error = %v", err)
+	}
+	games, _ := repo.GetEnabledGames(100)
+	if len(games) != 2 || games[1] != domain.GameDarts {
+		t.Errorf("games = %v, want [slot darts]", games)
+	}
+
+	// Disabling everything is a valid choice, not a reason to fall back.
+	repo.UpdateEnabledGames([]domain.Game{}, 100)
+	games, _ = repo.GetEnabledGames(100)
+	if len(games) != 0 {
+		t.Errorf("games = %v, want []", games)
+	}
+}
+
+func TestGetThrottle_Default(t *testing.T) {
+	db := setupSettingsDB(t)
+	defer db.Close()
+	repo := NewSettingsRepo(db)
+
+	throttle, err := repo.GetThrottle(100)
+	if err != nil {
+		t.Fatalf("GetThrottle() error = %v", err)
+	}
+	if throttle.Enabled() || throttle.Cleanup {
+		t.Errorf("default throttle = %+v, want disabled", throttle)
+	}
+}
+
+func TestUpdateAndGetThrottle(t *testing.T) {
+	db := setupSettingsDB(t)
+	defer db.Close()
+	repo := NewSettingsRepo(db)
+
+	repo.UpdateSpinCooldown(5, 100)
+	repo.UpdateSpinBurst(10, 100)
+	if err := repo.ToggleThrottleCleanup(100); err != nil {
+		t.Fatalf("ToggleThrottleCleanup() error = %v", err)
+	}
+
+	throttle, err := repo.GetThrottle(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if throttle.Cooldown != 5*time.Second || throttle.Burst != 10 || !throttle.Cleanup {
+		t.Errorf("throttle = %+v, want 5s cooldown, burst 10, cleanup on", throttle)
+	}
+
+	repo.ToggleThrottleCleanup(100)
+	throttle, _ = repo.GetThrottle(100)
+	if throttle.Cleanup {
+		t.Error("cleanup should be off after second toggle")
+	}
+}
+
+func TestUpdateAndGetDailySpinLimit(t *testing.T) {
+	db := setupSettingsDB(t)
+	defer db.Close()
+	repo := NewSettingsRepo(db)
+
+	limit, err := repo.GetDailySpinLimit(100)
+	if err != nil {
+		t.Fatalf("GetDailySpinLimit() error = %v", err)
+	}
+	if limit != 0 {
+		t.Errorf("default limit = %d, want 0 (unlimited)", limit)
+	}
+
+	repo.UpdateDailySpinLimit(50, 100)
+	limit, _ = repo.GetDailySpinLimit(100)
+	if limit != 50 {
+		t.Errorf("limit = %d, want 50", limit)
+	}
+}
+
+func TestUpdateAndGetTimezone(t *testing.T) {
+	db := setupSettingsDB(t)
+	defer db.Close()
+	repo := NewSettingsRepo(db)
+
+	tz, err := repo.GetTimezone(100)
+	if err != nil {
+		t.Fatalf("GetTimezone() error = %v", err)
+	}
+	if tz != DefaultTimezone {
+		t.Errorf("default timezone = %q, want %q", tz, DefaultTimezone)
+	}
+
+	repo.UpdateTimezone("America/New_York", 100)
+	tz, _ = repo.GetTimezone(100)
+	if tz != "America/New_York" {
+		t.Errorf("timezone = %q, want America/New_York", tz)
+	}
+}
+
+func TestToggleIgnoreSenderChats(t *testing.T) {
+	db := setupSettingsDB(t)
+	defer db.Close()
+	repo := NewSettingsRepo(db)
+
+	ignore, err := repo.GetIgnoreSenderChats(100)
+	if err != nil {
+		t.Fatalf("GetIgnoreSenderChats() error = %v", err)
+	}
+	if ignore {
+		t.Error("sender chats should be tracked by default")
+	}
+
+	repo.ToggleIgnoreSenderChats(100)
+	ignore, _ = repo.GetIgnoreSenderChats(100)
+	if !ignore {
+		t.Error("sender chats should be ignored after toggle")
+	}
+
+	repo.ToggleIgnoreSenderChats(100)
+	ignore, _ = repo.GetIgnoreSenderChats(100)
+	if ignore {
+		t.Error("sender chats should be tracked after second toggle")
+	}
+}
+
+func TestSeasonSchedule(t *testing.T) {
+	db := setupSettingsDB(t)
+	defer db.Close()
+	repo := NewSettingsRepo(db)
+
+	schedule, err := repo.GetSeasonSchedule(100)
+	if err != nil {
+		t.Fatalf("GetSeasonSchedule() error = %v", err)
+	}
+	if schedule.Mode != domain.SeasonsManual {
+		t.Errorf("default mode = %q, want %q", schedule.Mode, domain.SeasonsManual)
+	}
+
+	since := time.Unix(1_700_000_000, 0)
+	repo.UpdateSeasonSchedule(domain.SeasonsOnDate, "2024-05-31", since, 100)
+	repo.UpdateSeasonSchedule(domain.SeasonsWeekly, "", since, 200)
+	repo.UpdateSpinCost(2, 300)
+
+	schedule, _ = repo.GetSeasonSchedule(100)
+	if schedule.Mode != domain.SeasonsOnDate || schedule.EndDate != "2024-05-31" || !schedule.CheckedAt.Equal(since) {
+		t.Errorf("schedule = %+v", schedule)
+	}
+
+	repo.MarkSeasonChecked(since.Add(time.Hour), 100)
+	schedule, _ = repo.GetSeasonSchedule(100)
+	if !schedule.CheckedAt.Equal(since.Add(time.Hour)) {
+		t.Errorf("CheckedAt = %v, want %v", schedule.CheckedAt, since.Add(time.Hour))
+	}
+
+	chats, err := repo.GetScheduledSeasonChats()
+	if err != nil {
+		t.Fatalf("GetScheduledSeasonChats() error = %v", err)
+	}
+	if len(chats) != 2 || chats[0] != 100 || chats[1] != 200 {
+		t.Errorf("scheduled chats = %v, want [100 200]", chats)
+	}
+}
+
+func TestGetPayoutTable_Default(t *testing.T) {
+	db := setupSettingsDB(t)
+	defer db.Close()
+	repo := NewSettingsRepo(db)
+
+	table, err := repo.GetPayoutTable(100)
+	if err != nil {
+		t.Fatalf("GetPayoutTable() error = %v", err)
+	}
+	if len(table) != 0 {
+		t.Errorf("default table = %v, want empty", table)
+	}
+}
+
+func TestUpdateAndGetPayoutTable(t *testing.T) {
+	db := setupSettingsDB(t)
+	defer db.Close()
+	repo := NewSettingsRepo(db)
+
+	err := repo.UpdatePayoutTable(domain.PayoutTable{domain.ComboSevens: 500, domain.ComboTwoSevens: 5}, 100)
+	if err != nil {
+		t.Fatalf("UpdatePayoutTable() error = %v", err)
+	}
+
+	table, err := repo.GetPayoutTable(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table) != 2 || table[domain.ComboSevens] != 500 || table[domain.ComboTwoSevens] != 5 {
+		t.Errorf("table = %v, want map[777:500 two_sevens:5]", table)
+	}
+}
+
+func TestUpdatePayoutTable_KeepsOtherSettings(t *testing.T) {
+	db := setupSettingsDB(t)
+	defer db.Close()
+	repo := NewSettingsRepo(db)
+
+	repo.UpdateWinAmount(128, 100)
+	repo.UpdatePayoutTable(domain.PayoutTable{domain.ComboTriple: 32}, 100)
+
+	amount, _ := repo.GetWinAmount(100)
+	if amount != 128 {
+		t.Errorf("win amount = %d, want 128", amount)
+	}
+}
+
+func TestGetPayoutTable_InvalidJSON(t *testing.T) {
+	db := setupSettingsDB(t)
+	defer db.Close()
+	repo := NewSettingsRepo(db)
+
+	db.Exec(`INSERT INTO chat_settings (chat_id, payout_table) VALUES (100, 'not-json')`)
+
+	table, err := repo.GetPayoutTable(100)
+	if err != nil {
+		t.Fatalf("GetPayoutTable() error = %v", err)
+	}
+	if len(table) != 0 {
+		t.Errorf("table = %v, want empty (default)", table)
+	}
+}
+
 func TestGetPermission_DefaultFalse(t *testing.T) {
 	db := setupSettingsDB(t)
 	defer db.Close()
@@ -230,3 +621,158 @@ func TestTogglePermission_ChatIsolation(t *testing.T) {
 		t.Error("chat 200 should not have settings allowed")
 	}
 }
+
+func TestUpdateLevelCurve(t *testing.T) {
+	db := setupSettingsDB(t)
+	defer db.Close()
+	repo := NewSettingsRepo(db)
+
+	curve, err := repo.GetLevelCurve(100)
+	if err != nil {
+		t.Fatalf("GetLevelCurve() error = %v", err)
+	}
+	if curve != domain.DefaultLevelCurve {
+		t.Errorf("default curve = %q, want %q", curve, domain.DefaultLevelCurve)
+	}
+
+	if err := repo.UpdateLevelCurve("slow", 100); err != nil {
+		t.Fatalf("UpdateLevelCurve() error = %v", err)
+	}
+	curve, _ = repo.GetLevelCurve(100)
+	if curve != "slow" {
+		t.Errorf("curve = %q, want slow", curve)
+	}
+}
+
+func TestGetBailout(t *testing.T) {
+	db := setupSettingsDB(t)
+	defer db.Close()
+	repo := NewSettingsRepo(db)
+
+	policy, err := repo.GetBailout(100)
+	if err != nil {
+		t.Fatalf("GetBailout() error = %v", err)
+	}
+	if policy.Threshold != -1000 || policy.Cooldown != 7*24*time.Hour {
+		t.Errorf("default policy = %+v, want -1000 and a week", policy)
+	}
+
+	repo.UpdateBailoutThreshold(0, 100)
+	repo.UpdateBailoutCooldown(86400, 100)
+	policy, _ = repo.GetBailout(100)
+	if policy.Enabled() || policy.Cooldown != 24*time.Hour {
+		t.Errorf("policy = %+v, want disabled with a day cooldown", policy)
+	}
+}
+
+func TestGetTransferLimits(t *testing.T) {
+	db := setupSettingsDB(t)
+	defer db.Close()
+	repo := NewSettingsRepo(db)
+
+	limits, err := repo.GetTransferLimits(100)
+	if err != nil {
+		t.Fatalf("GetTransferLimits() error = %v", err)
+	}
+	if limits.Floor != 0 || limits.DailyCap != 500 {
+		t.Errorf("default limits = %+v, want floor 0, cap 500", limits)
+	}
+
+	repo.UpdateTransferFloor(-200, 100)
+	repo.UpdateTransferDailyCap(0, 100)
+	limits, _ = repo.GetTransferLimits(100)
+	if limits.Floor != -200 || limits.DailyCap != 0 {
+		t.Errorf("limits = %+v, want floor -200, no cap", limits)
+	}
+}
+
+func TestBetLimitsDefaultOff(t *testing.T) {
+	db := setupSettingsDB(t)
+	defer db.Close()
+	repo := NewSettingsRepo(db)
+
+	limits, err := repo.GetBetLimits(100)
+	if err != nil {
+		t.Fatalf("GetBetLimits() error = %v", err)
+	}
+	if limits.Enabled() {
+		t.Errorf("default limits = %+v, want bets off", limits)
+	}
+
+	repo.UpdateSpinCost(2, 100)
+	if limits, _ := repo.GetBetLimits(100); limits != (domain.BetLimits{Min: 1}) {
+		t.Errorf("limits of a stored chat = %+v, want bets off", limits)
+	}
+	repo.UpdateBetMax(500, 100)
+	if limits, _ := repo.GetBetLimits(100); limits != (domain.BetLimits{Min: 1, Max: 500}) {
+		t.Errorf("limits = %+v, want 1 to 500", limits)
+	}
+}
+
+func TestLotterySettings(t *testing.T) {
+	db := setupSettingsDB(t)
+	defer db.Close()
+	repo := NewSettingsRepo(db)
+
+	schedule, err := repo.GetLotterySchedule(100)
+	if err != nil {
+		t.Fatalf("GetLotterySchedule() error = %v", err)
+	}
+	if schedule.Enabled() || schedule.Hour != 20 {
+		t.Errorf("default schedule = %+v, want off at 20", schedule)
+	}
+	if price, _ := repo.GetTicketPrice(100); price != 10 {
+		t.Errorf("default ticket price = %d, want 10", price)
+	}
+
+	since := time.Unix(1_700_000_000, 0)
+	repo.UpdateLotterySchedule(domain.LotteryWeekly, 18, since, 100)
+	repo.UpdateTicketPrice(25, 100)
+	repo.UpdateSpinCost(2, 200)
+
+	schedule, _ = repo.GetLotterySchedule(100)
+	if schedule.Mode != domain.LotteryWeekly || schedule.Hour != 18 || !schedule.CheckedAt.Equal(since) {
+		t.Errorf("schedule = %+v", schedule)
+	}
+	if price, _ := repo.GetTicketPrice(100); price != 25 {
+		t.Errorf("ticket price = %d, want 25", price)
+	}
+
+	chats, err := repo.GetLotteryChats()
+	if err != nil {
+		t.Fatalf("GetLotteryChats() error = %v", err)
+	}
+	if len(chats) != 1 || chats[0] != 100 {
+		t.Errorf("lottery chats = %v, want [100]", chats)
+	}
+}
+
+func TestLoanTerms(t *testing.T) {
+	db := setupSettingsDB(t)
+	defer db.Close()
+	repo := NewSettingsRepo(db)
+
+	terms, err := repo.GetLoanTerms(100)
+	if err != nil {
+		t.Fatalf("GetLoanTerms() error = %v", err)
+	}
+	if terms != (domain.LoanTerms{Rate: 5, RepayShare: 50}) || terms.Enabled() {
+		t.Errorf("default terms = %+v, want loans off", terms)
+	}
+
+	repo.UpdateLoanMax(500, 100)
+	if terms, _ := repo.GetLoanTerms(100); terms.Max != 500 {
+		t.Errorf("loan max = %d, want 500", terms.Max)
+	}
+
+	repo.UpdateLoanMax(0, 100)
+	repo.UpdateLoanRate(10, 100)
+	repo.UpdateLoanRepayShare(100, 100)
+	terms, _ = repo.GetLoanTerms(100)
+	if terms != (domain.LoanTerms{Max: 0, Rate: 10, RepayShare: 100}) {
+		t.Errorf("terms = %+v", terms)
+	}
+	if terms.Enabled() {
+		t.Error("loans with zero max are enabled")
+	}
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrTournamentRunning is returned when the chat already has a running tournament.
+	ErrTournamentRunning = errors.New("tournament is already running")
+	// ErrTournamentClosed is returned when the tournament has already finished.
+	ErrTournamentClosed = errors.New("tournament is closed")
+)
+
+type TournamentRepo struct {
+	db *sql.DB
+}
+
+func NewTournamentRepo(db *sql.DB) *TournamentRepo {
+	return &TournamentRepo{db: db}
+}
+
+const tournamentColumns = `id, chat_id, game, rule, status, started_at, ends_at, message_id`
+
+func scanTournament(row interface{ Scan(...any) error }) (domain.Tournament, error) {
+	var t domain.Tournament
+	var startedAt, endsAt int64
+	err := row.Scan(&t.Id, &t.ChatId, &t.Game, &t.Rule, &t.Status, &startedAt, &endsAt, &t.MessageId)
+	t.StartedAt = time.Unix(startedAt, 0)
+	t.EndsAt = time.Unix(endsAt, 0)
+	return t, err
+}
+
+func (r *TournamentRepo) queryTournaments(query string, args ...any) ([]domain.Tournament, error) {
+	rows, err := r.db.Query(`SELECT `+tournamentColumns+` FROM tournaments `+query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanTournaments(rows)
+}
+
+func scanTournaments(rows *sql.Rows) ([]domain.Tournament, error) {
+	defer rows.Close()
+
+	var res []domain.Tournament
+	for rows.Next() {
+		t, err := scanTournament(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// StartTournament stores a new running tournament unless the chat already has one.
+func (r *TournamentRepo) StartTournament(t domain.Tournament) (domain.Tournament, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return domain.Tournament{}, err
+	}
+	defer tx.Rollback()
+
+	var running int64
+	err = tx.QueryRow(`SELECT COUNT(*) FROM tournaments WHERE chat_id = ? AND status = ?`,
+		t.ChatId, domain.TournamentRunning).Scan(&running)
+	if err != nil {
+		return domain.Tournament{}, err
+	}
+	if running > 0 {
+		return domain.Tournament{}, ErrTournamentRunning
+	}
+
+	res, err := tx.Exec(`
+		INSERT INTO tournaments (chat_id, game, rule, status, started_at, ends_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		t.ChatId, t.Game, t.Rule, domain.TournamentRunning, t.StartedAt.Unix(), t.EndsAt.Unix())
+	if err != nil {
+		return domain.Tournament{}, err
+	}
+	if t.Id, err = res.LastInsertId(); err != nil {
+		return domain.Tournament{}, err
+	}
+	t.Status = domain.TournamentRunning
+	return t, tx.Commit()
+}
+
+func (r *TournamentRepo) GetTournament(id int64) (domain.Tournament, error) {
+	return scanTournament(r.db.QueryRow(`SELECT `+tournamentColumns+` FROM tournaments WHERE id = ?`, id))
+}
+
+// GetRunning returns the chat's running tournament; ok is false when there is none.
+func (r *TournamentRepo) GetRunning(chatId int64) (domain.Tournament, bool, error) {
+	t, err := scanTournament(r.db.QueryRow(`SELECT `+tournamentColumns+` FROM tournaments WHERE chat_id = ? AND status = ?`,
+		chatId, domain.TournamentRunning))
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Tournament{}, false, nil
+	}
+	return t, err == nil, err
+}
+
+// SetMessage remembers the standings message to keep up to date.
+func (r *TournamentRepo) SetMessage(id int64, messageId int64) error {
+	_, err := r.db.Exec(`UPDATE tournaments SET message_id = ? WHERE id = ?`, messageId, id)
+	return err
+}
+
+// recordTournamentSpinTx adds a spin to the scores of the chat's running
+// tournament if the spin belongs to it, and reports whether it did.
+func recordTournamentSpinTx(tx *sql.Tx, chatId int64, game domain.Game, userId int64, username string, win bool, net int64, at time.Time) (bool, error) {
+	t, err := scanTournament(tx.QueryRow(`SELECT `+tournamentColumns+` FROM tournaments WHERE chat_id = ? AND status = ?`,
+		chatId, domain.TournamentRunning))
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !t.Counts(game, at) {
+		return false, nil
+	}
+
+	wins := 0
+	if win {
+		wins = 1
+	}
+	_, err = tx.Exec(`
+		INSERT INTO tournament_scores (tournament_id, user_id, username, spins, wins, net)
+		VALUES (?, ?, ?, 1, ?, ?)
+		ON CONFLICT(tournament_id, user_id) DO UPDATE SET
+			username = excluded.username,
+			spins = spins + 1,
+			wins = wins + excluded.wins,
+			net = net + excluded.net`,
+		t.Id, userId, username, wins, net)
+	if err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec(`UPDATE tournaments SET dirty = 1 WHERE id = ?`, t.Id); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetStandings ranks the tournament's players by its rule; ties share a rank.
+func (r *TournamentRepo) GetStandings(t domain.Tournament) ([]domain.TournamentScore, error) {
+	order := `wins DESC, net DESC`
+	if t.Rule == domain.TournamentNet {
+		order = `net DESC, wins DESC`
+	}
+	rows, err := r.db.Query(`
+		SELECT user_id, username, spins, wins, net,
+		       RANK() OVER (ORDER BY `+order+`) AS rank
+		FROM tournament_scores
+		WHERE tournament_id = ?
+		ORDER BY rank, spins, user_id`, t.Id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.TournamentScore
+	for rows.Next() {
+		var s domain.TournamentScore
+		if err := rows.Scan(&s.UserId, &s.Username, &s.Spins, &s.Wins, &s.Net, &s.Rank); err != nil {
+			return nil, err
+		}
+		res = append(res, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Finish closes a running tournament.
+func (r *TournamentRepo) Finish(id int64) error {
+	res, err := r.db.Exec(`UPDATE tournaments SET status = ?, dirty = 0 WHERE id = ? AND status = ?`,
+		domain.TournamentFinished, id, domain.TournamentRunning)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrTournamentClosed
+	}
+	return nil
+}
+
+// GetDue returns the running tournaments whose time is up.
+func (r *TournamentRepo) GetDue(now time.Time) ([]domain.Tournament, error) {
+	return r.queryTournaments(`WHERE status = ? AND ends_at <= ? ORDER BY id`, domain.TournamentRunning, now.Unix())
+}
+
+// TakeDirty returns the running tournaments whose scores changed since their
+// standings message was last refreshed, and marks them refreshed.
+func (r *TournamentRepo) TakeDirty() ([]domain.Tournament, error) {
+	rows, err := r.db.Query(`
+		UPDATE tournaments SET dirty = 0
+		WHERE status = ? AND dirty = 1 AND message_id != 0
+		RETURNING `+tournamentColumns, domain.TournamentRunning)
+	if err != nil {
+		return nil, err
+	}
+	return scanTournaments(rows)
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"errors"
+	"testing"
+	"time"
+)
+
+var tournamentStart = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func startTestTournament(t *testing.T, repo *TournamentRepo, rule domain.TournamentRule) domain.Tournament {
+	t.Helper()
+	tr, err := repo.StartTournament(domain.Tournament{
+		ChatId:    100,
+		Game:      domain.GameSlot,
+		Rule:      rule,
+		StartedAt: tournamentStart,
+		EndsAt:    tournamentStart.Add(2 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("StartTournament() error = %v", err)
+	}
+	return tr
+}
+
+// scoreSpin counts a spin that wins or loses net coins; a win costs one coin
+// and a loss costs what it lost.
+func scoreSpin(t *testing.T, stats *UserStatsRepo, chatId int64, game domain.Game, userId int64, username string, net int64, at time.Time) domain.SpinResult {
+	t.Helper()
+	lastMessageId++
+	spin := domain.Spin{ChatId: chatId, UserId: userId, Game: game, Username: username,
+		MessageId: lastMessageId, At: at, Cost: -net}
+	if net > 0 {
+		spin.Payout, spin.Cost = net+1, 1
+	}
+	result, err := stats.Spin(spin)
+	if err != nil {
+		t.Fatalf("Spin() error = %v", err)
+	}
+	return result
+}
+
+func TestStartTournament_OnePerChat(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewTournamentRepo(db)
+
+	tr := startTestTournament(t, repo, domain.TournamentWins)
+	_, err := repo.StartTournament(domain.Tournament{ChatId: 100, Game: domain.GameSlot, Rule: domain.TournamentNet,
+		StartedAt: tournamentStart, EndsAt: tournamentStart.Add(time.Hour)})
+	if !errors.Is(err, ErrTournamentRunning) {
+		t.Errorf("second StartTournament() error = %v, want ErrTournamentRunning", err)
+	}
+
+	if err := repo.Finish(tr.Id); err != nil {
+		t.Fatalf("Finish() error = %v", err)
+	}
+	if err := repo.Finish(tr.Id); !errors.Is(err, ErrTournamentClosed) {
+		t.Errorf("second Finish() error = %v, want ErrTournamentClosed", err)
+	}
+	if _, ok, _ := repo.GetRunning(100); ok {
+		t.Error("GetRunning() found a finished tournament")
+	}
+	startTestTournament(t, repo, domain.TournamentNet)
+}
+
+func TestTournamentSpin_OnlyInWindow(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewTournamentRepo(db)
+	stats := NewUserStatsRepo(db)
+	tr := startTestTournament(t, repo, domain.TournamentWins)
+
+	tests := []struct {
+		name string
+		chat int64
+		game domain.Game
+		at   time.Time
+		want bool
+	}{
+		{"inside", 100, domain.GameSlot, tournamentStart.Add(time.Minute), true},
+		{"before start", 100, domain.GameSlot, tournamentStart.Add(-time.Minute), false},
+		{"after end", 100, domain.GameSlot, tournamentStart.Add(3 * time.Hour), false},
+		{"other game", 100, domain.GameDice, tournamentStart.Add(time.Minute), false},
+		{"other chat", 200, domain.GameSlot, tournamentStart.Add(time.Minute), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := scoreSpin(t, stats, tt.chat, tt.game, 1, "alice", 10, tt.at)
+			if result.InTournament != tt.want {
+				t.Errorf("InTournament = %v, want %v", result.InTournament, tt.want)
+			}
+		})
+	}
+
+	standings, _ := repo.GetStandings(tr)
+	if len(standings) != 1 || standings[0].Spins != 1 {
+		t.Errorf("standings = %+v, want one spin of alice", standings)
+	}
+}
+
+func TestTournamentStandings_ByRule(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewTournamentRepo(db)
+	stats := NewUserStatsRepo(db)
+	tr := startTestTournament(t, repo, domain.TournamentWins)
+	at := tournamentStart.Add(time.Minute)
+
+	// alice: 2 wins, net 2; bob: 1 win, net 60; carol: no wins, net -3
+	scoreSpin(t, stats, 100, domain.GameSlot, 1, "alice", 2, at)
+	scoreSpin(t, stats, 100, domain.GameSlot, 1, "alice", 1, at)
+	scoreSpin(t, stats, 100, domain.GameSlot, 1, "alice", -1, at)
+	scoreSpin(t, stats, 100, domain.GameSlot, 2, "bob", 63, at)
+	scoreSpin(t, stats, 100, domain.GameSlot, 2, "bob", -3, at)
+	scoreSpin(t, stats, 100, domain.GameSlot, 3, "carol", -3, at)
+
+	byWins, err := repo.GetStandings(tr)
+	if err != nil {
+		t.Fatalf("GetStandings() error = %v", err)
+	}
+	assertOrder(t, "wins", byWins, "alice", "bob", "carol")
+	if byWins[0].Wins != 2 || byWins[0].Net != 2 || byWins[0].Spins != 3 {
+		t.Errorf("alice = %+v, want 3 spins, 2 wins, net 2", byWins[0])
+	}
+
+	tr.Rule = domain.TournamentNet
+	byNet, _ := repo.GetStandings(tr)
+	assertOrder(t, "net", byNet, "bob", "alice", "carol")
+}
+
+func TestTournamentStandings_TiesShareRank(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewTournamentRepo(db)
+	stats := NewUserStatsRepo(db)
+	tr := startTestTournament(t, repo, domain.TournamentWins)
+	at := tournamentStart.Add(time.Minute)
+
+	scoreSpin(t, stats, 100, domain.GameSlot, 1, "alice", 5, at)
+	scoreSpin(t, stats, 100, domain.GameSlot, 2, "bob", 5, at)
+	scoreSpin(t, stats, 100, domain.GameSlot, 3, "carol", -1, at)
+
+	standings, _ := repo.GetStandings(tr)
+	ranks := []int64{standings[0].Rank, standings[1].Rank, standings[2].Rank}
+	if ranks[0] != 1 || ranks[1] != 1 || ranks[2] != 3 {
+		t.Errorf("ranks = %v, want [1 1 3]", ranks)
+	}
+}
+
+func TestTournamentTakeDirty(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewTournamentRepo(db)
+	stats := NewUserStatsRepo(db)
+	tr := startTestTournament(t, repo, domain.TournamentWins)
+	at := tournamentStart.Add(time.Minute)
+
+	scoreSpin(t, stats, 100, domain.GameSlot, 1, "alice", 5, at)
+	if dirty, _ := repo.TakeDirty(); len(dirty) != 0 {
+		t.Errorf("TakeDirty() without a standings message = %d tournaments, want 0", len(dirty))
+	}
+
+	repo.SetMessage(tr.Id, 555)
+	dirty, err := repo.TakeDirty()
+	if err != nil {
+		t.Fatalf("TakeDirty() error = %v", err)
+	}
+	if len(dirty) != 1 || dirty[0].MessageId != 555 {
+		t.Fatalf("TakeDirty() = %+v, want the tournament with message 555", dirty)
+	}
+	if again, _ := repo.TakeDirty(); len(again) != 0 {
+		t.Errorf("second TakeDirty() = %d tournaments, want 0", len(again))
+	}
+
+	scoreSpin(t, stats, 100, domain.GameSlot, 1, "alice", -1, at)
+	if dirty, _ := repo.TakeDirty(); len(dirty) != 1 {
+		t.Errorf("TakeDirty() after a spin = %d tournaments, want 1", len(dirty))
+	}
+}
+
+func TestTournamentGetDue(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewTournamentRepo(db)
+	tr := startTestTournament(t, repo, domain.TournamentWins)
+
+	if due, _ := repo.GetDue(tournamentStart.Add(time.Hour)); len(due) != 0 {
+		t.Errorf("GetDue() before the end = %d, want 0", len(due))
+	}
+	due, err := repo.GetDue(tr.EndsAt)
+	if err != nil {
+		t.Fatalf("GetDue() error = %v", err)
+	}
+	if len(due) != 1 || due[0].Id != tr.Id {
+		t.Errorf("GetDue() at the end = %+v, want the tournament", due)
+	}
+}
+
+func assertOrder(t *testing.T, rule string, standings []domain.TournamentScore, names ...string) {
+	t.Helper()
+	if len(standings) != len(names) {
+		t.Fatalf("%s standings have %d players, want %d", rule, len(standings), len(names))
+	}
+	for i, name := range names {
+		if standings[i].Username != name {
+			t.Errorf("%s standings[%d] = %s, want %s", rule, i, standings[i].Username, name)
+		}
+	}
+}
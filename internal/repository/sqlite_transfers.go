@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrTransferClosed is returned when a transfer was already confirmed or cancelled.
+	ErrTransferClosed = errors.New("transfer is not pending")
+	// ErrTransferFloor is returned when the transfer would leave the sender below the floor.
+	ErrTransferFloor = errors.New("transfer goes below the balance floor")
+	// ErrTransferCap is returned when the sender has used up their daily cap.
+	ErrTransferCap = errors.New("transfer exceeds the daily cap")
+)
+
+type TransferRepo struct {
+	db *sql.DB
+}
+
+func NewTransferRepo(db *sql.DB) *TransferRepo {
+	return &TransferRepo{db: db}
+}
+
+// CreateTransfer stores a pending transfer and returns its id.
+func (r *TransferRepo) CreateTransfer(t domain.Transfer) (int64, error) {
+	res, err := r.db.Exec(`
+		INSERT INTO transfers (chat_id, from_user_id, from_name, to_user_id, to_name, amount, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.ChatId, t.FromId, t.FromName, t.ToId, t.ToName, t.Amount, domain.TransferPending, t.CreatedAt.Unix())
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *TransferRepo) GetTransfer(id int64) (domain.Transfer, error) {
+	return getTransfer(r.db, id)
+}
+
+// Confirm moves the coins of a pending transfer if the sender's limits allow
+// it. The balances and the transfer status change together or not at all.
+func (r *TransferRepo) Confirm(id int64, limits domain.TransferLimits, at time.Time) (domain.Transfer, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return domain.Transfer{}, err
+	}
+	defer tx.Rollback()
+
+	t, err := getTransfer(tx, id)
+	if err != nil {
+		return t, err
+	}
+	if t.Status != domain.TransferPending {
+		return t, ErrTransferClosed
+	}
+
+	var balance int64
+	err = tx.QueryRow(`
+		SELECT balance FROM user_stats WHERE chat_id = ? AND user_id = ? AND game = ?`,
+		t.ChatId, t.FromId, domain.CoinGame).Scan(&balance)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return t, err
+	}
+	if balance-t.Amount < limits.Floor {
+		return t, ErrTransferFloor
+	}
+
+	if limits.DailyCap > 0 {
+		var sent int64
+		err = tx.QueryRow(`
+			SELECT COALESCE(SUM(amount), 0) FROM transfers
+			WHERE chat_id = ? AND from_user_id = ? AND status = ? AND done_at >= ?`,
+			t.ChatId, t.FromId, domain.TransferDone, limits.DayStart.Unix()).Scan(&sent)
+		if err != nil {
+			return t, err
+		}
+		if sent+t.Amount > limits.DailyCap {
+			return t, ErrTransferCap
+		}
+	}
+
+	_, err = tx.Exec(`UPDATE transfers SET status = ?, done_at = ? WHERE id = ?`,
+		domain.TransferDone, at.Unix(), id)
+	if err != nil {
+		return t, err
+	}
+	if err := addBalanceTx(tx, t.ChatId, t.FromId, t.FromName, -t.Amount); err != nil {
+		return t, err
+	}
+	if err := addBalanceTx(tx, t.ChatId, t.ToId, t.ToName, t.Amount); err != nil {
+		return t, err
+	}
+	if err := tx.Commit(); err != nil {
+		return t, err
+	}
+	t.Status = domain.TransferDone
+	return t, nil
+}
+
+// Cancel closes a pending transfer without moving any coins.
+func (r *TransferRepo) Cancel(id int64) error {
+	res, err := r.db.Exec(`UPDATE transfers SET status = ? WHERE id = ? AND status = ?`,
+		domain.TransferCancelled, id, domain.TransferPending)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrTransferClosed
+	}
+	return nil
+}
+
+// rowQuerier is what *sql.DB and *sql.Tx have in common for single-row reads.
+type rowQuerier interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func getTransfer(q rowQuerier, id int64) (domain.Transfer, error) {
+	var t domain.Transfer
+	var createdAt int64
+	err := q.QueryRow(`
+		SELECT id, chat_id, from_user_id, from_name, to_user_id, to_name, amount, status, created_at
+		FROM transfers WHERE id = ?`, id).Scan(&t.Id, &t.ChatId, &t.FromId, &t.FromName,
+		&t.ToId, &t.ToName, &t.Amount, &t.Status, &createdAt)
+	t.CreatedAt = time.Unix(createdAt, 0)
+	return t, err
+}
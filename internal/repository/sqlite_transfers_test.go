@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestTransfer(t *testing.T, repo *TransferRepo, amount int64, at time.Time) int64 {
+	t.Helper()
+	id, err := repo.CreateTransfer(domain.Transfer{
+		ChatId: 100, FromId: 1, FromName: "alice", ToId: 2, ToName: "bob", Amount: amount, CreatedAt: at,
+	})
+	if err != nil {
+		t.Fatalf("CreateTransfer() error = %v", err)
+	}
+	return id
+}
+
+func TestConfirm_MovesCoins(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	transfers := NewTransferRepo(db)
+
+	stats.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 1, Payout: 64})
+	at := time.Unix(1_000_000, 0)
+	id := newTestTransfer(t, transfers, 40, at)
+
+	transfer, err := transfers.Confirm(id, domain.TransferLimits{}, at)
+	if err != nil {
+		t.Fatalf("Confirm() error = %v", err)
+	}
+	if transfer.Status != domain.TransferDone {
+		t.Errorf("status = %q, want done", transfer.Status)
+	}
+
+	alice, _ := stats.GetPersonalStats(100, 1, domain.CoinGame)
+	bob, _ := stats.GetPersonalStats(100, 2, domain.CoinGame)
+	if alice.Balance != 24 || bob.Balance != 40 {
+		t.Errorf("balances = %d, %d, want 24, 40", alice.Balance, bob.Balance)
+	}
+
+	if _, err := transfers.Confirm(id, domain.TransferLimits{}, at); !errors.Is(err, ErrTransferClosed) {
+		t.Errorf("second Confirm() error = %v, want ErrTransferClosed", err)
+	}
+}
+
+func TestConfirm_Floor(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	transfers := NewTransferRepo(db)
+
+	stats.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 1, Payout: 30})
+	at := time.Unix(1_000_000, 0)
+	id := newTestTransfer(t, transfers, 40, at)
+
+	if _, err := transfers.Confirm(id, domain.TransferLimits{Floor: 0}, at); !errors.Is(err, ErrTransferFloor) {
+		t.Fatalf("Confirm() error = %v, want ErrTransferFloor", err)
+	}
+	alice, _ := stats.GetPersonalStats(100, 1, domain.CoinGame)
+	if alice.Balance != 30 {
+		t.Errorf("alice balance = %d, want 30 untouched", alice.Balance)
+	}
+
+	if _, err := transfers.Confirm(id, domain.TransferLimits{Floor: -100}, at); err != nil {
+		t.Errorf("Confirm() with a lower floor error = %v", err)
+	}
+}
+
+func TestConfirm_DailyCap(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+	transfers := NewTransferRepo(db)
+
+	stats.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 1, Payout: 500})
+	day := time.Unix(1_000_000, 0)
+	limits := domain.TransferLimits{DailyCap: 100, DayStart: day}
+
+	first := newTestTransfer(t, transfers, 60, day)
+	if _, err := transfers.Confirm(first, limits, day.Add(time.Hour)); err != nil {
+		t.Fatalf("first Confirm() error = %v", err)
+	}
+	second := newTestTransfer(t, transfers, 50, day)
+	if _, err := transfers.Confirm(second, limits, day.Add(2*time.Hour)); !errors.Is(err, ErrTransferCap) {
+		t.Errorf("second Confirm() error = %v, want ErrTransferCap", err)
+	}
+
+	nextDay := domain.TransferLimits{DailyCap: 100, DayStart: day.Add(24 * time.Hour)}
+	if _, err := transfers.Confirm(second, nextDay, day.Add(25*time.Hour)); err != nil {
+		t.Errorf("Confirm() on the next day error = %v", err)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	transfers := NewTransferRepo(db)
+
+	id := newTestTransfer(t, transfers, 10, time.Unix(1_000_000, 0))
+	if err := transfers.Cancel(id); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	if err := transfers.Cancel(id); !errors.Is(err, ErrTransferClosed) {
+		t.Errorf("second Cancel() error = %v, want ErrTransferClosed", err)
+	}
+	transfer, _ := transfers.GetTransfer(id)
+	if transfer.Status != domain.TransferCancelled {
+		t.Errorf("status = %q, want cancelled", transfer.Status)
+	}
+}
+
+func TestFindByHandle(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	stats := NewUserStatsRepo(db)
+
+	stats.Spin(domain.Spin{ChatId: 100, UserId: 2, Game: domain.GameSlot, Username: "bob", Handle: "bobby", MessageId: 1})
+
+	userId, name, ok, err := stats.FindByHandle(100, "Bobby")
+	if err != nil {
+		t.Fatalf("FindByHandle() error = %v", err)
+	}
+	if !ok || userId != 2 || name != "bob" {
+		t.Errorf("FindByHandle() = %d, %q, %v, want 2, bob, true", userId, name, ok)
+	}
+	if _, _, ok, _ := stats.FindByHandle(200, "bobby"); ok {
+		t.Error("FindByHandle() found a player of another chat")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"bandit-counter-bot/internal/domain"
 	"database/sql"
 	"errors"
+	"strings"
+	"time"
 )
 
 type UserStatsRepo struct {
@@ -14,26 +16,120 @@ func NewUserStatsRepo(db *sql.DB) *UserStatsRepo {
 	return &UserStatsRepo{db: db}
 }
 
-func (r *UserStatsRepo) Spin(chatId int64, userId int64, username string, win bool, winAmount int64) error {
-	var balanceDelta int64
+// Spin applies a spin exactly once per (chat, message): the ledger row is
+// claimed first, and a message that is already in the ledger is reported as
+// a duplicate without touching balances, streaks or the jackpot. A spin over
+// the daily limit is rolled back the same way. A counted 🎰 settles the
+// player's pending bet in the same transaction and reports its stake, and a
+// winning one pays its LoanRepayShare towards the player's open loan. With a
+// DuelWindow the spin also rolls in the player's running duel, and every
+// counted spin scores in the chat's running tournament.
+func (r *UserStatsRepo) Spin(spin domain.Spin) (domain.SpinResult, error) {
+	var result domain.SpinResult
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	// a duel compares the plain rolls, whatever the player staked on them
+	rolled := spin.Payout
+	if spin.Game == domain.GameSlot {
+		bet, ok, err := getBet(tx, spin.ChatId, spin.UserId, spin.At)
+		if err != nil {
+			return result, err
+		}
+		if ok {
+			spin.ApplyStake(bet.Stake)
+		}
+	}
+
+	res, err := tx.Exec(`
+		INSERT INTO spins (chat_id, user_id, game, username, message_id, dice_value,
+			payout, cost, stake, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(chat_id, message_id) DO NOTHING`,
+		spin.ChatId, spin.UserId, spin.Game, spin.Username, spin.MessageId, spin.Value,
+		spin.Payout, spin.Charged(spin.Payout), spin.Stake, spin.At.Unix(),
+	)
+	if err != nil {
+		return result, err
+	}
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return result, err
+	}
+	if inserted == 0 {
+		result.Duplicate = true
+		return result, nil
+	}
+
+	if spin.DailyLimit > 0 {
+		var today int64
+		err := tx.QueryRow(`
+			SELECT COUNT(*) FROM spins
+			WHERE chat_id = ? AND user_id = ? AND created_at >= ?`,
+			spin.ChatId, spin.UserId, spin.DayStart.Unix()).Scan(&today)
+		if err != nil {
+			return result, err
+		}
+		// today includes the row just claimed above
+		if today > spin.DailyLimit {
+			result.OverQuota = true
+			return result, nil
+		}
+	}
+	ledgerId, err := res.LastInsertId()
+	if err != nil {
+		return result, err
+	}
+
+	if spin.Stake > 0 {
+		_, err = tx.Exec(`DELETE FROM pending_bets WHERE chat_id = ? AND user_id = ?`,
+			spin.ChatId, spin.UserId)
+		if err != nil {
+			return result, err
+		}
+		result.Stake = spin.Stake
+	}
+
+	if spin.Jackpot {
+		result.JackpotWon, err = takePoolTx(tx, spin.ChatId)
+		if err != nil {
+			return result, err
+		}
+	} else if spin.Payout == 0 && spin.JackpotShare > 0 && spin.Cost > 0 {
+		if err := addToPoolTx(tx, spin.ChatId, spin.Cost*spin.JackpotShare); err != nil {
+			return result, err
+		}
+	}
+
+	payout := spin.Payout + result.JackpotWon
+	cost := spin.Charged(payout)
+	if result.JackpotWon > 0 {
+		_, err = tx.Exec(`UPDATE spins SET payout = ?, jackpot = ?, cost = ? WHERE id = ?`,
+			payout, result.JackpotWon, cost, ledgerId)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	balanceDelta := payout - cost
 	var winDelta int64
 	var winFlag int64
-	if win {
-		balanceDelta = winAmount
+	if payout > 0 {
 		winDelta = 1
 		winFlag = 1
-	} else {
-		balanceDelta = -1
-		winDelta = 0
-		winFlag = 0
 	}
 
-	return r.executeUpdate(`
-		INSERT INTO user_stats (chat_id, user_id, username, spins, wins, balance,
+	_, err = tx.Exec(`
+		INSERT INTO user_stats (chat_id, user_id, game, username, handle, spins, wins, balance,
 			current_streak, max_streak, current_loss_streak, max_loss_streak)
-		VALUES (?, ?, ?, 1, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT(chat_id, user_id) DO UPDATE SET
+		VALUES (?, ?, ?, ?, ?, 1, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(chat_id, user_id, game) DO UPDATE SET
 			username = excluded.username,
+			handle = excluded.handle,
 			spins = spins + 1,
 			wins = wins + excluded.wins,
 			balance = balance + excluded.balance,
@@ -41,25 +137,68 @@ func (r *UserStatsRepo) Spin(chatId int64, userId int64, username string, win bo
 			max_streak = CASE WHEN ? = 1 THEN MAX(max_streak, current_streak + 1) ELSE max_streak END,
 			current_loss_streak = CASE WHEN ? = 0 THEN current_loss_streak + 1 ELSE 0 END,
 			max_loss_streak = CASE WHEN ? = 0 THEN MAX(max_loss_streak, current_loss_streak + 1) ELSE max_loss_streak END`,
-		chatId, userId, username, winDelta, balanceDelta,
+		spin.ChatId, spin.UserId, spin.Game, spin.Username, spin.Handle, winDelta, balanceDelta,
 		winFlag, winFlag, 1-winFlag, 1-winFlag,
 		winFlag, winFlag, winFlag, winFlag,
 	)
+	if err != nil {
+		return result, err
+	}
+
+	result.XPGained = domain.SpinXP(spin, result)
+	result.XP, err = addXP(tx, spin.ChatId, spin.UserId, result.XPGained)
+	if err != nil {
+		return result, err
+	}
+
+	if spin.Game == domain.CoinGame && payout > 0 && spin.LoanRepayShare > 0 {
+		loan, repaid, err := repayTx(tx, spin.ChatId, spin.UserId, spin.At, func(tx *sql.Tx, l domain.Loan) (int64, error) {
+			return domain.LoanRepayment(payout, spin.LoanRepayShare, l.Debt), nil
+		})
+		if err != nil {
+			return result, err
+		}
+		result.LoanRepaid, result.LoanDebt = repaid, loan.Debt
+	}
+
+	result.InTournament, err = recordTournamentSpinTx(tx, spin.ChatId, spin.Game, spin.UserId, spin.Username,
+		payout > 0, balanceDelta, spin.At)
+	if err != nil {
+		return result, err
+	}
+
+	if spin.DuelWindow > 0 {
+		result.Duel, result.DuelRolled, err = recordRollTx(tx, spin.ChatId, spin.UserId, rolled, spin.At, spin.DuelWindow)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, tx.Commit()
+}
+
+// IsCounted reports whether the chat message is already in the spin ledger.
+func (r *UserStatsRepo) IsCounted(chatId int64, messageId int64) (bool, error) {
+	var counted bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM spins WHERE chat_id = ? AND message_id = ?)`,
+		chatId, messageId).Scan(&counted)
+	return counted, err
 }
 
-func (r *UserStatsRepo) GetPersonalStats(chatId int64, userId int64) (domain.PersonalStats, error) {
+func (r *UserStatsRepo) GetPersonalStats(chatId int64, userId int64, game domain.Game) (domain.PersonalStats, error) {
 	var stats domain.PersonalStats
 	err := r.db.QueryRow(`
 		WITH ranked AS (
 			SELECT user_id, spins, wins, balance,
 			       current_streak, max_streak, current_loss_streak, max_loss_streak,
 			       DENSE_RANK() OVER (ORDER BY balance DESC) AS rank
-			FROM user_stats WHERE chat_id = ?
+			FROM user_stats WHERE chat_id = ? AND game = ?
 		)
 		SELECT spins, wins, balance, current_streak, max_streak,
 		       current_loss_streak, max_loss_streak, rank
 		FROM ranked WHERE user_id = ?`,
-		chatId, userId).Scan(&stats.Spins, &stats.Wins, &stats.Balance,
+		chatId, game, userId).Scan(&stats.Spins, &stats.Wins, &stats.Balance,
 		&stats.CurrentStreak, &stats.MaxStreak,
 		&stats.CurrentLossStreak, &stats.MaxLossStreak, &stats.Rank)
 	if err != nil {
@@ -71,122 +210,42 @@ func (r *UserStatsRepo) GetPersonalStats(chatId int64, userId int64) (domain.Per
 	return stats, nil
 }
 
-func (r *UserStatsRepo) GetRichStats(chatId int64) ([]domain.RatingStats, error) {
-	rows, err := r.db.Query(`
-		SELECT username, spins, wins, balance,
-		       DENSE_RANK() OVER (ORDER BY balance DESC) AS rank
-		FROM user_stats
-		WHERE chat_id = ?
-		ORDER BY balance DESC`, chatId)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var res []domain.RatingStats
-	for rows.Next() {
-		var s domain.RatingStats
-		if err := rows.Scan(&s.Username, &s.Spins, &s.Wins, &s.Balance, &s.Rank); err != nil {
-			return nil, err
-		}
-		res = append(res, s)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return res, nil
-}
-
-func (r *UserStatsRepo) GetDebtorsStats(chatId int64) ([]domain.RatingStats, error) {
-	rows, err := r.db.Query(`
-		SELECT username, spins, wins, balance,
-		       DENSE_RANK() OVER (ORDER BY balance ASC) AS rank
-		FROM user_stats
-		WHERE chat_id = ?
-		ORDER BY balance ASC`, chatId)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var res []domain.RatingStats
-	for rows.Next() {
-		var s domain.RatingStats
-		if err := rows.Scan(&s.Username, &s.Spins, &s.Wins, &s.Balance, &s.Rank); err != nil {
-			return nil, err
-		}
-		res = append(res, s)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return res, nil
-}
-
-func (r *UserStatsRepo) GetLuckyStats(chatId int64) ([]domain.RatingStats, error) {
-	rows, err := r.db.Query(`
-		SELECT username, spins, wins, balance,
-		       CASE WHEN spins > 0 THEN CAST(wins AS REAL) / spins * 100 ELSE 0 END AS luck,
-		       DENSE_RANK() OVER (ORDER BY CASE WHEN spins > 0 THEN CAST(wins AS REAL) / spins ELSE 0 END DESC) AS rank
-		FROM user_stats
-		WHERE chat_id = ?
-		ORDER BY luck DESC`, chatId)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var res []domain.RatingStats
-	for rows.Next() {
-		var s domain.RatingStats
-		if err := rows.Scan(&s.Username, &s.Spins, &s.Wins, &s.Balance, &s.Luck, &s.Rank); err != nil {
-			return nil, err
-		}
-		res = append(res, s)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return res, nil
-}
-
-func (r *UserStatsRepo) GetStreakStats(chatId int64) ([]domain.RatingStats, error) {
-	rows, err := r.db.Query(`
-		SELECT username, spins, wins, max_streak, max_loss_streak,
-		       DENSE_RANK() OVER (ORDER BY max_streak DESC) AS rank
-		FROM user_stats
-		WHERE chat_id = ?
-		ORDER BY max_streak DESC`, chatId)
+// FindByHandle looks up a player of the chat by their Telegram username, as
+// last seen on one of their spins.
+func (r *UserStatsRepo) FindByHandle(chatId int64, handle string) (int64, string, bool, error) {
+	var userId int64
+	var name string
+	err := r.db.QueryRow(`
+		SELECT user_id, username FROM user_stats
+		WHERE chat_id = ? AND handle = ?
+		LIMIT 1`,
+		chatId, strings.ToLower(handle)).Scan(&userId, &name)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var res []domain.RatingStats
-	for rows.Next() {
-		var s domain.RatingStats
-		if err := rows.Scan(&s.Username, &s.Spins, &s.Wins, &s.MaxStreak, &s.MaxLossStreak, &s.Rank); err != nil {
-			return nil, err
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, "", false, nil
 		}
-		res = append(res, s)
+		return 0, "", false, err
 	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return res, nil
+	return userId, name, true, nil
 }
 
-func (r *UserStatsRepo) ResetChat(chatId int64) error {
-	return r.executeUpdate(`DELETE FROM user_stats WHERE chat_id = ?`, chatId)
+func (r *UserStatsRepo) CountSpinsSince(chatId int64, userId int64, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM spins
+		WHERE chat_id = ? AND user_id = ? AND created_at >= ?`,
+		chatId, userId, since.Unix()).Scan(&count)
+	return count, err
 }
 
-func (r *UserStatsRepo) executeUpdate(query string, args ...interface{}) error {
-	_, err := r.db.Exec(query, args...)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil
-		}
-		return err
-	}
-	return nil
+// addBalanceTx credits (or, with a negative delta, debits) the player's coins
+// outside of a spin; the player gets a row even if they never spun.
+func addBalanceTx(tx *sql.Tx, chatId int64, userId int64, username string, delta int64) error {
+	_, err := tx.Exec(`
+		INSERT INTO user_stats (chat_id, user_id, game, username, balance) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(chat_id, user_id, game) DO UPDATE SET
+			username = excluded.username,
+			balance = balance + excluded.balance`,
+		chatId, userId, domain.CoinGame, username, delta)
+	return err
 }
@@ -1,59 +1,282 @@
 package repository
 
 import (
+	"bandit-counter-bot/internal/domain"
 	"database/sql"
 	"testing"
 	"testing/fstest"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
-func setupTestDB(t *testing.T) *sql.DB {
+// openTestDB opens an in-memory database with schema applied as its only
+// migration.
+func openTestDB(t *testing.T, schema string) *sql.DB {
 	t.Helper()
 	db, err := sql.Open("sqlite3", ":memory:")
 	if err != nil {
 		t.Fatal(err)
 	}
+	// Every connection to :memory: is a separate database.
+	db.SetMaxOpenConns(1)
 
 	migrations := fstest.MapFS{
-		"001_init.sql": &fstest.MapFile{
-			Data: []byte(`
-				CREATE TABLE IF NOT EXISTS user_stats (
-					chat_id INTEGER NOT NULL,
-					user_id INTEGER NOT NULL,
-					username TEXT NOT NULL DEFAULT 'noname',
-					spins INTEGER NOT NULL DEFAULT 0,
-					wins INTEGER NOT NULL DEFAULT 0,
-					balance INTEGER NOT NULL DEFAULT 0,
-					current_streak INTEGER NOT NULL DEFAULT 0,
-					max_streak INTEGER NOT NULL DEFAULT 0,
-					current_loss_streak INTEGER NOT NULL DEFAULT 0,
-					max_loss_streak INTEGER NOT NULL DEFAULT 0,
-					PRIMARY KEY (chat_id, user_id)
-				);
-				CREATE INDEX IF NOT EXISTS user_stats_chat_balance_idx
-				ON user_stats(chat_id, balance DESC);
-			`),
-		},
+		"001_init.sql": &fstest.MapFile{Data: []byte(schema)},
 	}
-
 	if err := Migrate(db, migrations); err != nil {
 		t.Fatal(err)
 	}
 	return db
 }
 
+// testSchema holds the tables of everything but the chat settings, which
+// setupTestDB adds from settingsSchema.
+const testSchema = `
+	CREATE TABLE IF NOT EXISTS user_stats (
+		chat_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		game TEXT NOT NULL DEFAULT 'slot',
+		username TEXT NOT NULL DEFAULT 'noname',
+		handle TEXT NOT NULL DEFAULT '',
+		spins INTEGER NOT NULL DEFAULT 0,
+		wins INTEGER NOT NULL DEFAULT 0,
+		balance INTEGER NOT NULL DEFAULT 0,
+		current_streak INTEGER NOT NULL DEFAULT 0,
+		max_streak INTEGER NOT NULL DEFAULT 0,
+		current_loss_streak INTEGER NOT NULL DEFAULT 0,
+		max_loss_streak INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (chat_id, user_id, game)
+	);
+	CREATE INDEX IF NOT EXISTS user_stats_chat_balance_idx
+	ON user_stats(chat_id, game, balance DESC);
+	CREATE TABLE IF NOT EXISTS jackpots (
+		chat_id INTEGER PRIMARY KEY,
+		pool_cents INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE TABLE IF NOT EXISTS spins (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chat_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		game TEXT NOT NULL,
+		username TEXT NOT NULL,
+		message_id INTEGER NOT NULL,
+		dice_value INTEGER NOT NULL,
+		payout INTEGER NOT NULL,
+		jackpot INTEGER NOT NULL DEFAULT 0,
+		cost INTEGER NOT NULL,
+		stake INTEGER NOT NULL DEFAULT 0,
+		created_at INTEGER NOT NULL
+	);
+	CREATE UNIQUE INDEX IF NOT EXISTS spins_chat_message_idx
+	ON spins(chat_id, message_id);
+	CREATE TABLE IF NOT EXISTS seasons (
+		chat_id INTEGER NOT NULL,
+		number INTEGER NOT NULL,
+		started_at INTEGER NOT NULL,
+		ended_at INTEGER NOT NULL,
+		PRIMARY KEY (chat_id, number)
+	);
+	CREATE TABLE IF NOT EXISTS season_standings (
+		chat_id INTEGER NOT NULL,
+		season INTEGER NOT NULL,
+		game TEXT NOT NULL,
+		user_id INTEGER NOT NULL,
+		username TEXT NOT NULL,
+		spins INTEGER NOT NULL,
+		wins INTEGER NOT NULL,
+		balance INTEGER NOT NULL,
+		max_streak INTEGER NOT NULL,
+		max_loss_streak INTEGER NOT NULL,
+		rank INTEGER NOT NULL,
+		PRIMARY KEY (chat_id, season, game, user_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS daily_claims (
+		chat_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		day TEXT NOT NULL,
+		streak INTEGER NOT NULL,
+		amount INTEGER NOT NULL,
+		claimed_at INTEGER NOT NULL,
+		PRIMARY KEY (chat_id, user_id, day)
+	);
+
+	CREATE TABLE IF NOT EXISTS pending_bets (
+		chat_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		stake INTEGER NOT NULL,
+		expires_at INTEGER NOT NULL,
+		PRIMARY KEY (chat_id, user_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS lottery_pots (
+		chat_id INTEGER PRIMARY KEY,
+		amount INTEGER NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE IF NOT EXISTS lottery_tickets (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chat_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		username TEXT NOT NULL,
+		price INTEGER NOT NULL,
+		bought_at INTEGER NOT NULL,
+		draw_id INTEGER NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE IF NOT EXISTS lottery_draws (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chat_id INTEGER NOT NULL,
+		drawn_at INTEGER NOT NULL,
+		seed INTEGER NOT NULL,
+		tickets INTEGER NOT NULL,
+		pot INTEGER NOT NULL,
+		ticket_id INTEGER NOT NULL,
+		winner_id INTEGER NOT NULL,
+		winner_name TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'won',
+		claimed_at INTEGER NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE IF NOT EXISTS loans (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chat_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		username TEXT NOT NULL,
+		principal INTEGER NOT NULL,
+		debt INTEGER NOT NULL,
+		rate INTEGER NOT NULL,
+		taken_at INTEGER NOT NULL,
+		accrued_at INTEGER NOT NULL,
+		repaid_at INTEGER NOT NULL DEFAULT 0
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS loans_open_idx
+	ON loans(chat_id, user_id) WHERE repaid_at = 0;
+
+	CREATE TABLE IF NOT EXISTS duels (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chat_id INTEGER NOT NULL,
+		challenger_id INTEGER NOT NULL,
+		challenger_name TEXT NOT NULL,
+		opponent_id INTEGER NOT NULL,
+		opponent_name TEXT NOT NULL,
+		stake INTEGER NOT NULL DEFAULT 0,
+		status TEXT NOT NULL DEFAULT 'offered',
+		challenger_payout INTEGER NOT NULL DEFAULT -1,
+		opponent_payout INTEGER NOT NULL DEFAULT -1,
+		winner_id INTEGER NOT NULL DEFAULT 0,
+		created_at INTEGER NOT NULL,
+		accepted_at INTEGER NOT NULL DEFAULT 0,
+		finished_at INTEGER NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE IF NOT EXISTS tournaments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chat_id INTEGER NOT NULL,
+		game TEXT NOT NULL,
+		rule TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'running',
+		started_at INTEGER NOT NULL,
+		ends_at INTEGER NOT NULL,
+		message_id INTEGER NOT NULL DEFAULT 0,
+		dirty INTEGER NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE IF NOT EXISTS tournament_scores (
+		tournament_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		username TEXT NOT NULL,
+		spins INTEGER NOT NULL DEFAULT 0,
+		wins INTEGER NOT NULL DEFAULT 0,
+		net INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (tournament_id, user_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS transfers (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chat_id INTEGER NOT NULL,
+		from_user_id INTEGER NOT NULL,
+		from_name TEXT NOT NULL,
+		to_user_id INTEGER NOT NULL,
+		to_name TEXT NOT NULL,
+		amount INTEGER NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		created_at INTEGER NOT NULL,
+		done_at INTEGER NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE IF NOT EXISTS bailouts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chat_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		debt INTEGER NOT NULL,
+		created_at INTEGER NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS achievements (
+		chat_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		achievement TEXT NOT NULL,
+		unlocked_at INTEGER NOT NULL,
+		PRIMARY KEY (chat_id, user_id, achievement)
+	);
+
+	CREATE TABLE IF NOT EXISTS player_xp (
+		chat_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		xp INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (chat_id, user_id)
+	);
+`
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	return openTestDB(t, testSchema+settingsSchema)
+}
+
+var lastMessageId int64
+
+// spin records a spin like a chat with default settings: a loss costs 1 coin
+// and a win is paid in full.
+// Every call gets a fresh message id so it is never taken for a redelivery.
+func spin(t *testing.T, repo *UserStatsRepo, chatId, userId int64, username string, payout int64) domain.SpinResult {
+	t.Helper()
+	lastMessageId++
+	result, err := repo.Spin(domain.Spin{
+		ChatId:    chatId,
+		UserId:    userId,
+		Game:      domain.GameSlot,
+		Username:  username,
+		MessageId: lastMessageId,
+		At:        time.Now(),
+		Payout:    payout,
+		Cost:      1,
+		FreeWins:  true,
+	})
+	if err != nil {
+		t.Fatalf("Spin() error = %v", err)
+	}
+	return result
+}
+
+// firstPage returns the first page of a leaderboard over all time.
+func firstPage(repo *UserStatsRepo, chatId int64, game domain.Game, rating domain.Rating) ([]domain.RatingStats, error) {
+	return repo.GetRatingPage(domain.RatingQuery{ChatId: chatId, Game: game, Rating: rating}, 10, 0)
+}
+
 func TestSpin_NewUser(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
 	repo := NewUserStatsRepo(db)
 
-	err := repo.Spin(100, 1, "alice", false, 64)
+	_, err := repo.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", Cost: 1})
 	if err != nil {
 		t.Fatalf("Spin() error = %v", err)
 	}
 
-	stats, err := repo.GetPersonalStats(100, 1)
+	stats, err := repo.GetPersonalStats(100, 1, domain.GameSlot)
 	if err != nil {
 		t.Fatalf("GetPersonalStats() error = %v", err)
 	}
@@ -73,12 +296,12 @@ func TestSpin_Win(t *testing.T) {
 	defer db.Close()
 	repo := NewUserStatsRepo(db)
 
-	err := repo.Spin(100, 1, "alice", true, 64)
+	_, err := repo.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", Payout: 64, Cost: 1, FreeWins: true})
 	if err != nil {
 		t.Fatalf("Spin() error = %v", err)
 	}
 
-	stats, err := repo.GetPersonalStats(100, 1)
+	stats, err := repo.GetPersonalStats(100, 1, domain.GameSlot)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -90,15 +313,289 @@ func TestSpin_Win(t *testing.T) {
 	}
 }
 
+func TestSpin_PayoutAmount(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserStatsRepo(db)
+
+	spin(t, repo, 100, 1, "alice", 500)
+	spin(t, repo, 100, 1, "alice", 5)
+
+	stats, err := repo.GetPersonalStats(100, 1, domain.GameSlot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Wins != 2 {
+		t.Errorf("Wins = %d, want 2", stats.Wins)
+	}
+	if stats.Balance != 505 {
+		t.Errorf("Balance = %d, want 505", stats.Balance)
+	}
+}
+
+func TestSpin_Cost(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserStatsRepo(db)
+
+	repo.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 1, Cost: 5})             // loss: -5
+	repo.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 2, Payout: 64, Cost: 5}) // win: +59
+
+	stats, err := repo.GetPersonalStats(100, 1, domain.GameSlot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Balance != 54 {
+		t.Errorf("Balance = %d, want 54 (-5 +59)", stats.Balance)
+	}
+}
+
+func TestSpin_FreeSpin(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserStatsRepo(db)
+
+	repo.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice"})
+
+	stats, err := repo.GetPersonalStats(100, 1, domain.GameSlot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Balance != 0 {
+		t.Errorf("Balance = %d, want 0", stats.Balance)
+	}
+	if stats.Spins != 1 || stats.CurrentLossStreak != 1 {
+		t.Errorf("Spins = %d, CurrentLossStreak = %d, want 1 and 1", stats.Spins, stats.CurrentLossStreak)
+	}
+}
+
+func TestSpin_RecordsLedger(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserStatsRepo(db)
+
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	_, err := repo.Spin(domain.Spin{
+		ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice",
+		MessageId: 42, Value: 64, At: at, Payout: 64, Cost: 1,
+	})
+	if err != nil {
+		t.Fatalf("Spin() error = %v", err)
+	}
+	spin(t, repo, 100, 2, "bob", 0)
+
+	var userId, messageId, payout, cost, createdAt int64
+	var value int
+	var game, username string
+	err = db.QueryRow(`
+		SELECT user_id, game, username, message_id, dice_value, payout, cost, created_at
+		FROM spins WHERE chat_id = 100 ORDER BY id LIMIT 1`).
+		Scan(&userId, &game, &username, &messageId, &value, &payout, &cost, &createdAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if userId != 1 || game != "slot" || username != "alice" || messageId != 42 ||
+		value != 64 || payout != 64 || cost != 1 || createdAt != at.Unix() {
+		t.Errorf("ledger row = (%d, %s, %s, %d, %d, %d, %d, %d), want (1, slot, alice, 42, 64, 64, 1, %d)",
+			userId, game, username, messageId, value, payout, cost, createdAt, at.Unix())
+	}
+
+	var count int
+	db.QueryRow(`SELECT COUNT(*) FROM spins WHERE chat_id = 100`).Scan(&count)
+	if count != 2 {
+		t.Errorf("ledger rows = %d, want 2", count)
+	}
+}
+
+func TestSpin_LedgerRollsBackWithAggregates(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserStatsRepo(db)
+
+	db.Exec(`DROP TABLE spins`)
+
+	_, err := repo.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", Cost: 1})
+	if err == nil {
+		t.Fatal("expected error when the ledger cannot be written")
+	}
+	if _, err := repo.GetPersonalStats(100, 1, domain.GameSlot); err == nil {
+		t.Error("aggregates should not be updated when the ledger write fails")
+	}
+}
+
+func TestSpin_ReplayIsIgnored(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserStatsRepo(db)
+
+	win := domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 7, Value: 64, Payout: 64, Cost: 1}
+	loss := domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 8, Value: 2, Cost: 1}
+
+	for _, s := range []domain.Spin{win, loss, win, loss, loss} {
+		if _, err := repo.Spin(s); err != nil {
+			t.Fatalf("Spin() error = %v", err)
+		}
+	}
+
+	stats, err := repo.GetPersonalStats(100, 1, domain.GameSlot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Spins != 2 || stats.Wins != 1 {
+		t.Errorf("Spins, Wins = %d, %d, want 2, 1", stats.Spins, stats.Wins)
+	}
+	if stats.Balance != 62 {
+		t.Errorf("Balance = %d, want 62 (+63 -1)", stats.Balance)
+	}
+	if stats.CurrentLossStreak != 1 || stats.MaxStreak != 1 {
+		t.Errorf("CurrentLossStreak, MaxStreak = %d, %d, want 1, 1", stats.CurrentLossStreak, stats.MaxStreak)
+	}
+
+	var count int
+	db.QueryRow(`SELECT COUNT(*) FROM spins WHERE chat_id = 100`).Scan(&count)
+	if count != 2 {
+		t.Errorf("ledger rows = %d, want 2", count)
+	}
+}
+
+func TestSpin_ReplayReportsDuplicate(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserStatsRepo(db)
+
+	s := domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 7, Cost: 1}
+	first, _ := repo.Spin(s)
+	second, err := repo.Spin(s)
+	if err != nil {
+		t.Fatalf("replayed Spin() error = %v", err)
+	}
+	if first.Duplicate {
+		t.Error("first spin should not be a duplicate")
+	}
+	if !second.Duplicate {
+		t.Error("replayed spin should be reported as a duplicate")
+	}
+}
+
+func TestSpin_ReplayDoesNotPayJackpotTwice(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserStatsRepo(db)
+	jackpots := NewJackpotRepo(db)
+
+	for i := int64(1); i <= 4; i++ {
+		repo.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: i, Cost: 10, JackpotShare: 50})
+	}
+	hit := domain.Spin{ChatId: 100, UserId: 2, Game: domain.GameSlot, Username: "bob", MessageId: 5, Value: 64, Payout: 64, Cost: 1, Jackpot: true}
+	first, _ := repo.Spin(hit)
+	// A loss fed back into the pool between the original update and its redelivery.
+	repo.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 6, Cost: 10, JackpotShare: 50})
+	second, _ := repo.Spin(hit)
+
+	if first.JackpotWon != 20 {
+		t.Errorf("first JackpotWon = %d, want 20", first.JackpotWon)
+	}
+	if second.JackpotWon != 0 {
+		t.Errorf("replayed JackpotWon = %d, want 0", second.JackpotWon)
+	}
+	bob, _ := repo.GetPersonalStats(100, 2, domain.GameSlot)
+	if bob.Balance != 83 {
+		t.Errorf("bob balance = %d, want 83 (64 + 20 - 1)", bob.Balance)
+	}
+	pool, _ := jackpots.GetPool(100)
+	if pool != 5 {
+		t.Errorf("pool = %d, want 5", pool)
+	}
+}
+
+func TestSpin_SameMessageIdInDifferentChats(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserStatsRepo(db)
+
+	repo.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 7, Cost: 1})
+	result, err := repo.Spin(domain.Spin{ChatId: 200, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 7, Cost: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Duplicate {
+		t.Error("same message id in another chat is a different spin")
+	}
+	stats, _ := repo.GetPersonalStats(200, 1, domain.GameSlot)
+	if stats.Spins != 1 {
+		t.Errorf("chat 200 spins = %d, want 1", stats.Spins)
+	}
+}
+
+func TestSpin_DailyLimit(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserStatsRepo(db)
+
+	dayStart := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	base := domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", Cost: 1, DailyLimit: 2, DayStart: dayStart}
+
+	// Yesterday's spin does not count toward today's limit.
+	yesterday := base
+	yesterday.MessageId, yesterday.At = 1, dayStart.Add(-time.Hour)
+	repo.Spin(yesterday)
+
+	var results []domain.SpinResult
+	for i := int64(2); i <= 4; i++ {
+		s := base
+		s.MessageId, s.At = i, dayStart.Add(time.Duration(i)*time.Hour)
+		result, err := repo.Spin(s)
+		if err != nil {
+			t.Fatalf("Spin() error = %v", err)
+		}
+		results = append(results, result)
+	}
+
+	if results[0].OverQuota || results[1].OverQuota {
+		t.Error("first two spins of the day should be counted")
+	}
+	if !results[2].OverQuota {
+		t.Error("third spin of the day should be over quota")
+	}
+
+	stats, _ := repo.GetPersonalStats(100, 1, domain.GameSlot)
+	if stats.Spins != 3 || stats.Balance != -3 {
+		t.Errorf("Spins, Balance = %d, %d, want 3, -3", stats.Spins, stats.Balance)
+	}
+
+	used, err := repo.CountSpinsSince(100, 1, dayStart)
+	if err != nil {
+		t.Fatalf("CountSpinsSince() error = %v", err)
+	}
+	if used != 2 {
+		t.Errorf("spins today = %d, want 2 (rejected spin is not in the ledger)", used)
+	}
+}
+
+func TestSpin_DailyLimitCountsAllGames(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserStatsRepo(db)
+
+	dayStart := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	at := dayStart.Add(time.Hour)
+	repo.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameDarts, Username: "alice", MessageId: 1, At: at, Cost: 1, DailyLimit: 1, DayStart: dayStart})
+	result, _ := repo.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 2, At: at, Cost: 1, DailyLimit: 1, DayStart: dayStart})
+
+	if !result.OverQuota {
+		t.Error("the limit is shared by all games")
+	}
+}
+
 func TestSpin_UpdatesUsername(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
 	repo := NewUserStatsRepo(db)
 
-	repo.Spin(100, 1, "old_name", false, 64)
-	repo.Spin(100, 1, "new_name", false, 64)
+	spin(t, repo, 100, 1, "old_name", 0)
+	spin(t, repo, 100, 1, "new_name", 0)
 
-	stats, err := repo.GetRichStats(100)
+	stats, err := firstPage(repo, 100, domain.GameSlot, domain.RatingRich)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -115,11 +612,11 @@ func TestSpin_AccumulatesStats(t *testing.T) {
 	defer db.Close()
 	repo := NewUserStatsRepo(db)
 
-	repo.Spin(100, 1, "alice", false, 64) // loss: -1
-	repo.Spin(100, 1, "alice", false, 64) // loss: -1
-	repo.Spin(100, 1, "alice", true, 64)  // win: +64
+	spin(t, repo, 100, 1, "alice", 0)  // loss: -1
+	spin(t, repo, 100, 1, "alice", 0)  // loss: -1
+	spin(t, repo, 100, 1, "alice", 64) // win: +64
 
-	stats, err := repo.GetPersonalStats(100, 1)
+	stats, err := repo.GetPersonalStats(100, 1, domain.GameSlot)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -139,7 +636,7 @@ func TestGetPersonalStats_NoRows(t *testing.T) {
 	defer db.Close()
 	repo := NewUserStatsRepo(db)
 
-	_, err := repo.GetPersonalStats(100, 999)
+	_, err := repo.GetPersonalStats(100, 999, domain.GameSlot)
 	if err == nil {
 		t.Error("expected error for non-existent user")
 	}
@@ -150,36 +647,36 @@ func TestGetPersonalStats_Rank(t *testing.T) {
 	defer db.Close()
 	repo := NewUserStatsRepo(db)
 
-	repo.Spin(100, 1, "rich", true, 64)   // balance: 64
-	repo.Spin(100, 2, "mid", false, 64)   // balance: -1
-	repo.Spin(100, 3, "poor", false, 64)  // balance: -1
-	repo.Spin(100, 3, "poor", false, 64)  // balance: -2
+	spin(t, repo, 100, 1, "rich", 64) // balance: 64
+	spin(t, repo, 100, 2, "mid", 0)   // balance: -1
+	spin(t, repo, 100, 3, "poor", 0)  // balance: -1
+	spin(t, repo, 100, 3, "poor", 0)  // balance: -2
 
-	stats, _ := repo.GetPersonalStats(100, 1)
+	stats, _ := repo.GetPersonalStats(100, 1, domain.GameSlot)
 	if stats.Rank != 1 {
 		t.Errorf("rich user rank = %d, want 1", stats.Rank)
 	}
 
-	stats, _ = repo.GetPersonalStats(100, 2)
+	stats, _ = repo.GetPersonalStats(100, 2, domain.GameSlot)
 	if stats.Rank != 2 {
 		t.Errorf("mid user rank = %d, want 2", stats.Rank)
 	}
 
-	stats, _ = repo.GetPersonalStats(100, 3)
+	stats, _ = repo.GetPersonalStats(100, 3, domain.GameSlot)
 	if stats.Rank != 3 {
 		t.Errorf("poor user rank = %d, want 3", stats.Rank)
 	}
 }
 
-func TestGetRichStats(t *testing.T) {
+func TestGetRatingPage_Rich(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
 	repo := NewUserStatsRepo(db)
 
-	repo.Spin(100, 1, "alice", true, 64)  // balance: 64
-	repo.Spin(100, 2, "bob", false, 64)   // balance: -1
+	spin(t, repo, 100, 1, "alice", 64) // balance: 64
+	spin(t, repo, 100, 2, "bob", 0)    // balance: -1
 
-	stats, err := repo.GetRichStats(100)
+	stats, err := firstPage(repo, 100, domain.GameSlot, domain.RatingRich)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -197,15 +694,15 @@ func TestGetRichStats(t *testing.T) {
 	}
 }
 
-func TestGetDebtorsStats(t *testing.T) {
+func TestGetRatingPage_Debtors(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
 	repo := NewUserStatsRepo(db)
 
-	repo.Spin(100, 1, "alice", true, 64)  // balance: 64
-	repo.Spin(100, 2, "bob", false, 64)   // balance: -1
+	spin(t, repo, 100, 1, "alice", 64) // balance: 64
+	spin(t, repo, 100, 2, "bob", 0)    // balance: -1
 
-	stats, err := repo.GetDebtorsStats(100)
+	stats, err := firstPage(repo, 100, domain.GameSlot, domain.RatingDebtors)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -221,12 +718,12 @@ func TestGetDebtorsStats(t *testing.T) {
 	}
 }
 
-func TestGetRichStats_Empty(t *testing.T) {
+func TestGetRatingPage_Empty(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
 	repo := NewUserStatsRepo(db)
 
-	stats, err := repo.GetRichStats(100)
+	stats, err := firstPage(repo, 100, domain.GameSlot, domain.RatingRich)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -240,11 +737,11 @@ func TestChatIsolation(t *testing.T) {
 	defer db.Close()
 	repo := NewUserStatsRepo(db)
 
-	repo.Spin(100, 1, "alice", true, 64)
-	repo.Spin(200, 1, "alice", false, 64)
+	spin(t, repo, 100, 1, "alice", 64)
+	spin(t, repo, 200, 1, "alice", 0)
 
-	stats100, _ := repo.GetPersonalStats(100, 1)
-	stats200, _ := repo.GetPersonalStats(200, 1)
+	stats100, _ := repo.GetPersonalStats(100, 1, domain.GameSlot)
+	stats200, _ := repo.GetPersonalStats(200, 1, domain.GameSlot)
 
 	if stats100.Balance != 64 {
 		t.Errorf("chat 100 balance = %d, want 64", stats100.Balance)
@@ -254,16 +751,44 @@ func TestChatIsolation(t *testing.T) {
 	}
 }
 
+func TestGameIsolation(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserStatsRepo(db)
+
+	spin(t, repo, 100, 1, "alice", 64)
+	repo.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameDarts, Username: "alice", MessageId: 1001, Cost: 1})
+	repo.Spin(domain.Spin{ChatId: 100, UserId: 2, Game: domain.GameDarts, Username: "bob", MessageId: 1002, Payout: 5, Cost: 1})
+
+	slot, _ := repo.GetPersonalStats(100, 1, domain.GameSlot)
+	darts, _ := repo.GetPersonalStats(100, 1, domain.GameDarts)
+	if slot.Balance != 64 || slot.Spins != 1 {
+		t.Errorf("slot stats = %+v, want balance 64 and 1 spin", slot)
+	}
+	if darts.Balance != -1 || darts.Rank != 2 {
+		t.Errorf("darts stats = %+v, want balance -1 and rank 2", darts)
+	}
+
+	slotRating, _ := firstPage(repo, 100, domain.GameSlot, domain.RatingRich)
+	if len(slotRating) != 1 {
+		t.Errorf("slot rating has %d players, want 1", len(slotRating))
+	}
+	dartsRating, _ := firstPage(repo, 100, domain.GameDarts, domain.RatingRich)
+	if len(dartsRating) != 2 || dartsRating[0].Username != "bob" {
+		t.Errorf("darts rating = %+v, want bob first of 2", dartsRating)
+	}
+}
+
 func TestSpin_WinStreakTracking(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
 	repo := NewUserStatsRepo(db)
 
-	repo.Spin(100, 1, "alice", true, 64) // win streak: 1
-	repo.Spin(100, 1, "alice", true, 64) // win streak: 2
-	repo.Spin(100, 1, "alice", true, 64) // win streak: 3
+	spin(t, repo, 100, 1, "alice", 64) // win streak: 1
+	spin(t, repo, 100, 1, "alice", 64) // win streak: 2
+	spin(t, repo, 100, 1, "alice", 64) // win streak: 3
 
-	stats, _ := repo.GetPersonalStats(100, 1)
+	stats, _ := repo.GetPersonalStats(100, 1, domain.GameSlot)
 	if stats.CurrentStreak != 3 {
 		t.Errorf("CurrentStreak = %d, want 3", stats.CurrentStreak)
 	}
@@ -271,9 +796,9 @@ func TestSpin_WinStreakTracking(t *testing.T) {
 		t.Errorf("MaxStreak = %d, want 3", stats.MaxStreak)
 	}
 
-	repo.Spin(100, 1, "alice", false, 64) // loss resets win streak
+	spin(t, repo, 100, 1, "alice", 0) // loss resets win streak
 
-	stats, _ = repo.GetPersonalStats(100, 1)
+	stats, _ = repo.GetPersonalStats(100, 1, domain.GameSlot)
 	if stats.CurrentStreak != 0 {
 		t.Errorf("CurrentStreak after loss = %d, want 0", stats.CurrentStreak)
 	}
@@ -287,12 +812,12 @@ func TestSpin_LossStreakTracking(t *testing.T) {
 	defer db.Close()
 	repo := NewUserStatsRepo(db)
 
-	repo.Spin(100, 1, "alice", false, 64) // loss streak: 1
-	repo.Spin(100, 1, "alice", false, 64) // loss streak: 2
-	repo.Spin(100, 1, "alice", false, 64) // loss streak: 3
-	repo.Spin(100, 1, "alice", false, 64) // loss streak: 4
+	spin(t, repo, 100, 1, "alice", 0) // loss streak: 1
+	spin(t, repo, 100, 1, "alice", 0) // loss streak: 2
+	spin(t, repo, 100, 1, "alice", 0) // loss streak: 3
+	spin(t, repo, 100, 1, "alice", 0) // loss streak: 4
 
-	stats, _ := repo.GetPersonalStats(100, 1)
+	stats, _ := repo.GetPersonalStats(100, 1, domain.GameSlot)
 	if stats.CurrentLossStreak != 4 {
 		t.Errorf("CurrentLossStreak = %d, want 4", stats.CurrentLossStreak)
 	}
@@ -300,9 +825,9 @@ func TestSpin_LossStreakTracking(t *testing.T) {
 		t.Errorf("MaxLossStreak = %d, want 4", stats.MaxLossStreak)
 	}
 
-	repo.Spin(100, 1, "alice", true, 64) // win resets loss streak
+	spin(t, repo, 100, 1, "alice", 64) // win resets loss streak
 
-	stats, _ = repo.GetPersonalStats(100, 1)
+	stats, _ = repo.GetPersonalStats(100, 1, domain.GameSlot)
 	if stats.CurrentLossStreak != 0 {
 		t.Errorf("CurrentLossStreak after win = %d, want 0", stats.CurrentLossStreak)
 	}
@@ -311,24 +836,34 @@ func TestSpin_LossStreakTracking(t *testing.T) {
 	}
 }
 
-func TestResetChat(t *testing.T) {
+func TestSpin_FreeWinsLedgerCost(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
 	repo := NewUserStatsRepo(db)
 
-	repo.Spin(100, 1, "alice", true, 64)
-	repo.Spin(100, 2, "bob", false, 64)
+	spin(t, repo, 100, 1, "alice", 0)
+	spin(t, repo, 100, 1, "alice", 64)
 
-	err := repo.ResetChat(100)
-	if err != nil {
-		t.Fatalf("ResetChat() error = %v", err)
+	// the ledger keeps what was charged, so period ratings match the balance
+	var net int64
+	db.QueryRow(`SELECT SUM(payout - cost) FROM spins WHERE chat_id = 100`).Scan(&net)
+	stats, _ := repo.GetPersonalStats(100, 1, domain.GameSlot)
+	if net != 63 || stats.Balance != 63 {
+		t.Errorf("ledger net, balance = %d, %d, want 63, 63 (-1 +64)", net, stats.Balance)
 	}
+}
 
-	stats, err := repo.GetRichStats(100)
-	if err != nil {
-		t.Fatal(err)
+func TestIsCounted(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserStatsRepo(db)
+
+	repo.Spin(domain.Spin{ChatId: 100, UserId: 1, Game: domain.GameSlot, Username: "alice", MessageId: 7, Cost: 1})
+
+	if counted, err := repo.IsCounted(100, 7); err != nil || !counted {
+		t.Errorf("IsCounted(100, 7) = %v, %v, want true", counted, err)
 	}
-	if len(stats) != 0 {
-		t.Errorf("expected 0 users after reset, got %d", len(stats))
+	if counted, _ := repo.IsCounted(200, 7); counted {
+		t.Error("message of another chat is counted")
 	}
 }
@@ -13,10 +13,17 @@ import (
 )
 
 type Scheduler struct {
-	cache   *cache.SlotMessageCache
-	cleaner *service.MessageCleaner
-	bot     *gotgbot.Bot
-	loc     *time.Location
+	cache       *cache.SlotMessageCache
+	throttle    *cache.SpinThrottle
+	cleaner     *service.MessageCleaner
+	seasons     *service.ResetService
+	bets        *service.BetService
+	duels       *service.DuelService
+	tournaments *service.TournamentService
+	lottery     *service.LotteryService
+	loans       *service.LoanService
+	bot         *gotgbot.Bot
+	loc         *time.Location
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -25,18 +32,32 @@ type Scheduler struct {
 
 func NewScheduler(
 	cache *cache.SlotMessageCache,
+	throttle *cache.SpinThrottle,
 	cleaner *service.MessageCleaner,
+	seasons *service.ResetService,
+	bets *service.BetService,
+	duels *service.DuelService,
+	tournaments *service.TournamentService,
+	lottery *service.LotteryService,
+	loans *service.LoanService,
 	bot *gotgbot.Bot,
 	loc *time.Location,
 ) *Scheduler {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Scheduler{
-		cache:   cache,
-		cleaner: cleaner,
-		bot:     bot,
-		loc:     loc,
-		ctx:     ctx,
-		cancel:  cancel,
+		cache:       cache,
+		throttle:    throttle,
+		cleaner:     cleaner,
+		seasons:     seasons,
+		bets:        bets,
+		duels:       duels,
+		tournaments: tournaments,
+		lottery:     lottery,
+		loans:       loans,
+		bot:         bot,
+		loc:         loc,
+		ctx:         ctx,
+		cancel:      cancel,
 	}
 }
 
@@ -57,6 +78,7 @@ func (s *Scheduler) loop() {
 	defer ticker.Stop()
 
 	var lastCleanupMinute int64 = -1
+	var lastSeasonMinute int64 = -1
 	var lastReportDay int = -1
 
 	for {
@@ -68,12 +90,28 @@ func (s *Scheduler) loop() {
 			now := nowUTC.In(s.loc)
 
 			minuteKey := now.Unix() / 60
+
+			// ---------- Every minute: season rollovers, expired duels, finished tournaments, lottery draws, loan interest ----------
+			if minuteKey != lastSeasonMinute {
+				lastSeasonMinute = minuteKey
+				s.seasons.RollOverSeasons(s.bot, nowUTC)
+				s.duels.ExpireDuels(s.bot, nowUTC)
+				s.tournaments.FinishDue(s.bot, nowUTC)
+				s.lottery.RunDraws(s.bot, nowUTC)
+				s.loans.AccrueInterest(nowUTC)
+			}
+
+			// ---------- Live tournament standings: every tick ----------
+			s.tournaments.RefreshBoards(s.bot)
+
 			if (now.Minute() == 0 || now.Minute() == 30) &&
 				now.Second() < 30 &&
 				minuteKey != lastCleanupMinute {
 
 				lastCleanupMinute = minuteKey
 				s.runCleanup()
+				s.throttle.Prune(nowUTC)
+				s.bets.PruneExpired(nowUTC)
 			}
 
 			// ---------- Daily report: 12:00 ----------
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"bandit-counter-bot/internal/repository"
+	"fmt"
+	"strings"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+type AchievementService struct {
+	repo         *repository.AchievementRepo
+	statsRepo    *repository.UserStatsRepo
+	settingsRepo *repository.SettingsRepo
+}
+
+func NewAchievementService(repo *repository.AchievementRepo, statsRepo *repository.UserStatsRepo, settingsRepo *repository.SettingsRepo) *AchievementService {
+	return &AchievementService{repo: repo, statsRepo: statsRepo, settingsRepo: settingsRepo}
+}
+
+// CheckSpin unlocks what a counted spin has earned and announces the new
+// achievements in reply to it.
+func (s *AchievementService) CheckSpin(b *gotgbot.Bot, msg *gotgbot.Message, spin domain.Spin) error {
+	stats, err := s.statsRepo.GetPersonalStats(spin.ChatId, spin.UserId, spin.Game)
+	if err != nil {
+		return err
+	}
+	unlocked, err := s.repo.Unlock(spin.ChatId, spin.UserId, domain.EarnedAchievements(spin, stats), spin.At)
+	if err != nil || len(unlocked) == 0 {
+		return err
+	}
+
+	var builder strings.Builder
+	if len(unlocked) == 1 {
+		fmt.Fprintf(&builder, "🏅 %s відкриває ачивку:", spin.Username)
+	} else {
+		fmt.Fprintf(&builder, "🏅 %s відкриває ачивки:", spin.Username)
+	}
+	for _, key := range unlocked {
+		if info, ok := domain.AchievementByKey(key); ok {
+			fmt.Fprintf(&builder, "\n%s — %s", info.Title, info.Hint)
+		}
+	}
+	_, _ = msg.Reply(b, builder.String(), &gotgbot.SendMessageOpts{})
+	return nil
+}
+
+// Count returns how many achievements the player has in the chat.
+func (s *AchievementService) Count(chatId int64, userId int64) (int, error) {
+	return s.repo.CountAchievements(chatId, userId)
+}
+
+func (s *AchievementService) HandleAchievementsCommand(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+	from := messageSender(msg)
+	unlocked, err := s.repo.GetAchievements(msg.Chat.Id, from.id)
+	if err != nil {
+		return err
+	}
+	loc, err := chatLocation(s.settingsRepo, msg.Chat.Id)
+	if err != nil {
+		return err
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "🏅 Ачивки %s: %d з %d\n", from.name, len(unlocked), len(domain.Achievements))
+	for _, a := range domain.Achievements {
+		line := fmt.Sprintf("\n🔒 %s — %s", a.Title, a.Hint)
+		for _, u := range unlocked {
+			if u.Key == a.Key {
+				line = fmt.Sprintf("\n✅ %s — %s (%s)", a.Title, a.Hint, u.UnlockedAt.In(loc).Format("02.01.06"))
+				break
+			}
+		}
+		builder.WriteString(line)
+	}
+	_, _ = msg.Reply(b, builder.String(), &gotgbot.SendMessageOpts{})
+	return nil
+}
@@ -54,3 +54,50 @@ func (a *AuthService) CanPerform(b *gotgbot.Bot, chatId int64, userId int64, act
 	}
 	return allowed
 }
+
+// MessageActor returns the id the user's messages in the chat are counted for,
+// see messageSender: an admin who stays anonymous writes as the chat itself.
+// Callback queries always carry the real user, so questions asked from a
+// button are keyed on this to match the answer.
+func (a *AuthService) MessageActor(b *gotgbot.Bot, chatId int64, userId int64) int64 {
+	member, err := b.GetChatMember(chatId, userId, nil)
+	if err != nil {
+		return userId
+	}
+	status := member.GetStatus()
+	if (status == "creator" || status == "administrator") && member.MergeChatMember().IsAnonymous {
+		return chatId
+	}
+	return userId
+}
+
+// IsAnonymousAdmin reports whether the message was sent by an admin posting
+// anonymously: Telegram then sets SenderChat to the group itself.
+func (a *AuthService) IsAnonymousAdmin(msg *gotgbot.Message) bool {
+	return msg.SenderChat != nil && msg.SenderChat.Id == msg.Chat.Id
+}
+
+// IsAdminMessage is IsAdmin for the author of a message. Messages sent on behalf
+// of a channel are never admin ones, their From is a shared service account.
+func (a *AuthService) IsAdminMessage(b *gotgbot.Bot, msg *gotgbot.Message) bool {
+	if a.IsAnonymousAdmin(msg) {
+		return true
+	}
+	if msg.SenderChat != nil {
+		return false
+	}
+	return a.IsAdmin(b, msg.Chat.Id, msg.From.Id)
+}
+
+// CanPerformMessage is CanPerform for the author of a message. A channel
+// posting in the chat only gets what the chat allows to everybody.
+func (a *AuthService) CanPerformMessage(b *gotgbot.Bot, msg *gotgbot.Message, action string) bool {
+	if a.IsAnonymousAdmin(msg) {
+		return true
+	}
+	if msg.SenderChat != nil {
+		allowed, err := a.settingsRepo.GetPermission(msg.Chat.Id, action)
+		return err == nil && allowed
+	}
+	return a.CanPerform(b, msg.Chat.Id, msg.From.Id, action)
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"bandit-counter-bot/internal/repository"
+	"fmt"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+type BailoutService struct {
+	repo         *repository.BailoutRepo
+	settingsRepo *repository.SettingsRepo
+}
+
+func NewBailoutService(repo *repository.BailoutRepo, settingsRepo *repository.SettingsRepo) *BailoutService {
+	return &BailoutService{repo: repo, settingsRepo: settingsRepo}
+}
+
+// HandleBailoutCommand writes off the coin debt of a player who is deep enough
+// in it, at most once per the chat's cooldown.
+func (s *BailoutService) HandleBailoutCommand(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+	from := messageSender(msg)
+
+	policy, err := s.settingsRepo.GetBailout(msg.Chat.Id)
+	if err != nil {
+		return err
+	}
+	if !policy.Enabled() {
+		_, _ = msg.Reply(b, "🆘 Банкрутство в цьому чаті вимкнено", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	at := msgTime(msg)
+	result, err := s.repo.Bailout(msg.Chat.Id, from.id, policy, at)
+	if err != nil {
+		return err
+	}
+
+	var text string
+	switch {
+	case result.Granted:
+		text = fmt.Sprintf("🆘 %s оголошує банкрутство. Борг %d списано, баланс %s обнулено",
+			from.name, result.Debt, domain.CoinGame.Emoji())
+		if result.Count > 1 {
+			text += fmt.Sprintf("\n🤡 Це вже %d-е банкрутство", result.Count)
+		}
+	case !result.RetryAt.IsZero():
+		text = fmt.Sprintf("⏳ %s, наступне банкрутство можна оформити через %s",
+			from.name, formatWait(result.RetryAt.Sub(at)))
+	default:
+		text = fmt.Sprintf("🆘 %s, банкрутство оформлюють з боргу від %d, а в тебе баланс %d",
+			from.name, -policy.Threshold, result.Debt)
+	}
+	_, _ = msg.Reply(b, text, &gotgbot.SendMessageOpts{})
+	return nil
+}
+
+// Count returns how many bailouts the player has had in the chat.
+func (s *BailoutService) Count(chatId int64, userId int64) (int64, error) {
+	return s.repo.CountBailouts(chatId, userId)
+}
+
+// Counts returns the bailout counts of several players; those without any are left out.
+func (s *BailoutService) Counts(chatId int64, userIds []int64) (map[int64]int64, error) {
+	return s.repo.CountBailoutsOf(chatId, userIds)
+}
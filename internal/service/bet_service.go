@@ -0,0 +1,102 @@
+package service
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"bandit-counter-bot/internal/repository"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// betTTL is how long a bet waits for the player's next 🎰.
+const betTTL = 5 * time.Minute
+
+type BetService struct {
+	repo         *repository.BetRepo
+	settingsRepo *repository.SettingsRepo
+}
+
+func NewBetService(repo *repository.BetRepo, settingsRepo *repository.SettingsRepo) *BetService {
+	return &BetService{repo: repo, settingsRepo: settingsRepo}
+}
+
+// HandleBetCommand places a stake on the player's next 🎰: /bet 50 bets,
+// /bet 0 takes the bet back, a bare /bet shows the pending one.
+func (s *BetService) HandleBetCommand(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+	from := messageSender(msg)
+
+	limits, err := s.settingsRepo.GetBetLimits(msg.Chat.Id)
+	if err != nil {
+		return err
+	}
+	if !limits.Enabled() {
+		_, _ = msg.Reply(b, "🎰 Ставки в цьому чаті вимкнено", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	at := msgTime(msg)
+	args := strings.Fields(msg.Text)[1:]
+	if len(args) == 0 {
+		bet, ok, err := s.repo.GetBet(msg.Chat.Id, from.id, at)
+		if err != nil {
+			return err
+		}
+		text := fmt.Sprintf("🎰 Ставка на наступну крутілку: /bet сума, від %d до %d", limits.Min, limits.Max)
+		if ok {
+			text = fmt.Sprintf("🎰 %s, твоя ставка %d чекає на крутілку ще %s. Забрати: /bet 0",
+				from.name, bet.Stake, formatWait(bet.ExpiresAt.Sub(at)))
+		}
+		_, _ = msg.Reply(b, text, &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	stake, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		_, _ = msg.Reply(b, "🎰 Напиши суму числом, наприклад /bet 50", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+	if stake == 0 {
+		cancelled, err := s.repo.CancelBet(msg.Chat.Id, from.id)
+		if err != nil {
+			return err
+		}
+		text := "🎰 Ставки й так не було"
+		if cancelled {
+			text = fmt.Sprintf("🎰 %s забирає ставку", from.name)
+		}
+		_, _ = msg.Reply(b, text, &gotgbot.SendMessageOpts{})
+		return nil
+	}
+	if !limits.Allows(stake) {
+		_, _ = msg.Reply(b, fmt.Sprintf("🎰 Ставка має бути від %d до %d", limits.Min, limits.Max),
+			&gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	err = s.repo.PlaceBet(domain.Bet{
+		ChatId:    msg.Chat.Id,
+		UserId:    from.id,
+		Stake:     stake,
+		ExpiresAt: at.Add(betTTL),
+	})
+	if err != nil {
+		return err
+	}
+	text := fmt.Sprintf("🎰 %s ставить %d на наступну крутілку. Ставка чекає %d хв",
+		from.name, stake, int(betTTL.Minutes()))
+	_, _ = msg.Reply(b, text, &gotgbot.SendMessageOpts{})
+	return nil
+}
+
+// PruneExpired drops bets nobody spun for in time.
+func (s *BetService) PruneExpired(now time.Time) {
+	if _, err := s.repo.DeleteExpired(now); err != nil {
+		log.Printf("prune expired bets: %v", err)
+	}
+}
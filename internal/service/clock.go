@@ -0,0 +1,54 @@
+package service
+
+import (
+	"bandit-counter-bot/internal/repository"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+var locations sync.Map // map[string]*time.Location
+
+func loadLocation(name string) (*time.Location, error) {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// chatLocation returns the chat's configured timezone, falling back to UTC
+// when the stored name cannot be loaded on this host.
+func chatLocation(settingsRepo *repository.SettingsRepo, chatId int64) (*time.Location, error) {
+	name, err := settingsRepo.GetTimezone(chatId)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := loadLocation(name)
+	if err != nil {
+		log.Printf("timezone %s of chat %d not found, using UTC: %v", name, chatId, err)
+		return time.UTC, nil
+	}
+	return loc, nil
+}
+
+// formatWait renders a wait as days and hours, or hours and minutes when it
+// is shorter than a day.
+func formatWait(d time.Duration) string {
+	minutes := int64(d.Round(time.Minute).Minutes())
+	switch {
+	case minutes < 60:
+		return fmt.Sprintf("%d хв", max(minutes, 1))
+	case minutes < 24*60:
+		return fmt.Sprintf("%d год %d хв", minutes/60, minutes%60)
+	case minutes%(24*60) < 60:
+		return fmt.Sprintf("%d дн", minutes/(24*60))
+	default:
+		return fmt.Sprintf("%d дн %d год", minutes/(24*60), minutes%(24*60)/60)
+	}
+}
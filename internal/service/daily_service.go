@@ -0,0 +1,59 @@
+package service
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"bandit-counter-bot/internal/repository"
+	"fmt"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+type DailyService struct {
+	repo         *repository.DailyRepo
+	settingsRepo *repository.SettingsRepo
+}
+
+func NewDailyService(repo *repository.DailyRepo, settingsRepo *repository.SettingsRepo) *DailyService {
+	return &DailyService{repo: repo, settingsRepo: settingsRepo}
+}
+
+// HandleDailyCommand pays the daily bonus once per day in the chat's timezone.
+func (s *DailyService) HandleDailyCommand(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+	from := messageSender(msg)
+
+	base, err := s.settingsRepo.GetDailyBonus(msg.Chat.Id)
+	if err != nil {
+		return err
+	}
+	if base == 0 {
+		_, _ = msg.Reply(b, "🎁 Щоденний бонус у цьому чаті вимкнено", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+	loc, err := chatLocation(s.settingsRepo, msg.Chat.Id)
+	if err != nil {
+		return err
+	}
+
+	at := msgTime(msg)
+	today := domain.StartOfDay(at, loc)
+	claim, ok, err := s.repo.Claim(msg.Chat.Id, from.id, from.name, today, base, at)
+	if err != nil {
+		return err
+	}
+
+	var text string
+	if ok {
+		text = fmt.Sprintf("🎁 %s забирає щоденний бонус: +%d %s", from.name, claim.Amount, domain.CoinGame.Emoji())
+		if claim.Streak > 1 {
+			text += fmt.Sprintf("\n🔥 Днів поспіль: %d", claim.Streak)
+		}
+	} else {
+		wait := today.AddDate(0, 0, 1).Sub(at)
+		text = fmt.Sprintf("⏳ %s, бонус на сьогодні вже забрано (+%d). Наступний через %s",
+			from.name, claim.Amount, formatWait(wait))
+	}
+	_, _ = msg.Reply(b, text, &gotgbot.SendMessageOpts{})
+	return nil
+}
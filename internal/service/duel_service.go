@@ -0,0 +1,244 @@
+package service
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"bandit-counter-bot/internal/repository"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+const (
+	// duelOfferTTL is how long a challenge waits to be accepted.
+	duelOfferTTL = 5 * time.Minute
+	// duelRollWindow is how long the duelists have to spin once the duel starts.
+	duelRollWindow = 3 * time.Minute
+)
+
+type DuelService struct {
+	repo         *repository.DuelRepo
+	settingsRepo *repository.SettingsRepo
+}
+
+func NewDuelService(repo *repository.DuelRepo, settingsRepo *repository.SettingsRepo) *DuelService {
+	return &DuelService{repo: repo, settingsRepo: settingsRepo}
+}
+
+// HandleDuelCommand challenges the author of the replied message; an optional
+// stake within the chat's bet limits goes to the winner.
+func (s *DuelService) HandleDuelCommand(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+	from := messageSender(msg)
+	reply := msg.ReplyToMessage
+	if reply == nil || reply.ForumTopicCreated != nil {
+		_, _ = msg.Reply(b, "⚔️ Щоб викликати на дуель, відповідай /duel на повідомлення суперника. Ставка за бажанням: /duel 50",
+			&gotgbot.SendMessageOpts{})
+		return nil
+	}
+	opponent := messageSender(reply)
+	if from.isChat || opponent.isChat || reply.From != nil && reply.From.IsBot {
+		_, _ = msg.Reply(b, "⚔️ Дуелі тільки між живими гравцями", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+	if opponent.id == from.id {
+		_, _ = msg.Reply(b, "⚔️ Дуель із собою? Сміливо, але ні", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	var stake int64
+	if args := strings.Fields(msg.Text)[1:]; len(args) > 0 {
+		n, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil || n < 0 {
+			_, _ = msg.Reply(b, "⚔️ Ставку пиши числом, наприклад /duel 50", &gotgbot.SendMessageOpts{})
+			return nil
+		}
+		stake = n
+	}
+	if stake > 0 {
+		limits, err := s.settingsRepo.GetBetLimits(msg.Chat.Id)
+		if err != nil {
+			return err
+		}
+		if !limits.Enabled() || !limits.Allows(stake) {
+			text := "⚔️ Ставки в цьому чаті вимкнено, тож дуель тільки на інтерес"
+			if limits.Enabled() {
+				text = fmt.Sprintf("⚔️ Ставка має бути від %d до %d", limits.Min, limits.Max)
+			}
+			_, _ = msg.Reply(b, text, &gotgbot.SendMessageOpts{})
+			return nil
+		}
+	}
+
+	id, err := s.repo.CreateDuel(domain.Duel{
+		ChatId:         msg.Chat.Id,
+		ChallengerId:   from.id,
+		ChallengerName: from.name,
+		OpponentId:     opponent.id,
+		OpponentName:   opponent.name,
+		Stake:          stake,
+		CreatedAt:      msgTime(msg),
+	})
+	if errors.Is(err, repository.ErrDuelBusy) {
+		_, _ = msg.Reply(b, "⚔️ Хтось із вас уже в дуелі, спершу добийтеся там", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	text := fmt.Sprintf("⚔️ %s викликає %s на дуель", from.name, opponent.name)
+	if stake > 0 {
+		text += fmt.Sprintf(" на %d %s", stake, domain.CoinGame.Emoji())
+	}
+	text += fmt.Sprintf("!\nВиклик діє %d хв", int(duelOfferTTL.Minutes()))
+	keyboard := gotgbot.InlineKeyboardMarkup{
+		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{
+			{Text: "⚔️ Прийняти", CallbackData: fmt.Sprintf("duel:%d:accept", id)},
+			{Text: "🏳️ Відмовитись", CallbackData: fmt.Sprintf("duel:%d:decline", id)},
+		}},
+	}
+	_, _ = msg.Reply(b, text, &gotgbot.SendMessageOpts{ReplyMarkup: keyboard})
+	return nil
+}
+
+// HandleDuelCallback lets the challenged player accept, and either duelist
+// call the challenge off while it is still open.
+func (s *DuelService) HandleDuelCallback(b *gotgbot.Bot, ctx *ext.Context) error {
+	cb := ctx.CallbackQuery
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) < 3 {
+		cb.Answer(b, nil)
+		return nil
+	}
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		cb.Answer(b, nil)
+		return nil
+	}
+
+	duel, err := s.repo.GetDuel(id)
+	if err != nil {
+		cb.Answer(b, nil)
+		return err
+	}
+	action := parts[2]
+	if action == "accept" && cb.From.Id != duel.OpponentId || !duel.Involves(cb.From.Id) {
+		cb.Answer(b, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "це не тебе викликали",
+		})
+		return nil
+	}
+
+	now := time.Now()
+	var text string
+	switch {
+	case action != "accept":
+		err = s.repo.Cancel(id)
+		text = fmt.Sprintf("🏳️ Дуелі %s проти %s не буде", duel.ChallengerName, duel.OpponentName)
+	case now.Sub(duel.CreatedAt) > duelOfferTTL:
+		err = s.repo.Cancel(id)
+		text = "⌛ Виклик на дуель протух"
+	default:
+		err = s.repo.Accept(id, now)
+		text = fmt.Sprintf("⚔️ Дуель: %s проти %s!\nКожен кидає одну 🎰 протягом %d хв, вищий виграш забирає",
+			duel.ChallengerName, duel.OpponentName, int(duelRollWindow.Minutes()))
+		if duel.Stake > 0 {
+			text += fmt.Sprintf(" %d %s", duel.Stake, domain.CoinGame.Emoji())
+		} else {
+			text += " славу"
+		}
+	}
+	if errors.Is(err, repository.ErrDuelClosed) {
+		cb.Answer(b, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "цей виклик уже закрито",
+		})
+		return nil
+	}
+	if err != nil {
+		cb.Answer(b, nil)
+		return err
+	}
+
+	_, _, _ = cb.Message.EditText(b, text, &gotgbot.EditMessageTextOpts{})
+	cb.Answer(b, nil)
+	return nil
+}
+
+// AnnounceRoll announces the outcome of the duel a 🎰 rolled in once both
+// duelists have rolled; the roll itself is recorded by the spin.
+func (s *DuelService) AnnounceRoll(b *gotgbot.Bot, msg *gotgbot.Message, result domain.SpinResult) {
+	if !result.DuelRolled || result.Duel.Status != domain.DuelDone {
+		return
+	}
+	_, _ = msg.Reply(b, formatDuelResult(result.Duel), &gotgbot.SendMessageOpts{})
+}
+
+// ExpireDuels closes the duels that ran out of time and announces how the
+// started ones ended.
+func (s *DuelService) ExpireDuels(b *gotgbot.Bot, now time.Time) {
+	duels, err := s.repo.ExpireDuels(now, duelOfferTTL, duelRollWindow)
+	if err != nil {
+		log.Printf("failed to expire duels: %v", err)
+		return
+	}
+	for _, d := range duels {
+		text := formatDuelResult(d)
+		if d.Status == domain.DuelCancelled {
+			text = fmt.Sprintf("⌛ Дуель %s проти %s скасовано: ніхто не крутнув", d.ChallengerName, d.OpponentName)
+		}
+		if _, err := b.SendMessage(d.ChatId, text, nil); err != nil {
+			log.Printf("failed to send duel result to chat %d: %v", d.ChatId, err)
+		}
+	}
+}
+
+// Record returns the player's duel wins and losses.
+func (s *DuelService) Record(chatId int64, userId int64) (int64, int64, error) {
+	return s.repo.GetDuelRecord(chatId, userId)
+}
+
+// RatingPage returns a page of the chat's duelists leaderboard.
+func (s *DuelService) RatingPage(chatId int64, limit, offset int) ([]domain.DuelStats, error) {
+	return s.repo.GetDuelRatingPage(chatId, limit, offset)
+}
+
+// CountRating returns how many players the duelists leaderboard has.
+func (s *DuelService) CountRating(chatId int64) (int, error) {
+	return s.repo.CountDuelRating(chatId)
+}
+
+// RatingEntry returns the player's line of the duelists leaderboard and its
+// zero-based position.
+func (s *DuelService) RatingEntry(chatId int64, userId int64) (domain.DuelStats, int, bool, error) {
+	return s.repo.GetDuelRatingEntry(chatId, userId)
+}
+
+func formatDuelResult(d domain.Duel) string {
+	roll := func(payout int64) string {
+		if payout == domain.DuelNotRolled {
+			return "—"
+		}
+		return strconv.FormatInt(payout, 10)
+	}
+	text := fmt.Sprintf("⚔️ %s %s : %s %s\n",
+		d.ChallengerName, roll(d.ChallengerPayout), roll(d.OpponentPayout), d.OpponentName)
+
+	winner := d.ChallengerName
+	if d.WinnerId == d.OpponentId {
+		winner = d.OpponentName
+	}
+	switch {
+	case d.WinnerId == 0:
+		return text + "🤝 Нічия"
+	case d.Stake > 0:
+		return text + fmt.Sprintf("🏆 Перемагає %s і забирає %d %s", winner, d.Stake, domain.CoinGame.Emoji())
+	default:
+		return text + fmt.Sprintf("🏆 Перемагає %s", winner)
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"fmt"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+var gameLabels = map[domain.Game]string{
+	domain.GameSlot:       "Крутілка",
+	domain.GameDice:       "Кубик",
+	domain.GameDarts:      "Дартс",
+	domain.GameBasketball: "Баскетбол",
+	domain.GameFootball:   "Футбол",
+	domain.GameBowling:    "Боулінг",
+}
+
+func containsGame(games []domain.Game, game domain.Game) bool {
+	for _, g := range games {
+		if g == game {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultGame is the game /me and /stats open with.
+func defaultGame(enabled []domain.Game) domain.Game {
+	if len(enabled) == 0 || containsGame(enabled, domain.GameSlot) {
+		return domain.GameSlot
+	}
+	return enabled[0]
+}
+
+// buildGameRow renders a game selector; callbackFormat receives the game key.
+// A single game needs no selector, so nil is returned.
+func buildGameRow(enabled []domain.Game, active domain.Game, callbackFormat string) []gotgbot.InlineKeyboardButton {
+	if len(enabled) < 2 {
+		return nil
+	}
+	var buttons []gotgbot.InlineKeyboardButton
+	for _, g := range domain.Games {
+		if !containsGame(enabled, g) {
+			continue
+		}
+		label := g.Emoji()
+		if g == active {
+			label = "✅ " + label
+		}
+		buttons = append(buttons, gotgbot.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf(callbackFormat, g),
+		})
+	}
+	return buttons
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"bandit-counter-bot/internal/repository"
+	"fmt"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+type LevelService struct {
+	repo         *repository.LevelRepo
+	settingsRepo *repository.SettingsRepo
+}
+
+func NewLevelService(repo *repository.LevelRepo, settingsRepo *repository.SettingsRepo) *LevelService {
+	return &LevelService{repo: repo, settingsRepo: settingsRepo}
+}
+
+// AnnounceLevelUp congratulates the player in reply to their spin when the
+// experience it earned took them to a new level.
+func (s *LevelService) AnnounceLevelUp(b *gotgbot.Bot, msg *gotgbot.Message, spin domain.Spin, result domain.SpinResult) error {
+	curve, err := s.curve(spin.ChatId)
+	if err != nil {
+		return err
+	}
+
+	level := curve.Level(result.XP)
+	if level == curve.Level(result.XP-result.XPGained) {
+		return nil
+	}
+	text := fmt.Sprintf("🎉 %s тепер %s %d рівня!", spin.Username, domain.LevelIcon(level), level)
+	_, _ = msg.Reply(b, text, &gotgbot.SendMessageOpts{})
+	return nil
+}
+
+// LevelLine describes the player's level and the progress to the next one for /me.
+func (s *LevelService) LevelLine(chatId int64, userId int64) (string, error) {
+	xp, err := s.repo.GetXP(chatId, userId)
+	if err != nil {
+		return "", err
+	}
+	curve, err := s.curve(chatId)
+	if err != nil {
+		return "", err
+	}
+	level, gained, needed := curve.Progress(xp)
+	return fmt.Sprintf("%s Рівень %d: %d/%d XP", domain.LevelIcon(level), level, gained, needed), nil
+}
+
+// Levels returns the levels of several players; those who never played are level 1.
+func (s *LevelService) Levels(chatId int64, userIds []int64) (map[int64]int, error) {
+	xps, err := s.repo.GetXPs(chatId, userIds)
+	if err != nil {
+		return nil, err
+	}
+	curve, err := s.curve(chatId)
+	if err != nil {
+		return nil, err
+	}
+	levels := make(map[int64]int, len(userIds))
+	for _, id := range userIds {
+		levels[id] = curve.Level(xps[id])
+	}
+	return levels, nil
+}
+
+func (s *LevelService) curve(chatId int64) (domain.LevelCurve, error) {
+	key, err := s.settingsRepo.GetLevelCurve(chatId)
+	if err != nil {
+		return domain.LevelCurve{}, err
+	}
+	return domain.LevelCurveByKey(key), nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"bandit-counter-bot/internal/repository"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// LoanService lends coins at a daily interest; the debt is paid back from
+// the player's 🎰 wins or by hand.
+type LoanService struct {
+	repo         *repository.LoanRepo
+	settingsRepo *repository.SettingsRepo
+}
+
+func NewLoanService(repo *repository.LoanRepo, settingsRepo *repository.SettingsRepo) *LoanService {
+	return &LoanService{repo: repo, settingsRepo: settingsRepo}
+}
+
+// HandleLoanCommand takes a loan with "/loan N", pays it off from the balance
+// with "/loan repay" and shows the player's debt or the terms otherwise.
+func (s *LoanService) HandleLoanCommand(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+	from := messageSender(msg)
+	args := strings.Fields(msg.Text)[1:]
+
+	if len(args) > 0 && strings.ToLower(args[0]) == "repay" {
+		return s.repay(b, msg, from)
+	}
+
+	terms, err := s.settingsRepo.GetLoanTerms(msg.Chat.Id)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return s.sendLoan(b, msg, from, terms)
+	}
+	if !terms.Enabled() {
+		_, _ = msg.Reply(b, "🏦 Кредити в цьому чаті вимкнено", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	amount, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || amount < 1 || amount > terms.Max {
+		_, _ = msg.Reply(b, fmt.Sprintf("🏦 Суму кредиту пиши числом від 1 до %d, наприклад /loan 100", terms.Max),
+			&gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	_, err = s.repo.TakeLoan(msg.Chat.Id, from.id, from.name, amount, terms.Rate, msgTime(msg))
+	if errors.Is(err, repository.ErrLoanOpen) {
+		loan, _, err := s.repo.GetLoan(msg.Chat.Id, from.id)
+		if err != nil {
+			return err
+		}
+		_, _ = msg.Reply(b, fmt.Sprintf("🏦 %s, спершу поверни попередній кредит: борг %d", from.name, loan.Debt),
+			&gotgbot.SendMessageOpts{})
+		return nil
+	}
+	if errors.Is(err, repository.ErrNotInDebt) {
+		_, _ = msg.Reply(b, "🏦 Кредит дають лише тим, хто пішов у мінус", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	text := fmt.Sprintf("🏦 %s бере кредит %d %s під %d%% на день\n"+
+		"Кожен виграш у %s гасить %d%% боргу, повернути одразу: /loan repay",
+		from.name, amount, domain.CoinGame.Emoji(), terms.Rate, domain.CoinGame.Emoji(), terms.RepayShare)
+	_, _ = msg.Reply(b, text, &gotgbot.SendMessageOpts{})
+	return nil
+}
+
+func (s *LoanService) sendLoan(b *gotgbot.Bot, msg *gotgbot.Message, from sender, terms domain.LoanTerms) error {
+	loan, ok, err := s.repo.GetLoan(msg.Chat.Id, from.id)
+	if err != nil {
+		return err
+	}
+	var text string
+	switch {
+	case ok:
+		text = fmt.Sprintf("🏦 Твій борг: %d (узято %d під %d%% на день)\n"+
+			"Кожен виграш у %s гасить %d%% боргу, повернути одразу: /loan repay",
+			loan.Debt, loan.Principal, loan.Rate, domain.CoinGame.Emoji(), terms.RepayShare)
+	case terms.Enabled():
+		text = fmt.Sprintf("🏦 Для тих, хто в мінусі: кредит до %d %s під %d%% на день\n"+
+			"Борг гаситься з кожного виграшу в %s (%d%%)\nВзяти: /loan 100",
+			terms.Max, domain.CoinGame.Emoji(), terms.Rate, domain.CoinGame.Emoji(), terms.RepayShare)
+	default:
+		text = "🏦 Кредити в цьому чаті вимкнено"
+	}
+	_, _ = msg.Reply(b, text, &gotgbot.SendMessageOpts{})
+	return nil
+}
+
+func (s *LoanService) repay(b *gotgbot.Bot, msg *gotgbot.Message, from sender) error {
+	loan, repaid, err := s.repo.Repay(msg.Chat.Id, from.id, msgTime(msg))
+	if err != nil {
+		return err
+	}
+	var text string
+	switch {
+	case loan.Id == 0:
+		text = "🏦 Боргів у тебе нема"
+	case repaid == 0:
+		text = fmt.Sprintf("🏦 Нема чим платити: борг %d, а баланс не в плюсі", loan.Debt)
+	case loan.Debt == 0:
+		text = fmt.Sprintf("🏦 %s віддає %d і закриває кредит!", from.name, repaid)
+	default:
+		text = fmt.Sprintf("🏦 %s віддає %d, лишилось %d", from.name, repaid, loan.Debt)
+	}
+	_, _ = msg.Reply(b, text, &gotgbot.SendMessageOpts{})
+	return nil
+}
+
+// AccrueInterest adds the daily interest to the loans that are due.
+func (s *LoanService) AccrueInterest(now time.Time) {
+	if _, err := s.repo.AccrueInterest(now); err != nil {
+		log.Printf("failed to accrue loan interest: %v", err)
+	}
+}
+
+// Debt returns what the player owes, zero without a loan.
+func (s *LoanService) Debt(chatId int64, userId int64) (int64, error) {
+	loan, _, err := s.repo.GetLoan(chatId, userId)
+	return loan.Debt, err
+}
+
+// RatingPage returns a page of the chat's creditors leaderboard, biggest debt first.
+func (s *LoanService) RatingPage(chatId int64, limit, offset int) ([]domain.LoanStats, error) {
+	return s.repo.GetLoanRatingPage(chatId, limit, offset)
+}
+
+// CountRating returns how many players the creditors leaderboard has.
+func (s *LoanService) CountRating(chatId int64) (int, error) {
+	return s.repo.CountLoanRating(chatId)
+}
+
+// RatingEntry returns the player's line of the creditors leaderboard and its
+// zero-based position.
+func (s *LoanService) RatingEntry(chatId int64, userId int64) (domain.LoanStats, int, bool, error) {
+	return s.repo.GetLoanRatingEntry(chatId, userId)
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"bandit-counter-bot/internal/repository"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// LotteryService sells lottery tickets for coins and draws the chat's pot on schedule.
+type LotteryService struct {
+	repo         *repository.LotteryRepo
+	settingsRepo *repository.SettingsRepo
+}
+
+func NewLotteryService(repo *repository.LotteryRepo, settingsRepo *repository.SettingsRepo) *LotteryService {
+	return &LotteryService{repo: repo, settingsRepo: settingsRepo}
+}
+
+// HandleTicketCommand buys tickets with "/ticket N", claims a won prize with
+// "/ticket claim", shows the last draw with "/ticket last" and the current
+// round with a bare "/ticket".
+func (s *LotteryService) HandleTicketCommand(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+	from := messageSender(msg)
+	args := strings.Fields(msg.Text)[1:]
+
+	if len(args) > 0 {
+		switch strings.ToLower(args[0]) {
+		case "claim":
+			return s.claim(b, msg, from)
+		case "last":
+			return s.sendLastDraw(b, msg)
+		}
+	}
+
+	schedule, err := s.settingsRepo.GetLotterySchedule(msg.Chat.Id)
+	if err != nil {
+		return err
+	}
+	if !schedule.Enabled() {
+		_, _ = msg.Reply(b, "🎟 Лотерею в цьому чаті вимкнено, адміни вмикають її в /settings", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+	if len(args) == 0 {
+		return s.sendRound(b, msg, from, schedule)
+	}
+
+	count, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || count < 1 || count > domain.LotteryMaxTickets {
+		_, _ = msg.Reply(b, fmt.Sprintf("🎟 Кількість квитків пиши числом від 1 до %d, наприклад /ticket 3", domain.LotteryMaxTickets),
+			&gotgbot.SendMessageOpts{})
+		return nil
+	}
+	price, err := s.settingsRepo.GetTicketPrice(msg.Chat.Id)
+	if err != nil {
+		return err
+	}
+
+	owned, pot, err := s.repo.BuyTickets(msg.Chat.Id, from.id, from.name, count, price, msgTime(msg))
+	if errors.Is(err, repository.ErrLotteryFunds) {
+		_, _ = msg.Reply(b, fmt.Sprintf("💸 %s, не вистачає балансу: квитки коштують %d %s", from.name, count*price, domain.CoinGame.Emoji()),
+			&gotgbot.SendMessageOpts{})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	text := fmt.Sprintf("🎟 %s купує квитки (%d) за %d %s\nТвоїх квитків у розіграші: %d\n🏦 Банк: %d",
+		from.name, count, count*price, domain.CoinGame.Emoji(), owned, pot)
+	_, _ = msg.Reply(b, text, &gotgbot.SendMessageOpts{})
+	return nil
+}
+
+func (s *LotteryService) sendRound(b *gotgbot.Bot, msg *gotgbot.Message, from sender, schedule domain.LotterySchedule) error {
+	chatId := msg.Chat.Id
+	pot, err := s.repo.GetPot(chatId)
+	if err != nil {
+		return err
+	}
+	mine, total, err := s.repo.CountTickets(chatId, from.id)
+	if err != nil {
+		return err
+	}
+	price, err := s.settingsRepo.GetTicketPrice(chatId)
+	if err != nil {
+		return err
+	}
+	unclaimed, err := s.repo.GetUnclaimed(chatId, from.id)
+	if err != nil {
+		return err
+	}
+	loc, err := chatLocation(s.settingsRepo, chatId)
+	if err != nil {
+		return err
+	}
+	next, _ := schedule.NextDraw(time.Now(), loc)
+
+	text := fmt.Sprintf("🎟 Лотерея\n\n"+
+		"🏦 Банк: %d\n"+
+		"🎫 Квиток: %d %s\n"+
+		"Квитків у розіграші: %d, твоїх: %d\n"+
+		"⏰ Розіграш: %s\n\n"+
+		"Купити: /ticket 3 · минулий розіграш: /ticket last",
+		pot, price, domain.CoinGame.Emoji(), total, mine, next.In(loc).Format("02.01 15:04"))
+	if unclaimed > 0 {
+		text += fmt.Sprintf("\n\n🏆 Тебе чекає виграш %d — забери: /ticket claim", unclaimed)
+	}
+	_, _ = msg.Reply(b, text, &gotgbot.SendMessageOpts{})
+	return nil
+}
+
+func (s *LotteryService) claim(b *gotgbot.Bot, msg *gotgbot.Message, from sender) error {
+	prize, err := s.repo.Claim(msg.Chat.Id, from.id, msgTime(msg))
+	if err != nil {
+		return err
+	}
+	text := "🎟 Незабраних виграшів у тебе нема"
+	if prize > 0 {
+		text = fmt.Sprintf("🏆 %s забирає виграш лотереї: +%d %s", from.name, prize, domain.CoinGame.Emoji())
+	}
+	_, _ = msg.Reply(b, text, &gotgbot.SendMessageOpts{})
+	return nil
+}
+
+// sendLastDraw shows the latest draw with everything needed to check it: the
+// seed and how many tickets each player had.
+func (s *LotteryService) sendLastDraw(b *gotgbot.Bot, msg *gotgbot.Message) error {
+	draw, ok, err := s.repo.GetLastDraw(msg.Chat.Id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		_, _ = msg.Reply(b, "🎟 Розіграшів ще не було", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+	tickets, err := s.repo.GetDrawTickets(draw.Id)
+	if err != nil {
+		return err
+	}
+	loc, err := chatLocation(s.settingsRepo, msg.Chat.Id)
+	if err != nil {
+		return err
+	}
+
+	type holder struct {
+		name  string
+		count int
+	}
+	holders := map[int64]*holder{}
+	var order []int64
+	for _, t := range tickets {
+		h, ok := holders[t.UserId]
+		if !ok {
+			h = &holder{name: t.Username}
+			holders[t.UserId] = h
+			order = append(order, t.UserId)
+		}
+		h.count++
+	}
+	sort.SliceStable(order, func(i, j int) bool { return holders[order[i]].count > holders[order[j]].count })
+
+	status := map[domain.LotteryDrawStatus]string{
+		domain.LotteryWon:     "чекає, поки заберуть",
+		domain.LotteryClaimed: "забрано",
+		domain.LotteryRolled:  "не забрали, перейшов у наступний банк",
+	}
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "🎟 Розіграш %s\n\n", draw.DrawnAt.In(loc).Format("02.01.06 15:04"))
+	fmt.Fprintf(&builder, "🏦 Банк: %d (%s)\n", draw.Pot, status[draw.Status])
+	fmt.Fprintf(&builder, "🏆 Квиток #%d — %s\n", draw.TicketId, draw.WinnerName)
+	fmt.Fprintf(&builder, "🔑 Seed: %d, квитків: %d\n\n", draw.Seed, draw.Tickets)
+	for _, id := range order {
+		fmt.Fprintf(&builder, "👤 %s — %d\n", holders[id].name, holders[id].count)
+	}
+	_, _ = msg.Reply(b, builder.String(), &gotgbot.SendMessageOpts{})
+	return nil
+}
+
+// RunDraws draws the lotteries whose scheduled time has come.
+func (s *LotteryService) RunDraws(b *gotgbot.Bot, now time.Time) {
+	chats, err := s.settingsRepo.GetLotteryChats()
+	if err != nil {
+		log.Printf("failed to load lottery schedules: %v", err)
+		return
+	}
+	for _, chatId := range chats {
+		if err := s.runDraw(b, chatId, now); err != nil {
+			log.Printf("lottery draw for chat %d: %v", chatId, err)
+		}
+	}
+}
+
+func (s *LotteryService) runDraw(b *gotgbot.Bot, chatId int64, now time.Time) error {
+	schedule, err := s.settingsRepo.GetLotterySchedule(chatId)
+	if err != nil {
+		return err
+	}
+	loc, err := chatLocation(s.settingsRepo, chatId)
+	if err != nil {
+		return err
+	}
+	at, ok := schedule.Due(now, loc)
+	if !ok {
+		return nil
+	}
+
+	draw, drawn, err := s.repo.Draw(chatId, rand.Uint64(), at)
+	if err != nil {
+		return err
+	}
+	if !drawn {
+		return nil
+	}
+
+	next, _ := schedule.NextDraw(now, loc)
+	text := fmt.Sprintf("🎟 Розіграш лотереї!\n\n"+
+		"Квитків: %d, банк: %d %s\n"+
+		"🏆 Виграє квиток #%d — %s (квитків: %d)!\n\n"+
+		"Забери виграш командою /ticket claim до наступного розіграшу (%s), інакше банк перейде далі\n"+
+		"🔑 Seed: %d · перевірити: /ticket last",
+		draw.Tickets, draw.Pot, domain.CoinGame.Emoji(), draw.TicketId, draw.WinnerName, draw.WinnerCount,
+		next.In(loc).Format("02.01 15:04"), draw.Seed)
+	if _, err := b.SendMessage(chatId, text, nil); err != nil {
+		log.Printf("failed to send lottery draw to chat %d: %v", chatId, err)
+	}
+	return nil
+}
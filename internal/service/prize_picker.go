@@ -0,0 +1,177 @@
+package service
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+const (
+	prizePickerPageSize  = 16
+	prizePickerPages     = 64 / prizePickerPageSize
+	prizeModeNameMaxLen  = 24
+	maxCustomPrizeModes  = 8
+	pendingPrizeModeName = "prize_mode_name"
+)
+
+// HandlePrizePickerCallback drives the custom prize set picker. The picker is
+// stateless: the whole selection travels in the callback data as
+// prizepick:<hex mask>:<page>:<action>, where the action is t<value> to toggle
+// a dice value, p to only show the page and s to save the selection.
+func (s *SettingsService) HandlePrizePickerCallback(b *gotgbot.Bot, ctx *ext.Context) error {
+	cb := ctx.CallbackQuery
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) < 4 {
+		cb.Answer(b, nil)
+		return nil
+	}
+
+	chatId := cb.Message.GetChat().Id
+	userId := cb.From.Id
+	if !s.auth.CanPerform(b, chatId, userId, "settings") {
+		cb.Answer(b, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "нізя тобі таке клацать",
+		})
+		return nil
+	}
+
+	mask, err := strconv.ParseUint(parts[1], 16, 64)
+	if err != nil {
+		cb.Answer(b, nil)
+		return nil
+	}
+	page, err := strconv.Atoi(parts[2])
+	if err != nil || page < 0 || page >= prizePickerPages {
+		page = 0
+	}
+	set := domain.PrizeSet(mask)
+	action := parts[3]
+
+	switch {
+	case action == "s":
+		if set.Len() == 0 {
+			cb.Answer(b, &gotgbot.AnswerCallbackQueryOpts{
+				Text: "Вибери хоч одну комбінацію",
+			})
+			return nil
+		}
+		s.pending.Set(chatId, s.auth.MessageActor(b, chatId, userId), pendingPrizeModeName, parts[1], time.Now())
+		text := fmt.Sprintf("✏️ Як назвати набір? Напиши назву наступним повідомленням (до %d символів).\n\n"+
+			"Якщо така назва вже є, набір перезапишеться.", prizeModeNameMaxLen)
+		_, _, _ = cb.Message.EditText(b, text, &gotgbot.EditMessageTextOpts{})
+		cb.Answer(b, nil)
+		return nil
+
+	case strings.HasPrefix(action, "t"):
+		if value, err := strconv.Atoi(action[1:]); err == nil {
+			set = set.Toggle(value)
+		}
+	}
+
+	text, keyboard := buildPrizePicker(set, page)
+	_, _, _ = cb.Message.EditText(b, text, &gotgbot.EditMessageTextOpts{
+		ReplyMarkup: keyboard,
+	})
+	cb.Answer(b, nil)
+	return nil
+}
+
+func prizePickerCallback(set domain.PrizeSet, page int, action string) string {
+	return fmt.Sprintf("prizepick:%x:%d:%s", uint64(set), page, action)
+}
+
+func buildPrizePicker(set domain.PrizeSet, page int) (string, gotgbot.InlineKeyboardMarkup) {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "🛠 Свій набір\n\nКлацай комбінації, які мають вигравати. Вибрано: %d", set.Len())
+	if n := set.Len(); n > 0 && n <= prizePickerPageSize {
+		for _, v := range set.Values() {
+			fmt.Fprintf(&builder, "\n• %s", domain.DecodeSlot(v))
+		}
+	}
+	fmt.Fprintf(&builder, "\n\nСторінка %d з %d", page+1, prizePickerPages)
+
+	var rows [][]gotgbot.InlineKeyboardButton
+	first := page*prizePickerPageSize + 1
+	for v := first; v < first+prizePickerPageSize; v += 2 {
+		var row []gotgbot.InlineKeyboardButton
+		for _, value := range []int{v, v + 1} {
+			label := domain.DecodeSlot(value).String()
+			if set.Has(value) {
+				label = "✅ " + label
+			}
+			row = append(row, gotgbot.InlineKeyboardButton{
+				Text:         label,
+				CallbackData: prizePickerCallback(set, page, fmt.Sprintf("t%d", value)),
+			})
+		}
+		rows = append(rows, row)
+	}
+
+	var navButtons []gotgbot.InlineKeyboardButton
+	if page > 0 {
+		navButtons = append(navButtons, gotgbot.InlineKeyboardButton{
+			Text:         "⬅️ Назад",
+			CallbackData: prizePickerCallback(set, page-1, "p"),
+		})
+	}
+	if page < prizePickerPages-1 {
+		navButtons = append(navButtons, gotgbot.InlineKeyboardButton{
+			Text:         "Далі ➡️",
+			CallbackData: prizePickerCallback(set, page+1, "p"),
+		})
+	}
+	rows = append(rows, navButtons)
+	rows = append(rows, []gotgbot.InlineKeyboardButton{
+		{Text: "💾 Зберегти", CallbackData: prizePickerCallback(set, page, "s")},
+		{Text: "❌ Скасувати", CallbackData: "settings:menu:main"},
+	})
+
+	return builder.String(), gotgbot.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// savePrizeMode handles the name an admin sent for the set picked earlier.
+func (s *SettingsService) savePrizeMode(b *gotgbot.Bot, msg *gotgbot.Message, payload string) error {
+	mask, err := strconv.ParseUint(payload, 16, 64)
+	if err != nil {
+		return nil
+	}
+	set := domain.PrizeSet(mask)
+
+	name := strings.TrimSpace(msg.Text)
+	if name == "" || strings.Contains(name, "\n") || utf8.RuneCountInString(name) > prizeModeNameMaxLen {
+		_, _ = msg.Reply(b, fmt.Sprintf("назва має бути одним рядком до %d символів, збережи набір ще раз через /settings", prizeModeNameMaxLen), &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	modes, err := s.repo.GetCustomPrizeModes(msg.Chat.Id)
+	if err != nil {
+		return err
+	}
+	exists := false
+	for _, m := range modes {
+		if m.Name == name {
+			exists = true
+			break
+		}
+	}
+	if !exists && len(modes) >= maxCustomPrizeModes {
+		_, _ = msg.Reply(b, fmt.Sprintf("більше %d своїх наборів не влізе, перезапиши якийсь старий", maxCustomPrizeModes), &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	mode, err := s.repo.SaveCustomPrizeMode(msg.Chat.Id, name, set.Values())
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdatePrizeMode(mode.Key, mode.Values, msg.Chat.Id); err != nil {
+		return err
+	}
+	_, _ = msg.Reply(b, fmt.Sprintf("✅ Набір «%s» збережено й увімкнено", name), &gotgbot.SendMessageOpts{})
+	return nil
+}
@@ -1,27 +1,33 @@
 package service
 
 import (
+	"bandit-counter-bot/internal/domain"
 	"bandit-counter-bot/internal/repository"
+	"errors"
+	"fmt"
+	"log"
 	"strings"
+	"time"
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 )
 
+// ResetService closes the current season: the standings go to the archive
+// and everybody starts again from zero.
 type ResetService struct {
-	statsRepo *repository.UserStatsRepo
-	auth      *AuthService
+	seasonRepo   *repository.SeasonRepo
+	statsRepo    *repository.UserStatsRepo
+	settingsRepo *repository.SettingsRepo
+	auth         *AuthService
 }
 
-func NewResetService(statsRepo *repository.UserStatsRepo, auth *AuthService) *ResetService {
-	return &ResetService{statsRepo: statsRepo, auth: auth}
+func NewResetService(seasonRepo *repository.SeasonRepo, statsRepo *repository.UserStatsRepo, settingsRepo *repository.SettingsRepo, auth *AuthService) *ResetService {
+	return &ResetService{seasonRepo: seasonRepo, statsRepo: statsRepo, settingsRepo: settingsRepo, auth: auth}
 }
 
 func (s *ResetService) HandleResetCommand(b *gotgbot.Bot, ctx *ext.Context) error {
-	chatId := ctx.EffectiveMessage.Chat.Id
-	userId := ctx.EffectiveMessage.From.Id
-
-	if !s.auth.CanPerform(b, chatId, userId, "reset") {
+	if !s.auth.CanPerformMessage(b, ctx.EffectiveMessage, "reset") {
 		_, _ = ctx.EffectiveMessage.Reply(b, "а фіг тобі", &gotgbot.SendMessageOpts{})
 		return nil
 	}
@@ -34,7 +40,7 @@ func (s *ResetService) HandleResetCommand(b *gotgbot.Bot, ctx *ext.Context) erro
 			},
 		},
 	}
-	_, _ = ctx.EffectiveMessage.Reply(b, "⚠️ Ти впевнений що хочеш скинути ВСЮ статистику?", &gotgbot.SendMessageOpts{
+	_, _ = ctx.EffectiveMessage.Reply(b, "⚠️ Закриваємо сезон? Підсумки підуть в архів, а рахунки всіх гравців обнуляться", &gotgbot.SendMessageOpts{
 		ReplyMarkup: keyboard,
 	})
 	return nil
@@ -69,7 +75,7 @@ func (s *ResetService) HandleResetCallback(b *gotgbot.Bot, ctx *ext.Context) err
 				},
 			},
 		}
-		cb.Message.EditText(b, "⚠️⚠️ Це видалить статистику ВСІХ гравців у цьому чаті. Точно?", &gotgbot.EditMessageTextOpts{
+		cb.Message.EditText(b, "⚠️⚠️ Це обнулить рахунки ВСІХ гравців у цьому чаті. Точно?", &gotgbot.EditMessageTextOpts{
 			ReplyMarkup: keyboard,
 		})
 
@@ -87,11 +93,21 @@ func (s *ResetService) HandleResetCallback(b *gotgbot.Bot, ctx *ext.Context) err
 		})
 
 	case "confirm":
-		if err := s.statsRepo.ResetChat(chatId); err != nil {
+		season, err := s.seasonRepo.CloseSeason(chatId, time.Now())
+		if errors.Is(err, repository.ErrEmptySeason) {
+			cb.Message.EditText(b, "🤷 в цьому сезоні ще ніхто не грав, нема що закривати", &gotgbot.EditMessageTextOpts{})
+			break
+		}
+		if err != nil {
+			cb.Answer(b, nil)
+			return err
+		}
+		text, err := s.seasonClosedText(chatId, season)
+		if err != nil {
 			cb.Answer(b, nil)
 			return err
 		}
-		cb.Message.EditText(b, "💥 пацани, не знаю вашє хто ви", &gotgbot.EditMessageTextOpts{})
+		cb.Message.EditText(b, text, &gotgbot.EditMessageTextOpts{})
 
 	case "cancel":
 		cb.Message.EditText(b, "❌ отмінет", &gotgbot.EditMessageTextOpts{})
@@ -100,3 +116,105 @@ func (s *ResetService) HandleResetCallback(b *gotgbot.Bot, ctx *ext.Context) err
 	cb.Answer(b, nil)
 	return nil
 }
+
+func (s *ResetService) seasonClosedText(chatId int64, season domain.Season) (string, error) {
+	seasons, err := s.seasonRepo.GetSeasons(chatId, domain.GameSlot, 1, 0)
+	if err != nil {
+		return "", err
+	}
+	text := fmt.Sprintf("🏁 Сезон %d закрито!", season.Number)
+	if len(seasons) > 0 && seasons[0].Number == season.Number && seasons[0].Champion != "" {
+		text += fmt.Sprintf("\n🏆 Чемпіон: %s з балансом %d", seasons[0].Champion, seasons[0].ChampionBalance)
+	}
+	text += "\n\n💥 пацани, не знаю вашє хто ви. Погнали новий сезон!\nМинулі сезони — в /stats"
+	return text, nil
+}
+
+const seasonTopSize = 10
+
+// RollOverSeasons closes the seasons whose scheduled end has come.
+func (s *ResetService) RollOverSeasons(b *gotgbot.Bot, now time.Time) {
+	chats, err := s.settingsRepo.GetScheduledSeasonChats()
+	if err != nil {
+		log.Printf("failed to load season schedules: %v", err)
+		return
+	}
+	for _, chatId := range chats {
+		if err := s.rollOverSeason(b, chatId, now); err != nil {
+			log.Printf("season rollover for chat %d: %v", chatId, err)
+		}
+	}
+}
+
+// rollOverSeason closes the season at the scheduled midnight, then posts the
+// final top with the champion and announces the new one. A one-off end date is
+// switched off once it has passed.
+func (s *ResetService) rollOverSeason(b *gotgbot.Bot, chatId int64, now time.Time) error {
+	schedule, err := s.settingsRepo.GetSeasonSchedule(chatId)
+	if err != nil {
+		return err
+	}
+	loc, err := chatLocation(s.settingsRepo, chatId)
+	if err != nil {
+		return err
+	}
+	boundary, ok := schedule.Due(now, loc)
+	if !ok {
+		return nil
+	}
+
+	current, err := s.seasonRepo.GetCurrentSeason(chatId, now)
+	if err != nil {
+		return err
+	}
+	enabledGames, err := s.settingsRepo.GetEnabledGames(chatId)
+	if err != nil {
+		return err
+	}
+	game := defaultGame(enabledGames)
+	stats, err := s.statsRepo.GetRatingPage(domain.RatingQuery{ChatId: chatId, Game: game, Rating: domain.RatingRich}, seasonTopSize, 0)
+	if err != nil {
+		return err
+	}
+
+	season, err := s.seasonRepo.CloseSeason(chatId, boundary)
+	closed := err == nil
+	if err != nil && !errors.Is(err, repository.ErrEmptySeason) {
+		return err
+	}
+
+	if schedule.Mode == domain.SeasonsOnDate {
+		err = s.settingsRepo.UpdateSeasonSchedule(domain.SeasonsManual, "", boundary, chatId)
+	} else {
+		err = s.settingsRepo.MarkSeasonChecked(boundary, chatId)
+	}
+	if err != nil {
+		return err
+	}
+
+	// announced only now: a failed close is retried next minute and must not repeat the top
+	if len(stats) > 0 {
+		s.send(b, chatId, formatSeasonFinal(current.Number, game, stats))
+	}
+	if closed {
+		s.send(b, chatId, fmt.Sprintf("🎉 Стартував сезон %d! Всі рахунки з нуля, крутіть %s\nПідсумки минулого — в /stats",
+			season.Number+1, game.Emoji()))
+	}
+	return nil
+}
+
+func (s *ResetService) send(b *gotgbot.Bot, chatId int64, text string) {
+	if _, err := b.SendMessage(chatId, text, nil); err != nil {
+		log.Printf("failed to send season message to chat %d: %v", chatId, err)
+	}
+}
+
+func formatSeasonFinal(number int64, game domain.Game, stats []domain.RatingStats) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "🏁 Сезон %d добіг кінця!\n\n🏆 Фінальний топ · %s %s\n", number, game.Emoji(), gameLabels[game])
+	for _, u := range stats {
+		builder.WriteString(formatRatingLine("rich", game, u, 0) + "\n")
+	}
+	fmt.Fprintf(&builder, "\n🥇 Чемпіон сезону: %s!", stats[0].Username)
+	return builder.String()
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// sender is whoever a message counts for.
+type sender struct {
+	id     int64
+	name   string
+	handle string
+	isChat bool
+}
+
+// messageSender resolves the real author of a message. Anonymous admins and
+// channels all post through shared service accounts in From, so for them the
+// chat in SenderChat is the player; an anonymous admin plays as the group itself.
+func messageSender(msg *gotgbot.Message) sender {
+	if msg.SenderChat != nil {
+		name := msg.SenderChat.Title
+		if msg.SenderChat.Id == msg.Chat.Id {
+			name = "Анонімний адмін"
+		}
+		return sender{id: msg.SenderChat.Id, name: name, isChat: true}
+	}
+	return sender{id: msg.From.Id, name: msg.From.FirstName, handle: strings.ToLower(msg.From.Username)}
+}
@@ -1,10 +1,14 @@
 package service
 
 import (
+	"bandit-counter-bot/internal/cache"
+	"bandit-counter-bot/internal/domain"
 	"bandit-counter-bot/internal/repository"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
@@ -13,28 +17,127 @@ import (
 var prizeModes = []struct {
 	key    string
 	label  string
-	values string
+	values []int
 }{
-	{"classic", "777", "[64]"},
-	{"three_in_a_row", "Три в ряд", "[1,22,43,64]"},
-	{"lemons", "Лимони", "[43]"},
+	{"classic", "777", []int{64}},
+	{"three_in_a_row", "Три в ряд", []int{1, 22, 43, 64}},
+	{"lemons", "Лимони", []int{43}},
 }
 
 var winAmounts = []int64{32, 64, 128, 256}
 
+// Bounds of a win amount typed in by an admin.
+const (
+	minWinAmount = 1
+	maxWinAmount = 1_000_000
+)
+
+const (
+	pendingWinAmount     = "win_amount"
+	pendingSeasonEndDate = "season_end_date"
+)
+
+var spinCosts = []int64{0, 1, 2, 5, 10}
+
+var jackpotShares = []int64{0, 10, 25, 50, 100}
+
+var spinCooldowns = []int64{0, 3, 5, 10, 30}
+
+var spinBursts = []int64{0, 5, 10, 20}
+
+var dailySpinLimits = []int64{0, 20, 50, 100, 200}
+
+var dailyBonuses = []int64{0, 10, 25, 50, 100}
+
+var bailoutThresholds = []int64{0, -500, -1000, -5000}
+
+var bailoutCooldownDays = []int64{1, 3, 7, 30}
+
+var transferFloors = []int64{0, -500, -1000}
+
+var transferDailyCaps = []int64{0, 100, 500, 1000}
+
+var betMins = []int64{1, 10, 50}
+
+var betMaxes = []int64{0, 100, 500, 1000, 5000}
+
+var lotteryHours = []int{12, 18, 20, 22}
+
+var ticketPrices = []int64{5, 10, 25, 50, 100}
+
+var loanMaxes = []int64{0, 100, 500, 1000}
+
+var loanRates = []int64{0, 1, 5, 10}
+
+var loanRepayShares = []int64{25, 50, 100}
+
+// settingsMenus maps callback categories that live in a submenu to that submenu.
+var settingsMenus = map[string]string{
+	"payout":        "payout",
+	"cooldown":      "throttle",
+	"burst":         "throttle",
+	"daily":         "throttle",
+	"throttleclean": "throttle",
+	"seasons":       "seasons",
+	"levelcurve":    "levels",
+	"dailybonus":    "economy",
+	"bailout":       "economy",
+	"bailoutcd":     "economy",
+	"transferfloor": "economy",
+	"transfercap":   "economy",
+	"betmin":        "economy",
+	"betmax":        "economy",
+	"lottery":       "lottery",
+	"lotteryhour":   "lottery",
+	"ticketprice":   "lottery",
+	"loanmax":       "loans",
+	"loanrate":      "loans",
+	"loanrepay":     "loans",
+}
+
+var seasonSchedules = []struct {
+	mode  string
+	label string
+}{
+	{domain.SeasonsManual, "Вручну"},
+	{domain.SeasonsWeekly, "Щотижня"},
+	{domain.SeasonsMonthly, "Щомісяця"},
+}
+
+var lotterySchedules = []struct {
+	mode  string
+	label string
+}{
+	{domain.LotteryOff, "Вимк"},
+	{domain.LotteryDaily, "Щодня"},
+	{domain.LotteryWeekly, "Щонеділі"},
+}
+
+var payoutAmounts = []int64{0, 16, 32, 64, 128, 256, 512}
+
+var comboLabels = map[domain.Combo]string{
+	domain.ComboSevens:    "7️⃣7️⃣7️⃣",
+	domain.ComboBars:      "BAR BAR BAR",
+	domain.ComboGrapes:    "🍇🍇🍇",
+	domain.ComboLemons:    "🍋🍋🍋",
+	domain.ComboTriple:    "Будь-яка трійка",
+	domain.ComboTwoSevens: "Дві сімки",
+}
+
 type SettingsService struct {
-	repo *repository.SettingsRepo
-	auth *AuthService
+	repo        *repository.SettingsRepo
+	jackpotRepo *repository.JackpotRepo
+	auth        *AuthService
+	pending     *cache.PendingInputs
 }
 
-func NewSettingsService(repo *repository.SettingsRepo, auth *AuthService) *SettingsService {
-	return &SettingsService{repo: repo, auth: auth}
+func NewSettingsService(repo *repository.SettingsRepo, jackpotRepo *repository.JackpotRepo, auth *AuthService, pending *cache.PendingInputs) *SettingsService {
+	return &SettingsService{repo: repo, jackpotRepo: jackpotRepo, auth: auth, pending: pending}
 }
 
 func (s *SettingsService) HandleSettingsCommand(b *gotgbot.Bot, ctx *ext.Context) error {
 	chatId := ctx.EffectiveMessage.Chat.Id
-	userId := ctx.EffectiveMessage.From.Id
-	isAdmin := s.auth.IsAdmin(b, chatId, userId)
+	isAdmin := s.auth.IsAdminMessage(b, ctx.EffectiveMessage)
 	text, keyboard, err := s.buildSettingsMessage(chatId, isAdmin)
 	if err != nil {
 		return err
@@ -45,6 +148,69 @@ func (s *SettingsService) HandleSettingsCommand(b *gotgbot.Bot, ctx *ext.Context
 	return nil
 }
 
+func (s *SettingsService) HandleTimezoneCommand(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+	args := ctx.Args()
+	if len(args) < 2 {
+		current, err := s.repo.GetTimezone(msg.Chat.Id)
+		if err != nil {
+			return err
+		}
+		_, _ = msg.Reply(b, fmt.Sprintf("🕛 Часовий пояс чату: %s\nЗмінити: /timezone Europe/Kyiv", current), &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	if !s.auth.CanPerformMessage(b, msg, "settings") {
+		_, _ = msg.Reply(b, "нізя тобі таке міняти", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+	name := args[1]
+	if _, err := loadLocation(name); err != nil || name == "Local" {
+		_, _ = msg.Reply(b, "не знаю такого поясу, треба щось типу Europe/Kyiv", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+	if err := s.repo.UpdateTimezone(name, msg.Chat.Id); err != nil {
+		return err
+	}
+	_, _ = msg.Reply(b, fmt.Sprintf("🕛 Тепер день починається за %s", name), &gotgbot.SendMessageOpts{})
+	return nil
+}
+
+// HandlePendingInput picks up the answer to a question the bot asked in this
+// chat, like the name of a custom prize set. Any other message is passed on.
+func (s *SettingsService) HandlePendingInput(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+	if msg.From == nil || strings.HasPrefix(msg.Text, "/") {
+		return ext.ContinueGroups
+	}
+	actor := messageSender(msg).id
+	if !s.pending.Has(msg.Chat.Id, actor, time.Now()) {
+		return ext.ContinueGroups
+	}
+	// checked before Take, so nobody else's message can close the question
+	if !s.auth.CanPerformMessage(b, msg, "settings") {
+		return ext.ContinueGroups
+	}
+	input, ok := s.pending.Take(msg.Chat.Id, actor, time.Now())
+	if !ok {
+		return ext.ContinueGroups
+	}
+
+	var err error
+	switch input.Kind {
+	case pendingPrizeModeName:
+		err = s.savePrizeMode(b, msg, input.Payload)
+	case pendingWinAmount:
+		err = s.saveWinAmount(b, msg)
+	case pendingSeasonEndDate:
+		err = s.saveSeasonEndDate(b, msg)
+	}
+	if err != nil {
+		return err
+	}
+	return ext.EndGroups
+}
+
 func (s *SettingsService) HandleSettingsCallback(b *gotgbot.Bot, ctx *ext.Context) error {
 	cb := ctx.CallbackQuery
 	parts := strings.Split(cb.Data, ":")
@@ -59,54 +225,85 @@ func (s *SettingsService) HandleSettingsCallback(b *gotgbot.Bot, ctx *ext.Contex
 	value := parts[2]
 
 	switch category {
-	case "prize", "amount":
+	case "menu":
+
+	case "perm":
+		if !s.auth.IsAdmin(b, chatId, userId) {
+			cb.Answer(b, &gotgbot.AnswerCallbackQueryOpts{
+				Text: "Тільки адміни можуть змінювати дозволи",
+			})
+			return nil
+		}
+		if _, err := s.repo.TogglePermission(chatId, value); err != nil {
+			cb.Answer(b, nil)
+			return err
+		}
+
+	default:
 		if !s.auth.CanPerform(b, chatId, userId, "settings") {
 			cb.Answer(b, &gotgbot.AnswerCallbackQueryOpts{
 				Text: "нізя тобі таке клацать",
 			})
 			return nil
 		}
-		if category == "prize" {
-			for _, mode := range prizeModes {
-				if mode.key == value {
-					if err := s.repo.UpdatePrizeValues(mode.values, chatId); err != nil {
-						cb.Answer(b, nil)
-						return err
-					}
-					break
-				}
-			}
-		} else {
-			amount, err := strconv.ParseInt(value, 10, 64)
-			if err != nil {
-				cb.Answer(b, nil)
-				return nil
-			}
-			if err := s.repo.UpdateWinAmount(amount, chatId); err != nil {
-				cb.Answer(b, nil)
-				return err
-			}
+		if category == "amount" && value == "custom" {
+			s.pending.Set(chatId, s.auth.MessageActor(b, chatId, userId), pendingWinAmount, "", time.Now())
+			text := fmt.Sprintf("✏️ Скільки платити за виграш? Напиши число від %d до %d наступним повідомленням.",
+				minWinAmount, maxWinAmount)
+			_, _, _ = cb.Message.EditText(b, text, &gotgbot.EditMessageTextOpts{})
+			cb.Answer(b, nil)
+			return nil
 		}
-
-	case "perm":
-		if !s.auth.IsAdmin(b, chatId, userId) {
+		if category == "seasons" && value == domain.SeasonsOnDate {
+			s.pending.Set(chatId, s.auth.MessageActor(b, chatId, userId), pendingSeasonEndDate, "", time.Now())
+			_, _, _ = cb.Message.EditText(b, "📅 Коли закінчити сезон? Напиши останній день наступним повідомленням, наприклад 31.12.2025",
+				&gotgbot.EditMessageTextOpts{})
+			cb.Answer(b, nil)
+			return nil
+		}
+		handled, err := s.applySetting(chatId, category, value)
+		if errors.Is(err, errBetRange) {
 			cb.Answer(b, &gotgbot.AnswerCallbackQueryOpts{
-				Text: "Тільки адміни можуть змінювати дозволи",
+				Text: "Мінімальна ставка не може бути більшою за максимальну",
 			})
 			return nil
 		}
-		if _, err := s.repo.TogglePermission(chatId, value); err != nil {
+		if err != nil {
 			cb.Answer(b, nil)
 			return err
 		}
+		if !handled {
+			cb.Answer(b, nil)
+			return nil
+		}
+	}
 
+	menu := settingsMenus[category]
+	if category == "menu" {
+		menu = value
+	}
+	var text string
+	var keyboard gotgbot.InlineKeyboardMarkup
+	var err error
+	switch menu {
+	case "payout":
+		text, keyboard, err = s.buildPayoutMessage(chatId)
+	case "throttle":
+		text, keyboard, err = s.buildThrottleMessage(chatId)
+	case "seasons":
+		text, keyboard, err = s.buildSeasonScheduleMessage(chatId)
+	case "levels":
+		text, keyboard, err = s.buildLevelCurveMessage(chatId)
+	case "economy":
+		text, keyboard, err = s.buildEconomyMessage(chatId)
+	case "lottery":
+		text, keyboard, err = s.buildLotteryMessage(chatId)
+	case "loans":
+		text, keyboard, err = s.buildLoansMessage(chatId)
 	default:
-		cb.Answer(b, nil)
-		return nil
+		isAdmin := s.auth.IsAdmin(b, chatId, userId)
+		text, keyboard, err = s.buildSettingsMessage(chatId, isAdmin)
 	}
-
-	isAdmin := s.auth.IsAdmin(b, chatId, userId)
-	text, keyboard, err := s.buildSettingsMessage(chatId, isAdmin)
 	if err != nil {
 		cb.Answer(b, nil)
 		return err
@@ -119,26 +316,312 @@ func (s *SettingsService) HandleSettingsCallback(b *gotgbot.Bot, ctx *ext.Contex
 	return nil
 }
 
+// errBetRange rejects a bet limit that would put the minimum stake above the
+// maximum one.
+var errBetRange = errors.New("bet minimum above maximum")
+
+// updateBetLimits stores one of the offered bet limits unless the pair would
+// stop making sense.
+func (s *SettingsService) updateBetLimits(chatId int64, category, value string) error {
+	limits, err := s.repo.GetBetLimits(chatId)
+	if err != nil {
+		return err
+	}
+	options, update := betMins, s.repo.UpdateBetMin
+	if category == "betmax" {
+		options, update = betMaxes, s.repo.UpdateBetMax
+	}
+	for _, o := range options {
+		if strconv.FormatInt(o, 10) != value {
+			continue
+		}
+		if category == "betmin" {
+			limits.Min = o
+		} else {
+			limits.Max = o
+		}
+		if !limits.Valid() {
+			return errBetRange
+		}
+		return update(o, chatId)
+	}
+	return nil
+}
+
+// applySetting stores a settings change from a callback and reports whether
+// the category was recognised.
+func (s *SettingsService) applySetting(chatId int64, category, value string) (bool, error) {
+	switch category {
+	case "prize":
+		for _, mode := range prizeModes {
+			if mode.key == value {
+				return true, s.repo.UpdatePrizeMode(mode.key, mode.values, chatId)
+			}
+		}
+		customModes, err := s.repo.GetCustomPrizeModes(chatId)
+		if err != nil {
+			return true, err
+		}
+		for _, mode := range customModes {
+			if mode.Key == value {
+				return true, s.repo.UpdatePrizeMode(mode.Key, mode.Values, chatId)
+			}
+		}
+		return true, nil
+	case "game":
+		return true, s.toggleGame(chatId, value)
+	case "payout":
+		return true, s.cyclePayout(chatId, domain.Combo(value))
+	case "throttleclean":
+		return true, s.repo.ToggleThrottleCleanup(chatId)
+	case "seasons":
+		for _, sch := range seasonSchedules {
+			if sch.mode == value {
+				return true, s.repo.UpdateSeasonSchedule(sch.mode, "", time.Now(), chatId)
+			}
+		}
+		return true, nil
+	case "senderchats":
+		return true, s.repo.ToggleIgnoreSenderChats(chatId)
+	case "bailout":
+		for _, t := range bailoutThresholds {
+			if strconv.FormatInt(t, 10) == value {
+				return true, s.repo.UpdateBailoutThreshold(t, chatId)
+			}
+		}
+		return true, nil
+	case "bailoutcd":
+		for _, d := range bailoutCooldownDays {
+			if strconv.FormatInt(d, 10) == value {
+				return true, s.repo.UpdateBailoutCooldown(d*int64(24*time.Hour/time.Second), chatId)
+			}
+		}
+		return true, nil
+	case "transferfloor":
+		for _, f := range transferFloors {
+			if strconv.FormatInt(f, 10) == value {
+				return true, s.repo.UpdateTransferFloor(f, chatId)
+			}
+		}
+		return true, nil
+	case "lottery", "lotteryhour":
+		schedule, err := s.repo.GetLotterySchedule(chatId)
+		if err != nil {
+			return true, err
+		}
+		for _, sch := range lotterySchedules {
+			if category == "lottery" && sch.mode == value {
+				return true, s.repo.UpdateLotterySchedule(sch.mode, schedule.Hour, time.Now(), chatId)
+			}
+		}
+		for _, h := range lotteryHours {
+			if category == "lotteryhour" && strconv.Itoa(h) == value {
+				return true, s.repo.UpdateLotterySchedule(schedule.Mode, h, time.Now(), chatId)
+			}
+		}
+		return true, nil
+	case "ticketprice":
+		for _, p := range ticketPrices {
+			if strconv.FormatInt(p, 10) == value {
+				return true, s.repo.UpdateTicketPrice(p, chatId)
+			}
+		}
+		return true, nil
+	case "betmin", "betmax":
+		return true, s.updateBetLimits(chatId, category, value)
+	case "loanmax":
+		for _, m := range loanMaxes {
+			if strconv.FormatInt(m, 10) == value {
+				return true, s.repo.UpdateLoanMax(m, chatId)
+			}
+		}
+		return true, nil
+	case "loanrate":
+		for _, r := range loanRates {
+			if strconv.FormatInt(r, 10) == value {
+				return true, s.repo.UpdateLoanRate(r, chatId)
+			}
+		}
+		return true, nil
+	case "loanrepay":
+		for _, sh := range loanRepayShares {
+			if strconv.FormatInt(sh, 10) == value {
+				return true, s.repo.UpdateLoanRepayShare(sh, chatId)
+			}
+		}
+		return true, nil
+	case "levelcurve":
+		for _, c := range domain.LevelCurves {
+			if c.Key == value {
+				return true, s.repo.UpdateLevelCurve(c.Key, chatId)
+			}
+		}
+		return true, nil
+	}
+
+	// the rest pick one of the values offered on the keyboard
+	updaters := map[string]struct {
+		options []int64
+		update  func(int64, int64) error
+	}{
+		"amount":      {winAmounts, s.repo.UpdateWinAmount},
+		"cost":        {spinCosts, s.repo.UpdateSpinCost},
+		"jackpot":     {jackpotShares, s.repo.UpdateJackpotShare},
+		"cooldown":    {spinCooldowns, s.repo.UpdateSpinCooldown},
+		"burst":       {spinBursts, s.repo.UpdateSpinBurst},
+		"daily":       {dailySpinLimits, s.repo.UpdateDailySpinLimit},
+		"dailybonus":  {dailyBonuses, s.repo.UpdateDailyBonus},
+		"transfercap": {transferDailyCaps, s.repo.UpdateTransferDailyCap},
+	}
+	updater, ok := updaters[category]
+	if !ok {
+		return false, nil
+	}
+	for _, o := range updater.options {
+		if strconv.FormatInt(o, 10) == value {
+			return true, updater.update(o, chatId)
+		}
+	}
+	return true, nil
+}
+
+// saveWinAmount handles the win amount an admin typed in. A wrong answer keeps
+// the question open, so the admin can just try again.
+func (s *SettingsService) saveWinAmount(b *gotgbot.Bot, msg *gotgbot.Message) error {
+	amount, err := strconv.ParseInt(strings.TrimSpace(msg.Text), 10, 64)
+	if err != nil || amount < minWinAmount || amount > maxWinAmount {
+		s.pending.Set(msg.Chat.Id, messageSender(msg).id, pendingWinAmount, "", time.Now())
+		_, _ = msg.Reply(b, fmt.Sprintf("треба ціле число від %d до %d, спробуй ще", minWinAmount, maxWinAmount), &gotgbot.SendMessageOpts{})
+		return nil
+	}
+	if err := s.repo.UpdateWinAmount(amount, msg.Chat.Id); err != nil {
+		return err
+	}
+	_, _ = msg.Reply(b, fmt.Sprintf("✅ Тепер виграш платить %d", amount), &gotgbot.SendMessageOpts{})
+	return nil
+}
+
+// saveSeasonEndDate handles the last day of the season an admin typed in.
+func (s *SettingsService) saveSeasonEndDate(b *gotgbot.Bot, msg *gotgbot.Message) error {
+	loc, err := chatLocation(s.repo, msg.Chat.Id)
+	if err != nil {
+		return err
+	}
+	date, err := time.ParseInLocation("02.01.2006", strings.TrimSpace(msg.Text), loc)
+	if err != nil || date.Before(domain.StartOfDay(time.Now(), loc)) {
+		s.pending.Set(msg.Chat.Id, messageSender(msg).id, pendingSeasonEndDate, "", time.Now())
+		_, _ = msg.Reply(b, "треба дата типу 31.12.2025, і не з минулого. Спробуй ще", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+	if err := s.repo.UpdateSeasonSchedule(domain.SeasonsOnDate, date.Format(domain.SeasonEndDateLayout), time.Now(), msg.Chat.Id); err != nil {
+		return err
+	}
+	_, _ = msg.Reply(b, fmt.Sprintf("✅ Сезон закінчиться %s опівночі", date.Format("02.01.2006")), &gotgbot.SendMessageOpts{})
+	return nil
+}
+
+func containsAmount(amounts []int64, amount int64) bool {
+	for _, a := range amounts {
+		if a == amount {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *SettingsService) buildSettingsMessage(chatId int64, isAdmin bool) (string, gotgbot.InlineKeyboardMarkup, error) {
 	currentMode, err := s.repo.GetPrizeMode(chatId)
 	if err != nil {
 		return "", gotgbot.InlineKeyboardMarkup{}, err
 	}
+	customModes, err := s.repo.GetCustomPrizeModes(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	prizeValues, err := s.repo.GetPrizeValues(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
 	currentAmount, err := s.repo.GetWinAmount(chatId)
 	if err != nil {
 		return "", gotgbot.InlineKeyboardMarkup{}, err
 	}
+	currentCost, costSet, err := s.repo.GetSpinCost(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	currentShare, err := s.repo.GetJackpotShare(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	pool, err := s.jackpotRepo.GetPool(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	payoutTable, err := s.repo.GetPayoutTable(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	enabledGames, err := s.repo.GetEnabledGames(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	ignoreSenderChats, err := s.repo.GetIgnoreSenderChats(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
 
-	modeLabel := "777"
+	modeLabel := "свій"
 	for _, m := range prizeModes {
 		if m.key == currentMode {
 			modeLabel = m.label
 			break
 		}
 	}
+	for _, m := range customModes {
+		if m.Key == currentMode {
+			modeLabel = m.Name
+			break
+		}
+	}
+
+	costLabel := strconv.FormatInt(currentCost, 10)
+	if !costSet {
+		costLabel += " (лише за програш)"
+	}
 
 	var builder strings.Builder
-	fmt.Fprintf(&builder, "🎰 Налаштування крутілки\n\nРежим виграшу: %s\nСума виграшу: %d", modeLabel, currentAmount)
+	fmt.Fprintf(&builder, "🎰 Налаштування крутілки\n\nРежим виграшу: %s\nСума виграшу: %d\nЦіна спроби: %s",
+		modeLabel, currentAmount, costLabel)
+	for _, c := range domain.Combos {
+		if amount := payoutTable[c]; amount > 0 {
+			fmt.Fprintf(&builder, "\n💰 %s: %d", comboLabels[c], amount)
+		}
+	}
+	fmt.Fprintf(&builder, "\n\n🏦 Джекпот: %d (відрахування з програшу: %d%%)", pool, currentShare)
+
+	builder.WriteString("\n\n🎮 Ігри:")
+	if len(enabledGames) == 0 {
+		builder.WriteString(" всі вимкнені")
+	}
+	var gameButtons []gotgbot.InlineKeyboardButton
+	for _, g := range domain.Games {
+		label := g.Emoji()
+		if containsGame(enabledGames, g) {
+			fmt.Fprintf(&builder, " %s", g.Emoji())
+			label = "✅" + label
+		}
+		gameButtons = append(gameButtons, gotgbot.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf("settings:game:%s", g),
+		})
+	}
+
+	senderChatsLabel := "👥 Канали й анонімні адміни: граються окремо"
+	if ignoreSenderChats {
+		senderChatsLabel = "👥 Канали й анонімні адміни: не рахуються"
+	}
+	fmt.Fprintf(&builder, "\n%s", senderChatsLabel)
 
 	var prizeButtons []gotgbot.InlineKeyboardButton
 	for _, m := range prizeModes {
@@ -151,6 +634,26 @@ func (s *SettingsService) buildSettingsMessage(chatId int64, isAdmin bool) (stri
 			CallbackData: fmt.Sprintf("settings:prize:%s", m.key),
 		})
 	}
+	prizeButtons = append(prizeButtons, gotgbot.InlineKeyboardButton{
+		Text:         "🛠 Свій",
+		CallbackData: prizePickerCallback(domain.NewPrizeSet(prizeValues), 0, "p"),
+	})
+
+	var customRows [][]gotgbot.InlineKeyboardButton
+	for i, m := range customModes {
+		if i%3 == 0 {
+			customRows = append(customRows, nil)
+		}
+		label := m.Name
+		if m.Key == currentMode {
+			label = "✅ " + label
+		}
+		last := len(customRows) - 1
+		customRows[last] = append(customRows[last], gotgbot.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf("settings:prize:%s", m.Key),
+		})
+	}
 
 	var amountButtons []gotgbot.InlineKeyboardButton
 	for _, a := range winAmounts {
@@ -163,12 +666,62 @@ func (s *SettingsService) buildSettingsMessage(chatId int64, isAdmin bool) (stri
 			CallbackData: fmt.Sprintf("settings:amount:%d", a),
 		})
 	}
+	customAmountLabel := "✏️ Своя"
+	if !containsAmount(winAmounts, currentAmount) {
+		customAmountLabel = fmt.Sprintf("✅ ✏️ %d", currentAmount)
+	}
+	amountButtons = append(amountButtons, gotgbot.InlineKeyboardButton{
+		Text:         customAmountLabel,
+		CallbackData: "settings:amount:custom",
+	})
 
-	rows := [][]gotgbot.InlineKeyboardButton{
-		prizeButtons,
-		amountButtons,
+	var costButtons []gotgbot.InlineKeyboardButton
+	for _, c := range spinCosts {
+		label := fmt.Sprintf("🎟 %d", c)
+		if c == currentCost {
+			label = "✅ " + label
+		}
+		costButtons = append(costButtons, gotgbot.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf("settings:cost:%d", c),
+		})
+	}
+
+	var shareButtons []gotgbot.InlineKeyboardButton
+	for _, sh := range jackpotShares {
+		label := fmt.Sprintf("%d%%", sh)
+		if sh == currentShare {
+			label = "✅ " + label
+		}
+		shareButtons = append(shareButtons, gotgbot.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf("settings:jackpot:%d", sh),
+		})
 	}
 
+	rows := [][]gotgbot.InlineKeyboardButton{prizeButtons}
+	rows = append(rows, customRows...)
+	rows = append(rows, [][]gotgbot.InlineKeyboardButton{
+		amountButtons,
+		costButtons,
+		shareButtons,
+		gameButtons,
+		{{Text: senderChatsLabel, CallbackData: "settings:senderchats:toggle"}},
+		{
+			{Text: "💰 Таблиця виплат", CallbackData: "settings:menu:payout"},
+			{Text: "🚦 Антиспам", CallbackData: "settings:menu:throttle"},
+		},
+		{
+			{Text: "🗓 Сезони", CallbackData: "settings:menu:seasons"},
+			{Text: "⭐ Рівні", CallbackData: "settings:menu:levels"},
+			{Text: "💳 Економіка", CallbackData: "settings:menu:economy"},
+		},
+		{
+			{Text: "🎟 Лотерея", CallbackData: "settings:menu:lottery"},
+			{Text: "🏦 Кредити", CallbackData: "settings:menu:loans"},
+		},
+	}...)
+
 	if isAdmin {
 		allowSettings, _ := s.repo.GetPermission(chatId, "settings")
 		allowReset, _ := s.repo.GetPermission(chatId, "reset")
@@ -202,3 +755,533 @@ func (s *SettingsService) buildSettingsMessage(chatId int64, isAdmin bool) (stri
 	keyboard := gotgbot.InlineKeyboardMarkup{InlineKeyboard: rows}
 	return builder.String(), keyboard, nil
 }
+
+func (s *SettingsService) toggleGame(chatId int64, key string) error {
+	game, ok := domain.ParseGame(key)
+	if !ok {
+		return nil
+	}
+	enabled, err := s.repo.GetEnabledGames(chatId)
+	if err != nil {
+		return err
+	}
+	games := make([]domain.Game, 0, len(domain.Games))
+	for _, g := range domain.Games {
+		if containsGame(enabled, g) != (g == game) {
+			games = append(games, g)
+		}
+	}
+	return s.repo.UpdateEnabledGames(games, chatId)
+}
+
+func (s *SettingsService) cyclePayout(chatId int64, combo domain.Combo) error {
+	if _, ok := comboLabels[combo]; !ok {
+		return nil
+	}
+	table, err := s.repo.GetPayoutTable(chatId)
+	if err != nil {
+		return err
+	}
+	next := payoutAmounts[0]
+	for i, a := range payoutAmounts {
+		if a == table[combo] && i+1 < len(payoutAmounts) {
+			next = payoutAmounts[i+1]
+			break
+		}
+	}
+	if next == 0 {
+		delete(table, combo)
+	} else {
+		table[combo] = next
+	}
+	return s.repo.UpdatePayoutTable(table, chatId)
+}
+
+func (s *SettingsService) buildPayoutMessage(chatId int64) (string, gotgbot.InlineKeyboardMarkup, error) {
+	table, err := s.repo.GetPayoutTable(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+
+	text := "💰 Таблиця виплат\n\n" +
+		"Комбінації перевіряються згори вниз, платить перша з сумою.\n" +
+		"Якщо жодна не зіграла, діє режим виграшу.\n" +
+		"Клацай, щоб змінити суму, 0 — вимкнено."
+
+	var rows [][]gotgbot.InlineKeyboardButton
+	for _, c := range domain.Combos {
+		rows = append(rows, []gotgbot.InlineKeyboardButton{{
+			Text:         fmt.Sprintf("%s: %d", comboLabels[c], table[c]),
+			CallbackData: fmt.Sprintf("settings:payout:%s", c),
+		}})
+	}
+	rows = append(rows, []gotgbot.InlineKeyboardButton{
+		{Text: "⬅️ Назад", CallbackData: "settings:menu:main"},
+	})
+
+	return text, gotgbot.InlineKeyboardMarkup{InlineKeyboard: rows}, nil
+}
+
+func (s *SettingsService) buildThrottleMessage(chatId int64) (string, gotgbot.InlineKeyboardMarkup, error) {
+	throttle, err := s.repo.GetThrottle(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	dailyLimit, err := s.repo.GetDailySpinLimit(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	timezone, err := s.repo.GetTimezone(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+
+	cooldownText := "нема"
+	if throttle.Cooldown > 0 {
+		cooldownText = fmt.Sprintf("%d с", int64(throttle.Cooldown.Seconds()))
+	}
+	burstText := "без ліміту"
+	if throttle.Burst > 0 {
+		burstText = fmt.Sprintf("%d за хвилину", throttle.Burst)
+	}
+	dailyText := "без ліміту"
+	if dailyLimit > 0 {
+		dailyText = fmt.Sprintf("%d", dailyLimit)
+	}
+	cleanupText := "ні"
+	if throttle.Cleanup {
+		cleanupText = "так"
+	}
+
+	text := fmt.Sprintf("🚦 Антиспам\n\n"+
+		"Спроби понад ліміт не зараховуються.\n\n"+
+		"⏱ Пауза між спробами: %s\n"+
+		"📈 Ліміт: %s\n"+
+		"🧹 Видаляти зайві й попереджати: %s\n"+
+		"📅 Спроб на день: %s (день за %s, змінити: /timezone)",
+		cooldownText, burstText, cleanupText, dailyText, timezone)
+
+	var cooldownButtons []gotgbot.InlineKeyboardButton
+	for _, c := range spinCooldowns {
+		label := fmt.Sprintf("⏱ %dс", c)
+		if time.Duration(c)*time.Second == throttle.Cooldown {
+			label = "✅ " + label
+		}
+		cooldownButtons = append(cooldownButtons, gotgbot.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf("settings:cooldown:%d", c),
+		})
+	}
+
+	var burstButtons []gotgbot.InlineKeyboardButton
+	for _, b := range spinBursts {
+		label := fmt.Sprintf("📈 %d/хв", b)
+		if b == 0 {
+			label = "📈 ∞"
+		}
+		if int(b) == throttle.Burst {
+			label = "✅ " + label
+		}
+		burstButtons = append(burstButtons, gotgbot.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf("settings:burst:%d", b),
+		})
+	}
+
+	var dailyButtons []gotgbot.InlineKeyboardButton
+	for _, d := range dailySpinLimits {
+		label := fmt.Sprintf("📅 %d", d)
+		if d == 0 {
+			label = "📅 ∞"
+		}
+		if d == dailyLimit {
+			label = "✅ " + label
+		}
+		dailyButtons = append(dailyButtons, gotgbot.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf("settings:daily:%d", d),
+		})
+	}
+
+	cleanupLabel := "🧹 Видаляти зайві: ні"
+	if throttle.Cleanup {
+		cleanupLabel = "🧹 Видаляти зайві: так"
+	}
+
+	rows := [][]gotgbot.InlineKeyboardButton{
+		cooldownButtons,
+		burstButtons,
+		{{Text: cleanupLabel, CallbackData: "settings:throttleclean:toggle"}},
+		dailyButtons,
+		{{Text: "⬅️ Назад", CallbackData: "settings:menu:main"}},
+	}
+	return text, gotgbot.InlineKeyboardMarkup{InlineKeyboard: rows}, nil
+}
+
+func (s *SettingsService) buildSeasonScheduleMessage(chatId int64) (string, gotgbot.InlineKeyboardMarkup, error) {
+	schedule, err := s.repo.GetSeasonSchedule(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	timezone, err := s.repo.GetTimezone(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+
+	current := "вручну через /reset"
+	switch schedule.Mode {
+	case domain.SeasonsWeekly:
+		current = "щопонеділка опівночі"
+	case domain.SeasonsMonthly:
+		current = "першого числа опівночі"
+	case domain.SeasonsOnDate:
+		if date, err := time.Parse(domain.SeasonEndDateLayout, schedule.EndDate); err == nil {
+			current = fmt.Sprintf("після %s", date.Format("02.01.2006"))
+		}
+	}
+
+	text := fmt.Sprintf("🗓 Сезони\n\n"+
+		"В кінці сезону бот публікує фінальний топ і чемпіона, архівує підсумки й обнуляє рахунки.\n\n"+
+		"Новий сезон: %s (час за %s, змінити: /timezone)", current, timezone)
+
+	var buttons []gotgbot.InlineKeyboardButton
+	for _, sch := range seasonSchedules {
+		label := sch.label
+		if sch.mode == schedule.Mode {
+			label = "✅ " + label
+		}
+		buttons = append(buttons, gotgbot.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf("settings:seasons:%s", sch.mode),
+		})
+	}
+	dateLabel := "📅 Дата"
+	if schedule.Mode == domain.SeasonsOnDate {
+		dateLabel = "✅ " + dateLabel
+	}
+
+	rows := [][]gotgbot.InlineKeyboardButton{
+		buttons,
+		{{Text: dateLabel, CallbackData: fmt.Sprintf("settings:seasons:%s", domain.SeasonsOnDate)}},
+		{{Text: "⬅️ Назад", CallbackData: "settings:menu:main"}},
+	}
+	return text, gotgbot.InlineKeyboardMarkup{InlineKeyboard: rows}, nil
+}
+
+func (s *SettingsService) buildLevelCurveMessage(chatId int64) (string, gotgbot.InlineKeyboardMarkup, error) {
+	current, err := s.repo.GetLevelCurve(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	curve := domain.LevelCurveByKey(current)
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "⭐ Рівні\n\n"+
+		"Досвід дається за кожну спробу (+%d) і за виграш (+%d) і не згорає між сезонами.\n\n"+
+		"Темп: %s", domain.XPPerSpin, domain.XPPerWin, curve.Name)
+	for _, level := range []int{2, 5, 10, 20} {
+		fmt.Fprintf(&builder, "\n%s %d рівень: %d XP", domain.LevelIcon(level), level, curve.XPFor(level))
+	}
+
+	var buttons []gotgbot.InlineKeyboardButton
+	for _, c := range domain.LevelCurves {
+		label := c.Name
+		if c.Key == curve.Key {
+			label = "✅ " + label
+		}
+		buttons = append(buttons, gotgbot.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf("settings:levelcurve:%s", c.Key),
+		})
+	}
+
+	rows := [][]gotgbot.InlineKeyboardButton{
+		buttons,
+		{{Text: "⬅️ Назад", CallbackData: "settings:menu:main"}},
+	}
+	return builder.String(), gotgbot.InlineKeyboardMarkup{InlineKeyboard: rows}, nil
+}
+
+func (s *SettingsService) buildEconomyMessage(chatId int64) (string, gotgbot.InlineKeyboardMarkup, error) {
+	dailyBonus, err := s.repo.GetDailyBonus(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	bailout, err := s.repo.GetBailout(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	transferLimits, err := s.repo.GetTransferLimits(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	betLimits, err := s.repo.GetBetLimits(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+
+	dailyText := "вимкнено"
+	if dailyBonus > 0 {
+		dailyText = fmt.Sprintf("%d, до %d за %d днів поспіль",
+			dailyBonus, domain.DailyBonus(dailyBonus, domain.DailyStreakCap+1), domain.DailyStreakCap+1)
+	}
+	bailoutText := "вимкнено"
+	if bailout.Enabled() {
+		bailoutText = fmt.Sprintf("з боргу від %d, раз на %s", -bailout.Threshold, formatWait(bailout.Cooldown))
+	}
+	betText := "вимкнено"
+	if betLimits.Enabled() {
+		betText = fmt.Sprintf("від %d до %d", betLimits.Min, betLimits.Max)
+	}
+	capText := "без ліміту"
+	if transferLimits.DailyCap > 0 {
+		capText = fmt.Sprintf("до %d на день", transferLimits.DailyCap)
+	}
+	text := fmt.Sprintf("💳 Економіка\n\n"+
+		"Бонуси, списання й перекази стосуються балансу %s.\n\n"+
+		"🎁 Щоденний бонус (/daily): %s\n"+
+		"🆘 Банкрутство (/bailout): %s\n"+
+		"💸 Перекази (/give): %s, баланс відправника не нижче %d\n"+
+		"🎯 Ставки (/bet): %s",
+		domain.CoinGame.Emoji(), dailyText, bailoutText, capText, transferLimits.Floor, betText)
+
+	var dailyButtons []gotgbot.InlineKeyboardButton
+	for _, d := range dailyBonuses {
+		label := fmt.Sprintf("🎁 %d", d)
+		if d == 0 {
+			label = "🎁 вимк"
+		}
+		if d == dailyBonus {
+			label = "✅ " + label
+		}
+		dailyButtons = append(dailyButtons, gotgbot.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf("settings:dailybonus:%d", d),
+		})
+	}
+
+	var bailoutButtons []gotgbot.InlineKeyboardButton
+	for _, t := range bailoutThresholds {
+		label := fmt.Sprintf("🆘 %d", t)
+		if t == 0 {
+			label = "🆘 вимк"
+		}
+		if t == bailout.Threshold {
+			label = "✅ " + label
+		}
+		bailoutButtons = append(bailoutButtons, gotgbot.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf("settings:bailout:%d", t),
+		})
+	}
+	var cooldownButtons []gotgbot.InlineKeyboardButton
+	for _, d := range bailoutCooldownDays {
+		label := fmt.Sprintf("⏳ %d дн", d)
+		if time.Duration(d)*24*time.Hour == bailout.Cooldown {
+			label = "✅ " + label
+		}
+		cooldownButtons = append(cooldownButtons, gotgbot.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf("settings:bailoutcd:%d", d),
+		})
+	}
+
+	var capButtons []gotgbot.InlineKeyboardButton
+	for _, c := range transferDailyCaps {
+		label := fmt.Sprintf("💸 %d/день", c)
+		if c == 0 {
+			label = "💸 ∞"
+		}
+		if c == transferLimits.DailyCap {
+			label = "✅ " + label
+		}
+		capButtons = append(capButtons, gotgbot.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf("settings:transfercap:%d", c),
+		})
+	}
+	var floorButtons []gotgbot.InlineKeyboardButton
+	for _, f := range transferFloors {
+		label := fmt.Sprintf("🧱 від %d", f)
+		if f == transferLimits.Floor {
+			label = "✅ " + label
+		}
+		floorButtons = append(floorButtons, gotgbot.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf("settings:transferfloor:%d", f),
+		})
+	}
+
+	var betMinButtons []gotgbot.InlineKeyboardButton
+	for _, m := range betMins {
+		label := fmt.Sprintf("🎯 від %d", m)
+		if m == betLimits.Min {
+			label = "✅ " + label
+		}
+		betMinButtons = append(betMinButtons, gotgbot.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf("settings:betmin:%d", m),
+		})
+	}
+	var betMaxButtons []gotgbot.InlineKeyboardButton
+	for _, m := range betMaxes {
+		label := fmt.Sprintf("🎯 до %d", m)
+		if m == 0 {
+			label = "🎯 вимк"
+		}
+		if m == betLimits.Max {
+			label = "✅ " + label
+		}
+		betMaxButtons = append(betMaxButtons, gotgbot.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf("settings:betmax:%d", m),
+		})
+	}
+
+	rows := [][]gotgbot.InlineKeyboardButton{
+		dailyButtons,
+		bailoutButtons,
+		cooldownButtons,
+		capButtons,
+		floorButtons,
+		betMinButtons,
+		betMaxButtons,
+		{{Text: "⬅️ Назад", CallbackData: "settings:menu:main"}},
+	}
+	return text, gotgbot.InlineKeyboardMarkup{InlineKeyboard: rows}, nil
+}
+
+func (s *SettingsService) buildLotteryMessage(chatId int64) (string, gotgbot.InlineKeyboardMarkup, error) {
+	schedule, err := s.repo.GetLotterySchedule(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	price, err := s.repo.GetTicketPrice(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	timezone, err := s.repo.GetTimezone(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+
+	current := "вимкнено"
+	switch schedule.Mode {
+	case domain.LotteryDaily:
+		current = fmt.Sprintf("щодня о %d:00", schedule.Hour)
+	case domain.LotteryWeekly:
+		current = fmt.Sprintf("щонеділі о %d:00", schedule.Hour)
+	}
+
+	text := fmt.Sprintf("🎟 Лотерея\n\n"+
+		"Гравці купують квитки (/ticket) за баланс %s, гроші йдуть у банк. Бот розігрує весь банк між квитками, "+
+		"а незабраний до наступного розіграшу виграш переходить далі.\n\n"+
+		"Розіграш: %s (час за %s, змінити: /timezone)\n"+
+		"🎫 Квиток: %d", domain.CoinGame.Emoji(), current, timezone, price)
+
+	var scheduleButtons []gotgbot.InlineKeyboardButton
+	for _, sch := range lotterySchedules {
+		label := sch.label
+		if sch.mode == schedule.Mode {
+			label = "✅ " + label
+		}
+		scheduleButtons = append(scheduleButtons, gotgbot.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf("settings:lottery:%s", sch.mode),
+		})
+	}
+	var hourButtons []gotgbot.InlineKeyboardButton
+	for _, h := range lotteryHours {
+		label := fmt.Sprintf("⏰ %d:00", h)
+		if h == schedule.Hour {
+			label = "✅ " + label
+		}
+		hourButtons = append(hourButtons, gotgbot.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf("settings:lotteryhour:%d", h),
+		})
+	}
+	var priceButtons []gotgbot.InlineKeyboardButton
+	for _, p := range ticketPrices {
+		label := fmt.Sprintf("🎫 %d", p)
+		if p == price {
+			label = "✅ " + label
+		}
+		priceButtons = append(priceButtons, gotgbot.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf("settings:ticketprice:%d", p),
+		})
+	}
+
+	rows := [][]gotgbot.InlineKeyboardButton{
+		scheduleButtons,
+		hourButtons,
+		priceButtons,
+		{{Text: "⬅️ Назад", CallbackData: "settings:menu:main"}},
+	}
+	return text, gotgbot.InlineKeyboardMarkup{InlineKeyboard: rows}, nil
+}
+
+func (s *SettingsService) buildLoansMessage(chatId int64) (string, gotgbot.InlineKeyboardMarkup, error) {
+	terms, err := s.repo.GetLoanTerms(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+
+	limit := "вимкнено"
+	if terms.Enabled() {
+		limit = fmt.Sprintf("до %d", terms.Max)
+	}
+	text := fmt.Sprintf("🏦 Кредити\n\n"+
+		"Гравці в мінусі позичають монети %s командою /loan. Раз на добу до боргу додаються відсотки, "+
+		"поки він не виросте в %d разів, а частина кожного виграшу в %s автоматично йде на його погашення.\n\n"+
+		"Кредит: %s\n"+
+		"📈 Відсоток: %d%% на день\n"+
+		"💸 З виграшу на борг: %d%%",
+		domain.CoinGame.Emoji(), domain.MaxDebtFactor, domain.CoinGame.Emoji(), limit, terms.Rate, terms.RepayShare)
+
+	var maxButtons []gotgbot.InlineKeyboardButton
+	for _, m := range loanMaxes {
+		label := fmt.Sprintf("🏦 %d", m)
+		if m == 0 {
+			label = "Вимк"
+		}
+		if m == terms.Max {
+			label = "✅ " + label
+		}
+		maxButtons = append(maxButtons, gotgbot.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf("settings:loanmax:%d", m),
+		})
+	}
+	var rateButtons []gotgbot.InlineKeyboardButton
+	for _, r := range loanRates {
+		label := fmt.Sprintf("📈 %d%%", r)
+		if r == terms.Rate {
+			label = "✅ " + label
+		}
+		rateButtons = append(rateButtons, gotgbot.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf("settings:loanrate:%d", r),
+		})
+	}
+	var repayButtons []gotgbot.InlineKeyboardButton
+	for _, sh := range loanRepayShares {
+		label := fmt.Sprintf("💸 %d%%", sh)
+		if sh == terms.RepayShare {
+			label = "✅ " + label
+		}
+		repayButtons = append(repayButtons, gotgbot.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf("settings:loanrepay:%d", sh),
+		})
+	}
+
+	rows := [][]gotgbot.InlineKeyboardButton{
+		maxButtons,
+		rateButtons,
+		repayButtons,
+		{{Text: "⬅️ Назад", CallbackData: "settings:menu:main"}},
+	}
+	return text, gotgbot.InlineKeyboardMarkup{InlineKeyboard: rows}, nil
+}
@@ -2,11 +2,16 @@ package service
 
 import (
 	"bandit-counter-bot/internal/cache"
+	"bandit-counter-bot/internal/domain"
 	"bandit-counter-bot/internal/repository"
 	"database/sql"
 	"errors"
 	"fmt"
+	"math"
 	"math/rand"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
@@ -16,41 +21,247 @@ type SlotService struct {
 	statsRepo    *repository.UserStatsRepo
 	settingsRepo *repository.SettingsRepo
 	messageCache *cache.SlotMessageCache
+	throttle     *cache.SpinThrottle
 	cleaner      *MessageCleaner
+	levels       *LevelService
+	achievements *AchievementService
+	bailouts     *BailoutService
+	duels        *DuelService
+	loans        *LoanService
 }
 
-func NewSlotService(userRepo *repository.UserStatsRepo, settingsRepo *repository.SettingsRepo, messageCache *cache.SlotMessageCache, cleaner *MessageCleaner) *SlotService {
-	return &SlotService{statsRepo: userRepo, settingsRepo: settingsRepo, messageCache: messageCache, cleaner: cleaner}
+func NewSlotService(userRepo *repository.UserStatsRepo, settingsRepo *repository.SettingsRepo, messageCache *cache.SlotMessageCache, throttle *cache.SpinThrottle, cleaner *MessageCleaner, levels *LevelService, achievements *AchievementService, bailouts *BailoutService, duels *DuelService, loans *LoanService) *SlotService {
+	return &SlotService{statsRepo: userRepo, settingsRepo: settingsRepo, messageCache: messageCache, throttle: throttle, cleaner: cleaner, levels: levels, achievements: achievements, bailouts: bailouts, duels: duels, loans: loans}
 }
 
 func (s *SlotService) HandleSlot(b *gotgbot.Bot, ctx *ext.Context) error {
 	msg := ctx.EffectiveMessage
+	game, ok := domain.GameByEmoji(msg.Dice.Emoji)
+	if !ok {
+		return nil
+	}
+
+	enabledGames, err := s.settingsRepo.GetEnabledGames(msg.Chat.Id)
+	if err != nil {
+		return err
+	}
+	if !containsGame(enabledGames, game) {
+		return nil
+	}
+
+	from := messageSender(msg)
+	if from.isChat {
+		ignore, err := s.settingsRepo.GetIgnoreSenderChats(msg.Chat.Id)
+		if err != nil {
+			return err
+		}
+		if ignore {
+			return nil
+		}
+	}
+
+	allowed, reserved, err := s.checkThrottle(b, msg, from)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return nil
+	}
+	counted := false
+	if reserved {
+		defer func() {
+			if !counted {
+				s.throttle.Release(msg.Chat.Id, from.id, msgTime(msg))
+			}
+		}()
+	}
+
+	spinCost, costSet, err := s.settingsRepo.GetSpinCost(msg.Chat.Id)
+	if err != nil {
+		return err
+	}
+
+	dailyLimit, err := s.settingsRepo.GetDailySpinLimit(msg.Chat.Id)
+	if err != nil {
+		return err
+	}
+	loc, err := chatLocation(s.settingsRepo, msg.Chat.Id)
+	if err != nil {
+		return err
+	}
+
 	value := int(msg.Dice.Value)
+	spin := domain.Spin{
+		ChatId:     msg.Chat.Id,
+		UserId:     from.id,
+		Game:       game,
+		Username:   from.name,
+		Handle:     from.handle,
+		MessageId:  msg.MessageId,
+		Value:      value,
+		At:         msgTime(msg),
+		Cost:       spinCost,
+		FreeWins:   !costSet,
+		DailyLimit: dailyLimit,
+		DayStart:   domain.StartOfDay(msgTime(msg), loc),
+	}
+	if game == domain.GameSlot {
+		if err := s.resolveSlot(&spin, value); err != nil {
+			return err
+		}
+		spin.DuelWindow = duelRollWindow
+		terms, err := s.settingsRepo.GetLoanTerms(msg.Chat.Id)
+		if err != nil {
+			return err
+		}
+		spin.LoanRepayShare = terms.RepayShare
+	} else {
+		spin.Payout = game.Payout(value)
+	}
 
-	prizeValues, err := s.settingsRepo.GetPrizeValues(msg.Chat.Id)
+	result, err := s.statsRepo.Spin(spin)
 	if err != nil {
 		return err
 	}
+	if result.Duplicate || result.OverQuota {
+		return nil
+	}
+	counted = true
+	if result.Stake > 0 {
+		spin.ApplyStake(result.Stake)
+	}
 
-	winAmount, err := s.settingsRepo.GetWinAmount(msg.Chat.Id)
+	if spin.Payout == 0 && result.JackpotWon == 0 {
+		s.messageCache.Add(msg.Chat.Id, msg.MessageId)
+	} else {
+		s.sendWinReaction(b, msg)
+	}
+	if result.JackpotWon > 0 {
+		text := fmt.Sprintf("💰💰💰 ДЖЕКПОТ!\n\n%s зриває банк і забирає %d 🤑", from.name, result.JackpotWon)
+		_, _ = msg.Reply(b, text, &gotgbot.SendMessageOpts{})
+	}
+	if spin.Stake > 0 {
+		if spin.Payout > 0 {
+			text := fmt.Sprintf("🎯 %s виграє ставку %d: +%d", from.name, spin.Stake, spin.Payout)
+			_, _ = msg.Reply(b, text, &gotgbot.SendMessageOpts{})
+		} else {
+			s.sendEphemeral(b, msg, fmt.Sprintf("💸 %s програє ставку %d", from.name, spin.Stake))
+		}
+	}
+	if game == domain.GameSlot {
+		s.duels.AnnounceRoll(b, msg, result)
+		s.sendLoanRepayment(b, msg, from, result)
+	}
+	if err := s.levels.AnnounceLevelUp(b, msg, spin, result); err != nil {
+		return err
+	}
+	return s.achievements.CheckSpin(b, msg, spin)
+}
+
+// sendLoanRepayment tells the player what their win paid off their loan.
+func (s *SlotService) sendLoanRepayment(b *gotgbot.Bot, msg *gotgbot.Message, from sender, result domain.SpinResult) {
+	if result.LoanRepaid == 0 {
+		return
+	}
+	if result.LoanDebt == 0 {
+		_, _ = msg.Reply(b, fmt.Sprintf("🏦 %s віддає %d з виграшу і закриває кредит!", from.name, result.LoanRepaid), &gotgbot.SendMessageOpts{})
+		return
+	}
+	s.sendEphemeral(b, msg, fmt.Sprintf("🏦 %s віддає %d з виграшу в рахунок кредиту, лишилось %d", from.name, result.LoanRepaid, result.LoanDebt))
+}
+
+// resolveSlot fills in the payout and jackpot fields of a 🎰 spin from the chat settings.
+func (s *SlotService) resolveSlot(spin *domain.Spin, value int) error {
+	prizeValues, err := s.settingsRepo.GetPrizeValues(spin.ChatId)
+	if err != nil {
+		return err
+	}
+
+	winAmount, err := s.settingsRepo.GetWinAmount(spin.ChatId)
 	if err != nil {
 		return err
 	}
 
-	win := false
+	payoutTable, err := s.settingsRepo.GetPayoutTable(spin.ChatId)
+	if err != nil {
+		return err
+	}
+
+	jackpotShare, err := s.settingsRepo.GetJackpotShare(spin.ChatId)
+	if err != nil {
+		return err
+	}
+
+	spin.Payout = slotPayout(value, prizeValues, winAmount, payoutTable)
+	spin.JackpotShare = jackpotShare
+	spin.Jackpot = domain.ComboSevens.Matches(domain.DecodeSlot(value))
+	return nil
+}
+
+// slotPayout resolves what a dice value pays: a matching combination from the
+// payout table wins first, otherwise the prize mode values pay winAmount.
+func slotPayout(value int, prizeValues []int, winAmount int64, table domain.PayoutTable) int64 {
+	if _, amount, ok := table.Payout(domain.DecodeSlot(value)); ok {
+		return amount
+	}
 	for _, v := range prizeValues {
 		if value == v {
-			win = true
-			break
+			return winAmount
 		}
 	}
-	if !win {
+	return 0
+}
+
+// checkThrottle enforces the chat's cooldown and burst limit. An allowed spin
+// is reserved against them, reported by the second result, and must be
+// released unless Spin counts it. A rejected spin is not counted and, if the
+// chat wants it, deleted later with a short warning.
+func (s *SlotService) checkThrottle(b *gotgbot.Bot, msg *gotgbot.Message, from sender) (bool, bool, error) {
+	throttle, err := s.settingsRepo.GetThrottle(msg.Chat.Id)
+	if err != nil {
+		return false, false, err
+	}
+	if !throttle.Enabled() {
+		return true, false, nil
+	}
+
+	at := msgTime(msg)
+	allowed, wait := s.throttle.Allow(msg.Chat.Id, from.id, at, throttle.Cooldown, throttle.Burst)
+	if allowed {
+		return true, true, nil
+	}
+	// a redelivered update that was already counted is not spam
+	counted, err := s.statsRepo.IsCounted(msg.Chat.Id, msg.MessageId)
+	if err != nil || counted {
+		return false, false, err
+	}
+
+	if throttle.Cleanup {
 		s.messageCache.Add(msg.Chat.Id, msg.MessageId)
+		if s.throttle.ShouldWarn(msg.Chat.Id, from.id, at) {
+			seconds := int64(math.Ceil(wait.Seconds()))
+			s.sendEphemeral(b, msg, fmt.Sprintf("⏳ %s, не спам! Наступна спроба зарахується через %d с",
+				from.name, seconds))
+		}
 	}
-	if win {
-		s.sendWinReaction(b, msg)
+	return false, false, nil
+}
+
+const ephemeralTTL = 10 * time.Second
+
+// sendEphemeral replies with a message that deletes itself after ephemeralTTL.
+func (s *SlotService) sendEphemeral(b *gotgbot.Bot, msg *gotgbot.Message, text string) {
+	sent, err := msg.Reply(b, text, &gotgbot.SendMessageOpts{})
+	if err != nil {
+		return
 	}
-	return s.statsRepo.Spin(msg.Chat.Id, msg.From.Id, msg.From.FirstName, win, winAmount)
+	time.AfterFunc(ephemeralTTL, func() {
+		_, _ = sent.Delete(b, nil)
+	})
+}
+
+func msgTime(msg *gotgbot.Message) time.Time {
+	return time.Unix(msg.Date, 0)
 }
 
 var winReactionEmojis = []string{"🎉", "🔥", "❤", "👍", "🏆", "⚡", "🍾", "👏", "🤩", "😍"}
@@ -65,21 +276,142 @@ func (s *SlotService) sendWinReaction(b *gotgbot.Bot, msg *gotgbot.Message) {
 
 func (s *SlotService) HandleMeCommand(b *gotgbot.Bot, ctx *ext.Context) error {
 	chatId := ctx.EffectiveMessage.Chat.Id
-	userId := ctx.EffectiveMessage.From.Id
-	stats, err := s.statsRepo.GetPersonalStats(chatId, userId)
+	from := messageSender(ctx.EffectiveMessage)
+	enabledGames, err := s.settingsRepo.GetEnabledGames(chatId)
+	if err != nil {
+		return err
+	}
+	text, keyboard, err := s.buildMeMessage(chatId, from.id, defaultGame(enabledGames), enabledGames)
+	if err != nil {
+		return err
+	}
+	opts := &gotgbot.SendMessageOpts{}
+	// Buttons are pressed by users, never by a chat, so a chat's card gets
+	// none: nobody could pass the owner check.
+	if len(keyboard.InlineKeyboard) > 0 && !from.isChat {
+		opts.ReplyMarkup = keyboard
+	}
+	_, _ = ctx.EffectiveMessage.Reply(b, text, opts)
+	return nil
+}
+
+func (s *SlotService) HandleMeCallback(b *gotgbot.Bot, ctx *ext.Context) error {
+	cb := ctx.CallbackQuery
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) < 3 {
+		cb.Answer(b, nil)
+		return nil
+	}
+
+	ownerId, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || ownerId != cb.From.Id {
+		cb.Answer(b, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "це не твоя статистика, напиши /me",
+		})
+		return nil
+	}
+	game, ok := domain.ParseGame(parts[2])
+	if !ok {
+		cb.Answer(b, nil)
+		return nil
+	}
+
+	chatId := cb.Message.GetChat().Id
+	enabledGames, err := s.settingsRepo.GetEnabledGames(chatId)
+	if err != nil {
+		cb.Answer(b, nil)
+		return err
+	}
+	text, keyboard, err := s.buildMeMessage(chatId, ownerId, game, enabledGames)
+	if err != nil {
+		cb.Answer(b, nil)
+		return err
+	}
+
+	_, _, _ = cb.Message.EditText(b, text, &gotgbot.EditMessageTextOpts{
+		ReplyMarkup: keyboard,
+	})
+	cb.Answer(b, nil)
+	return nil
+}
+
+func (s *SlotService) buildMeMessage(chatId int64, userId int64, game domain.Game, enabledGames []domain.Game) (string, gotgbot.InlineKeyboardMarkup, error) {
+	var keyboard gotgbot.InlineKeyboardMarkup
+	if row := buildGameRow(enabledGames, game, fmt.Sprintf("me:%d:%%s", userId)); row != nil {
+		keyboard.InlineKeyboard = [][]gotgbot.InlineKeyboardButton{row}
+	}
+
+	stats, err := s.statsRepo.GetPersonalStats(chatId, userId, game)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			ctx.EffectiveMessage.Reply(b, "ти хто ваше", &gotgbot.SendMessageOpts{})
-			return nil
+			return "ти хто ваше", keyboard, nil
 		}
-		return err
+		return "", keyboard, err
+	}
+
+	spinsLabel := "🎰 Прокрутів"
+	if game != domain.GameSlot {
+		spinsLabel = game.Emoji() + " Кидків"
 	}
 	text := fmt.Sprintf(
-		"🎰 Прокрутів: %d\n🍾 Виграшів: %d\n💸 Баланс: %d\n⭐ Місце в чаті: %d\n🍀 Удача: %.1f%%\n🔥 Серія перемог: %d / макс %d\n💀 Серія поразок: %d / макс %d",
-		stats.Spins, stats.Wins, stats.Balance, stats.Rank, stats.Luck,
+		"%s: %d\n🍾 Виграшів: %d\n💸 Баланс: %d\n⭐ Місце в чаті: %d\n🍀 Удача: %.1f%%\n🔥 Серія перемог: %d / макс %d\n💀 Серія поразок: %d / макс %d",
+		spinsLabel, stats.Spins, stats.Wins, stats.Balance, stats.Rank, stats.Luck,
 		stats.CurrentStreak, stats.MaxStreak, stats.CurrentLossStreak, stats.MaxLossStreak)
-	_, _ = ctx.EffectiveMessage.Reply(b, text, &gotgbot.SendMessageOpts{})
-	return nil
+
+	quotaLine, err := s.quotaLine(chatId, userId)
+	if err != nil {
+		return "", keyboard, err
+	}
+	levelLine, err := s.levels.LevelLine(chatId, userId)
+	if err != nil {
+		return "", keyboard, err
+	}
+	badges, err := s.achievements.Count(chatId, userId)
+	if err != nil {
+		return "", keyboard, err
+	}
+	bailouts, err := s.bailouts.Count(chatId, userId)
+	if err != nil {
+		return "", keyboard, err
+	}
+	duelWins, duelLosses, err := s.duels.Record(chatId, userId)
+	if err != nil {
+		return "", keyboard, err
+	}
+	debt, err := s.loans.Debt(chatId, userId)
+	if err != nil {
+		return "", keyboard, err
+	}
+	text += "\n" + levelLine
+	text += fmt.Sprintf("\n🏅 Ачивок: %d з %d (/achievements)", badges, len(domain.Achievements))
+	if bailouts > 0 {
+		text += fmt.Sprintf("\n🤡 Банкрутств: %d", bailouts)
+	}
+	if duelWins+duelLosses > 0 {
+		text += fmt.Sprintf("\n⚔️ Дуелі: %d перемог, %d поразок", duelWins, duelLosses)
+	}
+	if debt > 0 {
+		text += fmt.Sprintf("\n🏦 Борг за кредитом: %d", debt)
+	}
+	return text + quotaLine, keyboard, nil
+}
+
+// quotaLine describes how many counted spins the player has left today, or
+// nothing when the chat has no daily limit.
+func (s *SlotService) quotaLine(chatId int64, userId int64) (string, error) {
+	limit, err := s.settingsRepo.GetDailySpinLimit(chatId)
+	if err != nil || limit == 0 {
+		return "", err
+	}
+	loc, err := chatLocation(s.settingsRepo, chatId)
+	if err != nil {
+		return "", err
+	}
+	used, err := s.statsRepo.CountSpinsSince(chatId, userId, domain.StartOfDay(time.Now(), loc))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("\n🎟 Спроб на сьогодні: %d з %d", max(limit-used, 0), limit), nil
 }
 
 func (s *SlotService) HandleCleanCommand(b *gotgbot.Bot, ctx *ext.Context) error {
@@ -97,8 +429,18 @@ func (s *SlotService) HandleHelpCommand(b *gotgbot.Bot, ctx *ext.Context) error
 	text := "🎰 Доступні команди:\n\n" +
 		"/me - моя статистика\n" +
 		"/stats - рейтинг гравців\n" +
+		"/achievements - мої ачивки\n" +
+		"/daily - щоденний бонус\n" +
+		"/bailout - оголосити банкрутство\n" +
+		"/give - переказати монети гравцю\n" +
+		"/bet - ставка на наступну крутілку\n" +
+		"/duel - виклик на дуель (у відповідь)\n" +
+		"/tournament - турнір і його таблиця\n" +
+		"/ticket - квитки лотереї\n" +
+		"/loan - кредит під відсотки для тих, хто в мінусі\n" +
 		"/settings - налаштування крутілки\n" +
-		"/reset - скинути статистику чату\n" +
+		"/timezone - часовий пояс чату\n" +
+		"/reset - закрити сезон і почати новий\n" +
 		"/clean - видалити програшні повідомлення\n" +
 		"/help - список команд"
 	_, _ = ctx.EffectiveMessage.Reply(b, text, &gotgbot.SendMessageOpts{})
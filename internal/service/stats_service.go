@@ -7,6 +7,7 @@ import (
 	"math"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
@@ -14,20 +15,46 @@ import (
 
 const statsPageSize = 10
 
+// myStatsPage asks for the page the requesting player is on; it travels as
+// "me" in the callback data.
+const myStatsPage = -1
+
+var periodLabels = map[domain.Period]string{
+	domain.PeriodDay:   "Сьогодні",
+	domain.PeriodWeek:  "Тиждень",
+	domain.PeriodMonth: "Місяць",
+	domain.PeriodAll:   "Весь час",
+}
+
 type StatsService struct {
-	statsRepo *repository.UserStatsRepo
+	statsRepo    *repository.UserStatsRepo
+	settingsRepo *repository.SettingsRepo
+	jackpotRepo  *repository.JackpotRepo
+	seasonRepo   *repository.SeasonRepo
+	levels       *LevelService
+	bailouts     *BailoutService
+	duels        *DuelService
+	loans        *LoanService
 }
 
-func NewStatsService(statsRepo *repository.UserStatsRepo) *StatsService {
-	return &StatsService{statsRepo: statsRepo}
+func NewStatsService(statsRepo *repository.UserStatsRepo, settingsRepo *repository.SettingsRepo, jackpotRepo *repository.JackpotRepo, seasonRepo *repository.SeasonRepo, levels *LevelService, bailouts *BailoutService, duels *DuelService, loans *LoanService) *StatsService {
+	return &StatsService{statsRepo: statsRepo, settingsRepo: settingsRepo, jackpotRepo: jackpotRepo, seasonRepo: seasonRepo, levels: levels, bailouts: bailouts, duels: duels, loans: loans}
 }
 
 func (s *StatsService) HandleStatsCommand(b *gotgbot.Bot, ctx *ext.Context) error {
 	chatId := ctx.EffectiveMessage.Chat.Id
-	text, keyboard, err := s.buildStatsMessage(chatId, "rich", 0)
+	from := messageSender(ctx.EffectiveMessage)
+	enabledGames, err := s.settingsRepo.GetEnabledGames(chatId)
+	if err != nil {
+		return err
+	}
+	text, keyboard, err := s.buildStatsMessage(chatId, from.id, defaultGame(enabledGames), domain.PeriodAll, "rich", 0)
 	if err != nil {
 		return err
 	}
+	if from.isChat {
+		keyboard = withoutMyPage(keyboard)
+	}
 	_, _ = ctx.EffectiveMessage.Reply(b, text, &gotgbot.SendMessageOpts{
 		ReplyMarkup: keyboard,
 	})
@@ -42,14 +69,33 @@ func (s *StatsService) HandleStatsCallback(b *gotgbot.Bot, ctx *ext.Context) err
 		return nil
 	}
 
+	// The data is stats:<game>:<period>:<view>:<page>. Keyboards sent before
+	// periods existed carry no period part, and the oldest no game either.
+	game := domain.GameSlot
+	period := domain.PeriodAll
+	if len(parts) > 3 {
+		if g, ok := domain.ParseGame(parts[1]); ok {
+			game = g
+		}
+		parts = parts[1:]
+	}
+	if len(parts) > 3 {
+		if p, ok := domain.ParsePeriod(parts[1]); ok {
+			period = p
+		}
+		parts = parts[1:]
+	}
 	view := parts[1]
 	page, err := strconv.Atoi(parts[2])
 	if err != nil {
 		page = 0
 	}
+	if parts[2] == "me" {
+		page = myStatsPage
+	}
 
 	chatId := cb.Message.GetChat().Id
-	text, keyboard, err := s.buildStatsMessage(chatId, view, page)
+	text, keyboard, err := s.buildStatsMessage(chatId, cb.From.Id, game, period, view, page)
 	if err != nil {
 		cb.Answer(b, nil)
 		return err
@@ -62,100 +108,198 @@ func (s *StatsService) HandleStatsCallback(b *gotgbot.Bot, ctx *ext.Context) err
 	return nil
 }
 
-func (s *StatsService) buildStatsMessage(chatId int64, view string, page int) (string, gotgbot.InlineKeyboardMarkup, error) {
-	var stats []domain.RatingStats
-	var err error
-	var title string
+func (s *StatsService) buildStatsMessage(chatId, userId int64, game domain.Game, period domain.Period, view string, page int) (string, gotgbot.InlineKeyboardMarkup, error) {
+	if view == "seasons" {
+		return s.buildSeasonsMessage(chatId, game, period, page)
+	}
+	if number, ok := parseSeasonView(view); ok {
+		return s.buildSeasonStandingsMessage(chatId, game, period, number, page)
+	}
+	if view == "duelists" {
+		return s.buildDuelistsMessage(chatId, userId, game, period, page)
+	}
+	if view == "creditors" {
+		return s.buildCreditorsMessage(chatId, userId, game, period, page)
+	}
 
+	var title string
 	switch view {
-	case "rich":
-		stats, err = s.statsRepo.GetRichStats(chatId)
-		title = "🎩 Багатії"
 	case "debtors":
-		stats, err = s.statsRepo.GetDebtorsStats(chatId)
 		title = "🧙 Боржники"
 	case "lucky":
-		stats, err = s.statsRepo.GetLuckyStats(chatId)
 		title = "🍀 Везунчики"
 	case "streaks":
-		stats, err = s.statsRepo.GetStreakStats(chatId)
 		title = "🔥 Серії"
 	default:
-		stats, err = s.statsRepo.GetRichStats(chatId)
 		view = "rich"
 		title = "🎩 Багатії"
 	}
+	if period != domain.PeriodAll {
+		title += " · " + strings.ToLower(periodLabels[period])
+	}
+
+	q, err := s.ratingQuery(chatId, game, period, domain.Rating(view))
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	total, err := s.statsRepo.CountRating(q)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	me, myPosition, onBoard, err := s.statsRepo.GetRatingEntry(q, userId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	if page == myStatsPage && onBoard {
+		page = myPosition / statsPageSize
+	}
+	page, totalPages := clampPage(page, total)
+	stats, err := s.statsRepo.GetRatingPage(q, statsPageSize, page*statsPageSize)
 	if err != nil {
 		return "", gotgbot.InlineKeyboardMarkup{}, err
 	}
 
-	totalPages := int(math.Ceil(float64(len(stats)) / float64(statsPageSize)))
-	if totalPages == 0 {
-		totalPages = 1
+	userIds := []int64{userId}
+	for _, u := range stats {
+		userIds = append(userIds, u.UserId)
 	}
-	if page < 0 {
-		page = 0
+	levels, err := s.levels.Levels(chatId, userIds)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
 	}
-	if page >= totalPages {
-		page = totalPages - 1
+	if view == "debtors" {
+		bailouts, err := s.bailouts.Counts(chatId, userIds)
+		if err != nil {
+			return "", gotgbot.InlineKeyboardMarkup{}, err
+		}
+		for i := range stats {
+			stats[i].Bailouts = bailouts[stats[i].UserId]
+		}
+		me.Bailouts = bailouts[userId]
 	}
 
-	start := page * statsPageSize
-	end := start + statsPageSize
-	if end > len(stats) {
-		end = len(stats)
+	enabledGames, err := s.settingsRepo.GetEnabledGames(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
 	}
 
 	var builder strings.Builder
-	builder.WriteString(title + "\n\n")
+	if game == domain.GameSlot {
+		pool, err := s.jackpotRepo.GetPool(chatId)
+		if err != nil {
+			return "", gotgbot.InlineKeyboardMarkup{}, err
+		}
+		builder.WriteString(title + "\n")
+		fmt.Fprintf(&builder, "🏦 Джекпот: %d\n\n", pool)
+	} else {
+		fmt.Fprintf(&builder, "%s · %s %s\n\n", title, game.Emoji(), gameLabels[game])
+	}
 
 	if len(stats) == 0 {
-		builder.WriteString("порожняк")
+		builder.WriteString("порожняк\n")
+	}
+	for _, u := range stats {
+		builder.WriteString(formatRatingLine(view, game, u, levels[u.UserId]) + "\n")
+	}
+
+	if onBoard {
+		builder.WriteString("\n📍 Ти: " + formatRatingLine(view, game, me, levels[userId]))
 	} else {
-		pageStats := stats[start:end]
-		for _, u := range pageStats {
-			switch view {
-			case "lucky":
-				fmt.Fprintf(&builder, "%d. 👤 %s — 🍀 %.1f%%, 🎰 %d, 🍾 %d\n",
-					u.Rank, u.Username, u.Luck, u.Spins, u.Wins)
-			case "streaks":
-				fmt.Fprintf(&builder, "%d. 👤 %s — 🔥 %d, 💀 %d, 🎰 %d\n",
-					u.Rank, u.Username, u.MaxStreak, u.MaxLossStreak, u.Spins)
-			default:
-				fmt.Fprintf(&builder, "%d. 👤 %s — 💸 %d, 🎰 %d, 🍾 %d\n",
-					u.Rank, u.Username, u.Balance, u.Spins, u.Wins)
-			}
-		}
+		builder.WriteString("\n📍 Тебе ще нема в цьому рейтингу")
 	}
 
 	if totalPages > 1 {
 		fmt.Fprintf(&builder, "\nСторінка %d/%d", page+1, totalPages)
 	}
 
-	keyboard := buildStatsKeyboard(view, page, totalPages)
+	keyboard := buildStatsKeyboard(enabledGames, game, period, view, page, totalPages)
 	return builder.String(), keyboard, nil
 }
 
-func buildStatsKeyboard(activeView string, page, totalPages int) gotgbot.InlineKeyboardMarkup {
+// formatRatingLine renders one leaderboard row; the player's level icon stands
+// in for the generic 👤 when the level is known.
+func formatRatingLine(view string, game domain.Game, u domain.RatingStats, level int) string {
+	icon := "👤"
+	if level > 0 {
+		icon = domain.LevelIcon(level)
+	}
+	switch view {
+	case "debtors":
+		line := fmt.Sprintf("%d. %s %s — 💸 %d, %s %d, 🍾 %d",
+			u.Rank, icon, u.Username, u.Balance, game.Emoji(), u.Spins, u.Wins)
+		if u.Bailouts > 0 {
+			line += fmt.Sprintf(", 🤡 %d", u.Bailouts)
+		}
+		return line
+	case "lucky":
+		return fmt.Sprintf("%d. %s %s — 🍀 %.1f%%, %s %d, 🍾 %d",
+			u.Rank, icon, u.Username, u.Luck, game.Emoji(), u.Spins, u.Wins)
+	case "streaks":
+		return fmt.Sprintf("%d. %s %s — 🔥 %d, 💀 %d, %s %d",
+			u.Rank, icon, u.Username, u.MaxStreak, u.MaxLossStreak, game.Emoji(), u.Spins)
+	default:
+		return fmt.Sprintf("%d. %s %s — 💸 %d, %s %d, 🍾 %d",
+			u.Rank, icon, u.Username, u.Balance, game.Emoji(), u.Spins, u.Wins)
+	}
+}
+
+// ratingQuery selects the lifetime counters for PeriodAll and the spin ledger
+// since the start of the period otherwise.
+func (s *StatsService) ratingQuery(chatId int64, game domain.Game, period domain.Period, rating domain.Rating) (domain.RatingQuery, error) {
+	q := domain.RatingQuery{ChatId: chatId, Game: game, Rating: rating}
+	if period != domain.PeriodAll {
+		loc, err := chatLocation(s.settingsRepo, chatId)
+		if err != nil {
+			return domain.RatingQuery{}, err
+		}
+		q.Since, _ = period.Start(time.Now(), loc)
+	}
+	return q, nil
+}
+
+func statsCallback(game domain.Game, period domain.Period, view string, page int) string {
+	return fmt.Sprintf("stats:%s:%s:%s:%d", game, period, view, page)
+}
+
+func buildStatsKeyboard(enabledGames []domain.Game, game domain.Game, period domain.Period, activeView string, page, totalPages int) gotgbot.InlineKeyboardMarkup {
 	viewRows := [][]struct {
 		key   string
 		label string
 	}{
 		{{"rich", "Багатії"}, {"debtors", "Боржники"}},
 		{{"lucky", "Везунчики"}, {"streaks", "Серії"}},
+		{{"duelists", "⚔️ Дуелянти"}, {"creditors", "🏦 Кредитори"}},
+		{{"seasons", "🏆 Минулі сезони"}},
 	}
 
 	var rows [][]gotgbot.InlineKeyboardButton
+	if gameRow := buildGameRow(enabledGames, game, "stats:%s:"+string(period)+":"+activeView+":0"); gameRow != nil {
+		rows = append(rows, gameRow)
+	}
+	if hasPeriods(activeView) {
+		var periodButtons []gotgbot.InlineKeyboardButton
+		for _, p := range domain.Periods {
+			label := periodLabels[p]
+			if p == period {
+				label = "✅ " + label
+			}
+			periodButtons = append(periodButtons, gotgbot.InlineKeyboardButton{
+				Text:         label,
+				CallbackData: statsCallback(game, p, activeView, 0),
+			})
+		}
+		rows = append(rows, periodButtons)
+	}
 	for _, row := range viewRows {
 		var buttons []gotgbot.InlineKeyboardButton
 		for _, v := range row {
 			label := v.label
-			if v.key == activeView {
+			if v.key == activeView || v.key == "seasons" && isSeasonView(activeView) {
 				label = "✅ " + label
 			}
 			buttons = append(buttons, gotgbot.InlineKeyboardButton{
 				Text:         label,
-				CallbackData: fmt.Sprintf("stats:%s:0", v.key),
+				CallbackData: statsCallback(game, period, v.key, 0),
 			})
 		}
 		rows = append(rows, buttons)
@@ -166,19 +310,272 @@ func buildStatsKeyboard(activeView string, page, totalPages int) gotgbot.InlineK
 		if page > 0 {
 			navButtons = append(navButtons, gotgbot.InlineKeyboardButton{
 				Text:         "⬅️ Назад",
-				CallbackData: fmt.Sprintf("stats:%s:%d", activeView, page-1),
+				CallbackData: statsCallback(game, period, activeView, page-1),
 			})
 		}
 		if page < totalPages-1 {
 			navButtons = append(navButtons, gotgbot.InlineKeyboardButton{
 				Text:         "Далі ➡️",
-				CallbackData: fmt.Sprintf("stats:%s:%d", activeView, page+1),
+				CallbackData: statsCallback(game, period, activeView, page+1),
 			})
 		}
 		if len(navButtons) > 0 {
 			rows = append(rows, navButtons)
 		}
 	}
+	if !isSeasonView(activeView) {
+		rows = append(rows, []gotgbot.InlineKeyboardButton{{
+			Text:         "📍 Моя сторінка",
+			CallbackData: fmt.Sprintf("stats:%s:%s:%s:me", game, period, activeView),
+		}})
+	}
+
+	return gotgbot.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
 
+// withoutMyPage drops the "my page" button. It finds the page of whoever
+// presses it, and that is always a user, so a board asked for by a chat must
+// not offer it.
+func withoutMyPage(keyboard gotgbot.InlineKeyboardMarkup) gotgbot.InlineKeyboardMarkup {
+	var rows [][]gotgbot.InlineKeyboardButton
+	for _, row := range keyboard.InlineKeyboard {
+		if len(row) == 1 && strings.HasSuffix(row[0].CallbackData, ":me") {
+			continue
+		}
+		rows = append(rows, row)
+	}
 	return gotgbot.InlineKeyboardMarkup{InlineKeyboard: rows}
 }
+
+// Season views are "seasons" for the list of closed seasons and "season<N>"
+// for the final standings of season N.
+func parseSeasonView(view string) (int64, bool) {
+	if !strings.HasPrefix(view, "season") || view == "seasons" {
+		return 0, false
+	}
+	number, err := strconv.ParseInt(strings.TrimPrefix(view, "season"), 10, 64)
+	return number, err == nil && number > 0
+}
+
+// hasPeriods reports whether the view can be narrowed to a period; season
+// standings, duel records and open loans are always shown whole.
+func hasPeriods(view string) bool {
+	return !isSeasonView(view) && view != "duelists" && view != "creditors"
+}
+
+func isSeasonView(view string) bool {
+	_, ok := parseSeasonView(view)
+	return ok || view == "seasons"
+}
+
+func (s *StatsService) buildSeasonsMessage(chatId int64, game domain.Game, period domain.Period, page int) (string, gotgbot.InlineKeyboardMarkup, error) {
+	total, err := s.seasonRepo.CountSeasons(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	page, totalPages := clampPage(page, total)
+	seasons, err := s.seasonRepo.GetSeasons(chatId, game, statsPageSize, page*statsPageSize)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	enabledGames, err := s.settingsRepo.GetEnabledGames(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	loc, err := chatLocation(s.settingsRepo, chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "🏆 Минулі сезони · %s %s\n\n", game.Emoji(), gameLabels[game])
+	if len(seasons) == 0 {
+		builder.WriteString("ще жоден сезон не закінчився")
+	}
+	var seasonButtons []gotgbot.InlineKeyboardButton
+	for _, season := range seasons {
+		fmt.Fprintf(&builder, "🏁 Сезон %d (%s — %s)\n", season.Number,
+			season.StartedAt.In(loc).Format("02.01.06"), season.EndedAt.In(loc).Format("02.01.06"))
+		if season.Champion == "" {
+			builder.WriteString("     ніхто не грав\n")
+		} else {
+			fmt.Fprintf(&builder, "     🥇 %s — 💸 %d\n", season.Champion, season.ChampionBalance)
+		}
+		seasonButtons = append(seasonButtons, gotgbot.InlineKeyboardButton{
+			Text:         fmt.Sprintf("#%d", season.Number),
+			CallbackData: statsCallback(game, period, fmt.Sprintf("season%d", season.Number), 0),
+		})
+	}
+	if totalPages > 1 {
+		fmt.Fprintf(&builder, "\nСторінка %d/%d", page+1, totalPages)
+	}
+
+	keyboard := buildStatsKeyboard(enabledGames, game, period, "seasons", page, totalPages)
+	for len(seasonButtons) > 0 {
+		n := min(5, len(seasonButtons))
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, seasonButtons[:n])
+		seasonButtons = seasonButtons[n:]
+	}
+	return builder.String(), keyboard, nil
+}
+
+func (s *StatsService) buildSeasonStandingsMessage(chatId int64, game domain.Game, period domain.Period, number int64, page int) (string, gotgbot.InlineKeyboardMarkup, error) {
+	total, err := s.seasonRepo.CountSeasonStandings(chatId, number, game)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	page, totalPages := clampPage(page, total)
+	stats, err := s.seasonRepo.GetSeasonStandings(chatId, number, game, statsPageSize, page*statsPageSize)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	enabledGames, err := s.settingsRepo.GetEnabledGames(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "🏁 Підсумки сезону %d · %s %s\n\n", number, game.Emoji(), gameLabels[game])
+	if len(stats) == 0 {
+		builder.WriteString("порожняк")
+	}
+	for _, u := range stats {
+		builder.WriteString(formatRatingLine("rich", game, u, 0) + "\n")
+	}
+	if totalPages > 1 {
+		fmt.Fprintf(&builder, "\nСторінка %d/%d", page+1, totalPages)
+	}
+
+	keyboard := buildStatsKeyboard(enabledGames, game, period, fmt.Sprintf("season%d", number), page, totalPages)
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []gotgbot.InlineKeyboardButton{{
+		Text:         "⬅️ До сезонів",
+		CallbackData: statsCallback(game, period, "seasons", 0),
+	}})
+	return builder.String(), keyboard, nil
+}
+
+// statsBoard is a leaderboard the repository pages on its own and that is
+// the same whatever game is selected.
+type statsBoard struct {
+	view  string
+	title string
+	count func() (int, error)
+	// entry renders the player's own line and returns its zero-based position.
+	entry func() (string, int, bool, error)
+	page  func(limit, offset int) ([]string, error)
+}
+
+func (s *StatsService) buildBoardMessage(chatId int64, game domain.Game, period domain.Period, page int, board statsBoard) (string, gotgbot.InlineKeyboardMarkup, error) {
+	total, err := board.count()
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	me, myPosition, onBoard, err := board.entry()
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	if page == myStatsPage && onBoard {
+		page = myPosition / statsPageSize
+	}
+	page, totalPages := clampPage(page, total)
+	lines, err := board.page(statsPageSize, page*statsPageSize)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+	enabledGames, err := s.settingsRepo.GetEnabledGames(chatId)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+
+	var builder strings.Builder
+	builder.WriteString(board.title + "\n\n")
+	if len(lines) == 0 {
+		builder.WriteString("порожняк\n")
+	}
+	for _, line := range lines {
+		builder.WriteString(line + "\n")
+	}
+	if onBoard {
+		builder.WriteString("\n📍 Ти: " + me)
+	} else {
+		builder.WriteString("\n📍 Тебе ще нема в цьому рейтингу")
+	}
+	if totalPages > 1 {
+		fmt.Fprintf(&builder, "\nСторінка %d/%d", page+1, totalPages)
+	}
+
+	keyboard := buildStatsKeyboard(enabledGames, game, period, board.view, page, totalPages)
+	return builder.String(), keyboard, nil
+}
+
+// buildDuelistsMessage ranks the chat's players by duels won; duels are
+// played on 🎰 only, so the board is the same whatever game is selected.
+func (s *StatsService) buildDuelistsMessage(chatId, userId int64, game domain.Game, period domain.Period, page int) (string, gotgbot.InlineKeyboardMarkup, error) {
+	return s.buildBoardMessage(chatId, game, period, page, statsBoard{
+		view:  "duelists",
+		title: "⚔️ Дуелянти",
+		count: func() (int, error) {
+			return s.duels.CountRating(chatId)
+		},
+		entry: func() (string, int, bool, error) {
+			u, position, ok, err := s.duels.RatingEntry(chatId, userId)
+			return formatDuelLine(u), position, ok, err
+		},
+		page: func(limit, offset int) ([]string, error) {
+			stats, err := s.duels.RatingPage(chatId, limit, offset)
+			var lines []string
+			for _, u := range stats {
+				lines = append(lines, formatDuelLine(u))
+			}
+			return lines, err
+		},
+	})
+}
+
+func formatDuelLine(u domain.DuelStats) string {
+	return fmt.Sprintf("%d. ⚔️ %s — 🏆 %d, 💀 %d", u.Rank, u.Username, u.Wins, u.Losses)
+}
+
+// buildCreditorsMessage ranks the chat's players with open loans by debt;
+// loans are taken in 🎰 coins, so the board is the same whatever game is
+// selected.
+func (s *StatsService) buildCreditorsMessage(chatId, userId int64, game domain.Game, period domain.Period, page int) (string, gotgbot.InlineKeyboardMarkup, error) {
+	return s.buildBoardMessage(chatId, game, period, page, statsBoard{
+		view:  "creditors",
+		title: "🏦 Кредитори",
+		count: func() (int, error) {
+			return s.loans.CountRating(chatId)
+		},
+		entry: func() (string, int, bool, error) {
+			u, position, ok, err := s.loans.RatingEntry(chatId, userId)
+			return formatLoanLine(u), position, ok, err
+		},
+		page: func(limit, offset int) ([]string, error) {
+			stats, err := s.loans.RatingPage(chatId, limit, offset)
+			var lines []string
+			for _, u := range stats {
+				lines = append(lines, formatLoanLine(u))
+			}
+			return lines, err
+		},
+	})
+}
+
+func formatLoanLine(u domain.LoanStats) string {
+	return fmt.Sprintf("%d. 🏦 %s — борг %d (узято %d)", u.Rank, u.Username, u.Debt, u.Principal)
+}
+
+// clampPage keeps page within the pages needed for total rows.
+func clampPage(page, total int) (int, int) {
+	totalPages := int(math.Ceil(float64(total) / float64(statsPageSize)))
+	if totalPages == 0 {
+		totalPages = 1
+	}
+	if page < 0 {
+		page = 0
+	}
+	if page >= totalPages {
+		page = totalPages - 1
+	}
+	return page, totalPages
+}
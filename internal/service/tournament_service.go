@@ -0,0 +1,301 @@
+package service
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"bandit-counter-bot/internal/repository"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// tournamentBoardSize is how many players the standings message shows.
+const tournamentBoardSize = 10
+
+var podiumMedals = map[int64]string{1: "🥇", 2: "🥈", 3: "🥉"}
+
+// TournamentService runs time-boxed contests scored apart from the lifetime
+// stats. The standings message of a running tournament is kept up to date.
+type TournamentService struct {
+	repo         *repository.TournamentRepo
+	settingsRepo *repository.SettingsRepo
+	auth         *AuthService
+}
+
+func NewTournamentService(repo *repository.TournamentRepo, settingsRepo *repository.SettingsRepo, auth *AuthService) *TournamentService {
+	return &TournamentService{repo: repo, settingsRepo: settingsRepo, auth: auth}
+}
+
+// HandleTournamentCommand shows the standings of the running tournament;
+// admins start one with "/tournament start 2h [wins|net] [emoji]" and end it
+// early with "/tournament stop".
+func (s *TournamentService) HandleTournamentCommand(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+	args := strings.Fields(msg.Text)[1:]
+	if len(args) == 0 {
+		return s.sendStandings(b, msg)
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "start", "stop":
+		if !s.auth.IsAdminMessage(b, msg) {
+			_, _ = msg.Reply(b, "а фіг тобі", &gotgbot.SendMessageOpts{})
+			return nil
+		}
+		if strings.ToLower(args[0]) == "stop" {
+			return s.stop(b, msg)
+		}
+		return s.start(b, msg, args[1:])
+	default:
+		_, _ = msg.Reply(b, "🏆 Турніри:\n\n"+
+			"/tournament - таблиця поточного турніру\n"+
+			"/tournament start 2h - почати турнір (адміни)\n"+
+			"/tournament start 2h net 🎲 - з правилом і грою\n"+
+			"/tournament stop - завершити достроково (адміни)\n\n"+
+			"Правила: wins - найбільше виграшів, net - найкращий баланс", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+}
+
+func (s *TournamentService) start(b *gotgbot.Bot, msg *gotgbot.Message, args []string) error {
+	var duration time.Duration
+	ok := false
+	if len(args) > 0 {
+		duration, ok = domain.ParseTournamentDuration(args[0])
+	}
+	if !ok {
+		_, _ = msg.Reply(b, "⏱ Турнір триває від 10 хв до 7 дн, наприклад /tournament start 2h", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	enabledGames, err := s.settingsRepo.GetEnabledGames(msg.Chat.Id)
+	if err != nil {
+		return err
+	}
+	game := defaultGame(enabledGames)
+	rule := domain.TournamentWins
+	for _, arg := range args[1:] {
+		if r, ok := domain.ParseTournamentRule(strings.ToLower(arg)); ok {
+			rule = r
+		} else if g, ok := domain.GameByEmoji(arg); ok && containsGame(enabledGames, g) {
+			game = g
+		} else {
+			_, _ = msg.Reply(b, "🤔 Не розумію "+arg+". Правила: wins або net, гра - емодзі увімкненої гри", &gotgbot.SendMessageOpts{})
+			return nil
+		}
+	}
+
+	at := msgTime(msg)
+	t, err := s.repo.StartTournament(domain.Tournament{
+		ChatId:    msg.Chat.Id,
+		Game:      game,
+		Rule:      rule,
+		StartedAt: at,
+		EndsAt:    at.Add(duration),
+	})
+	if errors.Is(err, repository.ErrTournamentRunning) {
+		_, _ = msg.Reply(b, "🏆 Турнір уже йде, дивись /tournament", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	text := fmt.Sprintf("🏆 Турнір стартував! Крутіть %s, рахуються тільки спіни до кінця турніру\n\n", game.Emoji())
+	return s.sendBoard(b, msg, t, text)
+}
+
+func (s *TournamentService) stop(b *gotgbot.Bot, msg *gotgbot.Message) error {
+	t, ok, err := s.repo.GetRunning(msg.Chat.Id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		_, _ = msg.Reply(b, "нема що зупиняти", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+	return s.finish(b, t)
+}
+
+func (s *TournamentService) sendStandings(b *gotgbot.Bot, msg *gotgbot.Message) error {
+	t, ok, err := s.repo.GetRunning(msg.Chat.Id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		_, _ = msg.Reply(b, "🏆 Зараз турніру нема. Адміни можуть почати: /tournament start 2h", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+	return s.sendBoard(b, msg, t, "")
+}
+
+// sendBoard posts the standings and makes the new message the one kept up to date.
+func (s *TournamentService) sendBoard(b *gotgbot.Bot, msg *gotgbot.Message, t domain.Tournament, header string) error {
+	text, keyboard, err := s.buildBoard(t, time.Now())
+	if err != nil {
+		return err
+	}
+	sent, err := msg.Reply(b, header+text, &gotgbot.SendMessageOpts{ReplyMarkup: keyboard})
+	if err != nil {
+		return err
+	}
+	return s.repo.SetMessage(t.Id, sent.MessageId)
+}
+
+// HandleTournamentCallback refreshes a standings message on demand.
+func (s *TournamentService) HandleTournamentCallback(b *gotgbot.Bot, ctx *ext.Context) error {
+	cb := ctx.CallbackQuery
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) < 3 {
+		cb.Answer(b, nil)
+		return nil
+	}
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		cb.Answer(b, nil)
+		return nil
+	}
+
+	t, err := s.repo.GetTournament(id)
+	if err != nil {
+		cb.Answer(b, nil)
+		return err
+	}
+	text, keyboard, err := s.buildBoard(t, time.Now())
+	if err != nil {
+		cb.Answer(b, nil)
+		return err
+	}
+	_, _, _ = cb.Message.EditText(b, text, &gotgbot.EditMessageTextOpts{ReplyMarkup: keyboard})
+	cb.Answer(b, nil)
+	return nil
+}
+
+// FinishDue closes the tournaments whose time is up and announces the podiums.
+func (s *TournamentService) FinishDue(b *gotgbot.Bot, now time.Time) {
+	due, err := s.repo.GetDue(now)
+	if err != nil {
+		log.Printf("failed to load finished tournaments: %v", err)
+		return
+	}
+	for _, t := range due {
+		if err := s.finish(b, t); err != nil {
+			log.Printf("failed to finish tournament %d in chat %d: %v", t.Id, t.ChatId, err)
+		}
+	}
+}
+
+// RefreshBoards edits the standings messages of the tournaments whose scores changed.
+func (s *TournamentService) RefreshBoards(b *gotgbot.Bot) {
+	dirty, err := s.repo.TakeDirty()
+	if err != nil {
+		log.Printf("failed to load tournament boards: %v", err)
+		return
+	}
+	for _, t := range dirty {
+		if err := s.editBoard(b, t); err != nil {
+			log.Printf("failed to refresh tournament board in chat %d: %v", t.ChatId, err)
+		}
+	}
+}
+
+// finish closes the tournament, freezes its standings message and posts the podium.
+func (s *TournamentService) finish(b *gotgbot.Bot, t domain.Tournament) error {
+	if err := s.repo.Finish(t.Id); errors.Is(err, repository.ErrTournamentClosed) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	t.Status = domain.TournamentFinished
+
+	standings, err := s.repo.GetStandings(t)
+	if err != nil {
+		return err
+	}
+	if t.MessageId != 0 {
+		if err := s.editBoard(b, t); err != nil {
+			log.Printf("failed to freeze tournament board in chat %d: %v", t.ChatId, err)
+		}
+	}
+	_, err = b.SendMessage(t.ChatId, formatPodium(t, standings), nil)
+	return err
+}
+
+func (s *TournamentService) editBoard(b *gotgbot.Bot, t domain.Tournament) error {
+	text, keyboard, err := s.buildBoard(t, time.Now())
+	if err != nil {
+		return err
+	}
+	_, _, err = b.EditMessageText(text, &gotgbot.EditMessageTextOpts{
+		ChatId:      t.ChatId,
+		MessageId:   t.MessageId,
+		ReplyMarkup: keyboard,
+	})
+	return err
+}
+
+func (s *TournamentService) buildBoard(t domain.Tournament, now time.Time) (string, gotgbot.InlineKeyboardMarkup, error) {
+	standings, err := s.repo.GetStandings(t)
+	if err != nil {
+		return "", gotgbot.InlineKeyboardMarkup{}, err
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "🏆 Турнір · %s %s\n📏 %s\n", t.Game.Emoji(), gameLabels[t.Game], t.Rule.Name())
+	running := t.Status == domain.TournamentRunning
+	if running {
+		fmt.Fprintf(&builder, "⏳ До кінця: %s\n\n", formatWait(t.EndsAt.Sub(now)))
+	} else {
+		builder.WriteString("🏁 Завершено\n\n")
+	}
+	if len(standings) == 0 {
+		builder.WriteString("порожняк\n")
+	}
+	for _, score := range standings[:min(len(standings), tournamentBoardSize)] {
+		builder.WriteString(formatTournamentLine(t, score) + "\n")
+	}
+
+	var keyboard gotgbot.InlineKeyboardMarkup
+	if running {
+		keyboard.InlineKeyboard = [][]gotgbot.InlineKeyboardButton{{
+			{Text: "🔄 Оновити", CallbackData: fmt.Sprintf("tournament:%d:refresh", t.Id)},
+		}}
+	}
+	return builder.String(), keyboard, nil
+}
+
+func formatTournamentLine(t domain.Tournament, u domain.TournamentScore) string {
+	icon := "👤"
+	if medal, ok := podiumMedals[u.Rank]; ok {
+		icon = medal
+	}
+	if t.Rule == domain.TournamentNet {
+		return fmt.Sprintf("%d. %s %s — 💸 %d, 🍾 %d, %s %d", u.Rank, icon, u.Username, u.Net, u.Wins, t.Game.Emoji(), u.Spins)
+	}
+	return fmt.Sprintf("%d. %s %s — 🍾 %d, 💸 %d, %s %d", u.Rank, icon, u.Username, u.Wins, u.Net, t.Game.Emoji(), u.Spins)
+}
+
+func formatPodium(t domain.Tournament, standings []domain.TournamentScore) string {
+	if len(standings) == 0 {
+		return "🏁 Турнір завершено, але ніхто так і не зіграв"
+	}
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "🏁 Турнір завершено! %s\n\n", t.Rule.Name())
+	for _, u := range standings {
+		medal, ok := podiumMedals[u.Rank]
+		if !ok {
+			break
+		}
+		score := fmt.Sprintf("🍾 %d", u.Wins)
+		if t.Rule == domain.TournamentNet {
+			score = fmt.Sprintf("💸 %d", u.Net)
+		}
+		fmt.Fprintf(&builder, "%s %s — %s\n", medal, u.Username, score)
+	}
+	return builder.String()
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"bandit-counter-bot/internal/domain"
+	"bandit-counter-bot/internal/repository"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// transferConfirmTTL is how long the sender has to confirm a transfer.
+const transferConfirmTTL = 5 * time.Minute
+
+const giveUsage = "💸 Як переказати: /give 50 у відповідь на повідомлення гравця або /give 50 @нік"
+
+type TransferService struct {
+	repo         *repository.TransferRepo
+	statsRepo    *repository.UserStatsRepo
+	settingsRepo *repository.SettingsRepo
+}
+
+func NewTransferService(repo *repository.TransferRepo, statsRepo *repository.UserStatsRepo, settingsRepo *repository.SettingsRepo) *TransferService {
+	return &TransferService{repo: repo, statsRepo: statsRepo, settingsRepo: settingsRepo}
+}
+
+// HandleGiveCommand asks the sender to confirm a transfer to the player they
+// replied to or mentioned. Nothing moves until the sender presses the button.
+func (s *TransferService) HandleGiveCommand(b *gotgbot.Bot, ctx *ext.Context) error {
+	msg := ctx.EffectiveMessage
+	from := messageSender(msg)
+	if from.isChat {
+		_, _ = msg.Reply(b, "💸 Від імені каналу чи анонімно переказувати не можна", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	amount, to, ok, err := s.parseGive(msg)
+	if err != nil {
+		return err
+	}
+	if !ok || amount <= 0 {
+		_, _ = msg.Reply(b, giveUsage, &gotgbot.SendMessageOpts{})
+		return nil
+	}
+	if to.id == from.id {
+		_, _ = msg.Reply(b, "💸 Собі переказувати нема сенсу", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	id, err := s.repo.CreateTransfer(domain.Transfer{
+		ChatId:    msg.Chat.Id,
+		FromId:    from.id,
+		FromName:  from.name,
+		ToId:      to.id,
+		ToName:    to.name,
+		Amount:    amount,
+		CreatedAt: msgTime(msg),
+	})
+	if err != nil {
+		return err
+	}
+
+	text := fmt.Sprintf("💸 %s передає %d %s гравцю %s. Підтверди протягом %d хв",
+		from.name, amount, domain.CoinGame.Emoji(), to.name, int(transferConfirmTTL.Minutes()))
+	keyboard := gotgbot.InlineKeyboardMarkup{
+		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{
+			{Text: "✅ Передати", CallbackData: fmt.Sprintf("give:%d:ok", id)},
+			{Text: "❌ Скасувати", CallbackData: fmt.Sprintf("give:%d:no", id)},
+		}},
+	}
+	_, _ = msg.Reply(b, text, &gotgbot.SendMessageOpts{ReplyMarkup: keyboard})
+	return nil
+}
+
+// parseGive reads the amount and the recipient: the author of the replied
+// message, a mention of a user without a username, or an @username of a
+// player the bot has seen spin in this chat.
+func (s *TransferService) parseGive(msg *gotgbot.Message) (int64, sender, bool, error) {
+	var amount int64
+	var to sender
+	var handle string
+	for _, arg := range strings.Fields(msg.Text)[1:] {
+		if strings.HasPrefix(arg, "@") {
+			handle = strings.TrimPrefix(arg, "@")
+			continue
+		}
+		if n, err := strconv.ParseInt(arg, 10, 64); err == nil {
+			amount = n
+		}
+	}
+
+	for _, e := range msg.Entities {
+		if e.Type == "text_mention" && e.User != nil && !e.User.IsBot {
+			return amount, sender{id: e.User.Id, name: e.User.FirstName}, true, nil
+		}
+	}
+	if handle != "" {
+		userId, name, ok, err := s.statsRepo.FindByHandle(msg.Chat.Id, handle)
+		if err != nil || !ok {
+			return 0, to, false, err
+		}
+		return amount, sender{id: userId, name: name}, true, nil
+	}
+	if reply := msg.ReplyToMessage; reply != nil && reply.ForumTopicCreated == nil {
+		if reply.From != nil && reply.From.IsBot {
+			return 0, to, false, nil
+		}
+		return amount, messageSender(reply), true, nil
+	}
+	return 0, to, false, nil
+}
+
+func (s *TransferService) HandleGiveCallback(b *gotgbot.Bot, ctx *ext.Context) error {
+	cb := ctx.CallbackQuery
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) < 3 {
+		cb.Answer(b, nil)
+		return nil
+	}
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		cb.Answer(b, nil)
+		return nil
+	}
+
+	transfer, err := s.repo.GetTransfer(id)
+	if err != nil {
+		cb.Answer(b, nil)
+		return err
+	}
+	if transfer.FromId != cb.From.Id {
+		cb.Answer(b, &gotgbot.AnswerCallbackQueryOpts{
+			Text: "це не твій переказ",
+		})
+		return nil
+	}
+
+	now := time.Now()
+	if parts[2] != "ok" || now.Sub(transfer.CreatedAt) > transferConfirmTTL {
+		text := "❌ Переказ скасовано"
+		if parts[2] == "ok" {
+			text = "⌛ Переказ не підтвердили вчасно"
+		}
+		if err := s.repo.Cancel(id); err != nil && !errors.Is(err, repository.ErrTransferClosed) {
+			cb.Answer(b, nil)
+			return err
+		}
+		_, _, _ = cb.Message.EditText(b, text, &gotgbot.EditMessageTextOpts{})
+		cb.Answer(b, nil)
+		return nil
+	}
+
+	limits, err := s.settingsRepo.GetTransferLimits(transfer.ChatId)
+	if err != nil {
+		cb.Answer(b, nil)
+		return err
+	}
+	loc, err := chatLocation(s.settingsRepo, transfer.ChatId)
+	if err != nil {
+		cb.Answer(b, nil)
+		return err
+	}
+	limits.DayStart = domain.StartOfDay(now, loc)
+
+	_, err = s.repo.Confirm(id, limits, now)
+	switch {
+	case errors.Is(err, repository.ErrTransferClosed):
+		cb.Answer(b, nil)
+		return nil
+	case errors.Is(err, repository.ErrTransferFloor):
+		cb.Answer(b, &gotgbot.AnswerCallbackQueryOpts{
+			Text: fmt.Sprintf("не вистачає: після переказу баланс має бути не нижче %d", limits.Floor),
+		})
+		return nil
+	case errors.Is(err, repository.ErrTransferCap):
+		cb.Answer(b, &gotgbot.AnswerCallbackQueryOpts{
+			Text: fmt.Sprintf("за день можна переказати не більше %d", limits.DailyCap),
+		})
+		return nil
+	case err != nil:
+		cb.Answer(b, nil)
+		return err
+	}
+
+	text := fmt.Sprintf("✅ Переказано: %s → %s, %d %s",
+		transfer.FromName, transfer.ToName, transfer.Amount, domain.CoinGame.Emoji())
+	_, _, _ = cb.Message.EditText(b, text, &gotgbot.EditMessageTextOpts{})
+	cb.Answer(b, nil)
+	return nil
+}
@@ -0,0 +1 @@
+ALTER TABLE chat_settings ADD COLUMN payout_table TEXT NOT NULL DEFAULT '{}';
@@ -0,0 +1 @@
+ALTER TABLE chat_settings ADD COLUMN spin_cost INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS jackpots (
+    chat_id INTEGER PRIMARY KEY,
+    pool_cents INTEGER NOT NULL DEFAULT 0
+);
+
+ALTER TABLE chat_settings ADD COLUMN jackpot_share INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,30 @@
+CREATE TABLE user_stats_new (
+    chat_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    game TEXT NOT NULL DEFAULT 'slot',
+
+    username TEXT NOT NULL DEFAULT 'ноунейм',
+    spins INTEGER NOT NULL DEFAULT 0,
+    wins INTEGER NOT NULL DEFAULT 0,
+    balance INTEGER NOT NULL DEFAULT 0,
+    current_streak INTEGER NOT NULL DEFAULT 0,
+    max_streak INTEGER NOT NULL DEFAULT 0,
+    current_loss_streak INTEGER NOT NULL DEFAULT 0,
+    max_loss_streak INTEGER NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (chat_id, user_id, game)
+);
+
+INSERT INTO user_stats_new (chat_id, user_id, game, username, spins, wins, balance,
+    current_streak, max_streak, current_loss_streak, max_loss_streak)
+SELECT chat_id, user_id, 'slot', username, spins, wins, balance,
+    current_streak, max_streak, current_loss_streak, max_loss_streak
+FROM user_stats;
+
+DROP TABLE user_stats;
+ALTER TABLE user_stats_new RENAME TO user_stats;
+
+CREATE INDEX IF NOT EXISTS user_stats_chat_balance_idx
+ON user_stats(chat_id, game, balance DESC);
+
+ALTER TABLE chat_settings ADD COLUMN enabled_games TEXT NOT NULL DEFAULT '["slot"]';
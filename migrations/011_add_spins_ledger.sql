@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS spins (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    chat_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    game TEXT NOT NULL,
+    username TEXT NOT NULL,
+    message_id INTEGER NOT NULL,
+    dice_value INTEGER NOT NULL,
+    payout INTEGER NOT NULL,
+    jackpot INTEGER NOT NULL DEFAULT 0,
+    cost INTEGER NOT NULL,
+    created_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS spins_chat_created_idx
+ON spins(chat_id, created_at);
+
+CREATE INDEX IF NOT EXISTS spins_chat_user_created_idx
+ON spins(chat_id, user_id, created_at);
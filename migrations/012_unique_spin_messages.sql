@@ -0,0 +1,5 @@
+DELETE FROM spins
+WHERE id NOT IN (SELECT MIN(id) FROM spins GROUP BY chat_id, message_id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS spins_chat_message_idx
+ON spins(chat_id, message_id);
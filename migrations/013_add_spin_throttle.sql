@@ -0,0 +1,3 @@
+ALTER TABLE chat_settings ADD COLUMN spin_cooldown INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE chat_settings ADD COLUMN spin_burst INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE chat_settings ADD COLUMN throttle_cleanup INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+ALTER TABLE chat_settings ADD COLUMN daily_spin_limit INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE chat_settings ADD COLUMN timezone TEXT NOT NULL DEFAULT 'Europe/Kyiv';
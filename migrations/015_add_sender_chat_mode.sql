@@ -0,0 +1 @@
+ALTER TABLE chat_settings ADD COLUMN ignore_sender_chats INTEGER NOT NULL DEFAULT 0;
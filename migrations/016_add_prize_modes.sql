@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS prize_modes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    chat_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    prize_values TEXT NOT NULL,
+    UNIQUE (chat_id, name)
+);
+
+ALTER TABLE chat_settings ADD COLUMN prize_mode TEXT NOT NULL DEFAULT 'classic';
+
+-- The mode used to be guessed from prize_values; only the built-in sets could be stored.
+UPDATE chat_settings SET prize_mode = CASE prize_values
+    WHEN '[43]' THEN 'lemons'
+    WHEN '[1,22,43,64]' THEN 'three_in_a_row'
+    WHEN '[64]' THEN 'classic'
+    ELSE 'custom'
+END;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS seasons (
+    chat_id INTEGER NOT NULL,
+    number INTEGER NOT NULL,
+    started_at INTEGER NOT NULL,
+    ended_at INTEGER NOT NULL,
+    PRIMARY KEY (chat_id, number)
+);
+
+CREATE TABLE IF NOT EXISTS season_standings (
+    chat_id INTEGER NOT NULL,
+    season INTEGER NOT NULL,
+    game TEXT NOT NULL,
+    user_id INTEGER NOT NULL,
+    username TEXT NOT NULL,
+    spins INTEGER NOT NULL,
+    wins INTEGER NOT NULL,
+    balance INTEGER NOT NULL,
+    max_streak INTEGER NOT NULL,
+    max_loss_streak INTEGER NOT NULL,
+    rank INTEGER NOT NULL,
+    PRIMARY KEY (chat_id, season, game, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS season_standings_rank_idx
+ON season_standings(chat_id, season, game, rank);
@@ -0,0 +1,3 @@
+ALTER TABLE chat_settings ADD COLUMN season_schedule TEXT NOT NULL DEFAULT 'off';
+ALTER TABLE chat_settings ADD COLUMN season_end_date TEXT NOT NULL DEFAULT '';
+ALTER TABLE chat_settings ADD COLUMN season_checked_at INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS achievements (
+    chat_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    achievement TEXT NOT NULL,
+    unlocked_at INTEGER NOT NULL,
+    PRIMARY KEY (chat_id, user_id, achievement)
+);
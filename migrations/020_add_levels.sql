@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS player_xp (
+    chat_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    xp INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (chat_id, user_id)
+);
+
+-- Players keep the experience they earned before levels existed.
+INSERT INTO player_xp (chat_id, user_id, xp)
+SELECT chat_id, user_id, COUNT(*) + 5 * SUM(CASE WHEN payout > 0 THEN 1 ELSE 0 END)
+FROM spins
+GROUP BY chat_id, user_id;
+
+ALTER TABLE chat_settings ADD COLUMN level_curve TEXT NOT NULL DEFAULT 'normal';
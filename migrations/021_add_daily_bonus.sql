@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS daily_claims (
+    chat_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    day TEXT NOT NULL,
+    streak INTEGER NOT NULL,
+    amount INTEGER NOT NULL,
+    claimed_at INTEGER NOT NULL,
+    PRIMARY KEY (chat_id, user_id, day)
+);
+
+ALTER TABLE chat_settings ADD COLUMN daily_bonus INTEGER NOT NULL DEFAULT 10;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS bailouts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    chat_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    debt INTEGER NOT NULL,
+    created_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS bailouts_chat_user_idx
+ON bailouts(chat_id, user_id, created_at);
+
+ALTER TABLE chat_settings ADD COLUMN bailout_threshold INTEGER NOT NULL DEFAULT -1000;
+ALTER TABLE chat_settings ADD COLUMN bailout_cooldown INTEGER NOT NULL DEFAULT 604800;
@@ -0,0 +1,23 @@
+ALTER TABLE user_stats ADD COLUMN handle TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS user_stats_chat_handle_idx
+ON user_stats(chat_id, handle);
+
+CREATE TABLE IF NOT EXISTS transfers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    chat_id INTEGER NOT NULL,
+    from_user_id INTEGER NOT NULL,
+    from_name TEXT NOT NULL,
+    to_user_id INTEGER NOT NULL,
+    to_name TEXT NOT NULL,
+    amount INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    created_at INTEGER NOT NULL,
+    done_at INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS transfers_chat_from_idx
+ON transfers(chat_id, from_user_id, done_at);
+
+ALTER TABLE chat_settings ADD COLUMN transfer_floor INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE chat_settings ADD COLUMN transfer_daily_cap INTEGER NOT NULL DEFAULT 500;
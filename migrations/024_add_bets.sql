@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS pending_bets (
+    chat_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    stake INTEGER NOT NULL,
+    expires_at INTEGER NOT NULL,
+    PRIMARY KEY (chat_id, user_id)
+);
+
+ALTER TABLE spins ADD COLUMN stake INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE chat_settings ADD COLUMN bet_min INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE chat_settings ADD COLUMN bet_max INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS duels (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    chat_id INTEGER NOT NULL,
+    challenger_id INTEGER NOT NULL,
+    challenger_name TEXT NOT NULL,
+    opponent_id INTEGER NOT NULL,
+    opponent_name TEXT NOT NULL,
+    stake INTEGER NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'offered',
+    challenger_payout INTEGER NOT NULL DEFAULT -1,
+    opponent_payout INTEGER NOT NULL DEFAULT -1,
+    winner_id INTEGER NOT NULL DEFAULT 0,
+    created_at INTEGER NOT NULL,
+    accepted_at INTEGER NOT NULL DEFAULT 0,
+    finished_at INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS duels_chat_status_idx
+ON duels(chat_id, status);
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS tournaments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    chat_id INTEGER NOT NULL,
+    game TEXT NOT NULL,
+    rule TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'running',
+    started_at INTEGER NOT NULL,
+    ends_at INTEGER NOT NULL,
+    message_id INTEGER NOT NULL DEFAULT 0,
+    -- set when the scores changed since the standings message was last edited
+    dirty INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS tournaments_status_idx
+ON tournaments(status, chat_id);
+
+CREATE TABLE IF NOT EXISTS tournament_scores (
+    tournament_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    username TEXT NOT NULL,
+    spins INTEGER NOT NULL DEFAULT 0,
+    wins INTEGER NOT NULL DEFAULT 0,
+    net INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (tournament_id, user_id)
+);
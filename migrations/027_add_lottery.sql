@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS lottery_pots (
+    chat_id INTEGER PRIMARY KEY,
+    amount INTEGER NOT NULL DEFAULT 0
+);
+
+-- draw_id stays 0 until the round the ticket was bought for is drawn
+CREATE TABLE IF NOT EXISTS lottery_tickets (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    chat_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    username TEXT NOT NULL,
+    price INTEGER NOT NULL,
+    bought_at INTEGER NOT NULL,
+    draw_id INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS lottery_tickets_draw_idx
+ON lottery_tickets(chat_id, draw_id);
+
+CREATE TABLE IF NOT EXISTS lottery_draws (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    chat_id INTEGER NOT NULL,
+    drawn_at INTEGER NOT NULL,
+    seed INTEGER NOT NULL,
+    tickets INTEGER NOT NULL,
+    pot INTEGER NOT NULL,
+    ticket_id INTEGER NOT NULL,
+    winner_id INTEGER NOT NULL,
+    winner_name TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'won',
+    claimed_at INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS lottery_draws_chat_idx
+ON lottery_draws(chat_id, status);
+
+ALTER TABLE chat_settings ADD COLUMN lottery_schedule TEXT NOT NULL DEFAULT 'off';
+ALTER TABLE chat_settings ADD COLUMN lottery_hour INTEGER NOT NULL DEFAULT 20;
+ALTER TABLE chat_settings ADD COLUMN lottery_checked_at INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE chat_settings ADD COLUMN ticket_price INTEGER NOT NULL DEFAULT 10;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS loans (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    chat_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    username TEXT NOT NULL,
+    principal INTEGER NOT NULL,
+    debt INTEGER NOT NULL,
+    rate INTEGER NOT NULL,
+    taken_at INTEGER NOT NULL,
+    accrued_at INTEGER NOT NULL,
+    repaid_at INTEGER NOT NULL DEFAULT 0
+);
+
+-- at most one open loan per player
+CREATE UNIQUE INDEX IF NOT EXISTS loans_open_idx
+ON loans(chat_id, user_id) WHERE repaid_at = 0;
+
+ALTER TABLE chat_settings ADD COLUMN loan_max INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE chat_settings ADD COLUMN loan_rate INTEGER NOT NULL DEFAULT 5;
+ALTER TABLE chat_settings ADD COLUMN loan_repay_share INTEGER NOT NULL DEFAULT 50;
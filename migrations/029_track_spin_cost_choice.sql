@@ -0,0 +1,5 @@
+-- Chats that never chose a spin cost keep the original rules: a win is paid in
+-- full and only a loss costs the spin. A chosen cost of 1 can't be told apart
+-- from the old default, so only other values count as chosen.
+ALTER TABLE chat_settings ADD COLUMN spin_cost_set INTEGER NOT NULL DEFAULT 0;
+UPDATE chat_settings SET spin_cost_set = 1 WHERE spin_cost <> 1;